@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/fake_upstream"
+)
+
+func main() {
+	if err := newCommand().Execute(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "Error:", err.Error())
+		os.Exit(1)
+	}
+}
+
+func newCommand() *cobra.Command {
+	var (
+		addr   string
+		prices []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "fake-upstream",
+		Short: "Serves a stand-in of the CoinDesk price API, scriptable through its /_control endpoints",
+		Example: "  fake-upstream --addr :8081 --price BTCUSD=50000 --price ETHUSD=3000\n" +
+			"  COIN_DESK_API_URL=http://localhost:8081/data/price crypto-pricing-api serve",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			server := fake_upstream.New()
+
+			for _, value := range prices {
+				pair, price, err := parsePrice(value)
+				if err != nil {
+					return err
+				}
+				server.SetPrice(pair, price)
+			}
+
+			slog.Info("Serving fake upstream", "addr", addr)
+
+			return http.ListenAndServe(addr, server) //nolint:gosec // local test tool
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", ":8081", "address to listen on")
+	cmd.Flags().StringArrayVar(&prices, "price", []string{"BTCUSD=50000", "ETHUSD=3000"}, "initial price of a pair, e.g. BTCUSD=50000")
+
+	return cmd
+}
+
+func parsePrice(value string) (domain.Pair, decimal.Decimal, error) {
+	pairValue, priceValue, found := strings.Cut(value, "=")
+	if !found {
+		return domain.Pair{}, decimal.Zero, errors.Errorf("invalid price %q, expected PAIR=PRICE", value)
+	}
+
+	pair, err := domain.NewPairFromString(strings.ToUpper(pairValue))
+	if err != nil {
+		return domain.Pair{}, decimal.Zero, errors.Wrapf(err, "invalid pair %q", pairValue)
+	}
+
+	price, err := decimal.NewFromString(priceValue)
+	if err != nil {
+		return domain.Pair{}, decimal.Zero, errors.Wrapf(err, "invalid price %q", priceValue)
+	}
+
+	return pair, price, nil
+}
@@ -1,45 +1,18 @@
 package main
 
 import (
-	"context"
-	"log/slog"
+	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
 
-	"github.com/tonytcb/crypto-pricing-api/internal/app"
-	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/cli"
 )
 
-func main() {
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer cancel()
-
-	cfg, err := config.Load("./default.env", "./config/default.env")
-	if err != nil {
-		panic("unable to load env configurations:" + err.Error())
-	}
-
-	var log = slog.Default()
-
-	log.Info("Initializing application")
-
-	application, err := app.NewApplication(ctx, cfg, log)
-	if err != nil {
-		panic("Failed to create application:" + err.Error())
-	}
+// version is set on build time through -ldflags "-X main.version=..."
+var version = "dev" //nolint:gochecknoglobals // set by the linker
 
-	if err = application.Run(ctx); err != nil {
-		panic("Failed to start application: " + err.Error())
+func main() {
+	if err := cli.NewRootCommand(version).Execute(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "Error:", err.Error())
+		os.Exit(1)
 	}
-
-	<-ctx.Done() // wait until we receive a signal to stop the app
-
-	log.Info("Shutting down application")
-
-	application.Stop()
-
-	log.Info("Exit application")
-
-	os.Exit(0)
 }
@@ -0,0 +1,81 @@
+package http_handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// SSEEventsSentKey is set by streaming handlers with the number of events delivered to the client.
+	SSEEventsSentKey = "sse_events_sent"
+	// SSEClientIDKey is set by streaming handlers with the ID of the client.
+	SSEClientIDKey = "sse_client_id"
+
+	eventStreamContentType = "text/event-stream"
+)
+
+type AccessLogHandler struct {
+	log *slog.Logger
+}
+
+func NewAccessLogHandler() *AccessLogHandler {
+	return &AccessLogHandler{
+		log: slog.Default(),
+	}
+}
+
+// Log writes one access log entry per request once it has been served.
+// Streaming (SSE) requests are logged on disconnect, including the connection lifetime and the number of events delivered.
+func (h AccessLogHandler) Log(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	var (
+		duration = time.Since(start)
+		bytes    = c.Writer.Size()
+	)
+
+	if bytes < 0 {
+		bytes = 0
+	}
+
+	attrs := []any{
+		"request_id", RequestIDFromContext(c),
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"query", c.Request.URL.RawQuery,
+		"client_ip", c.ClientIP(),
+		"user_agent", c.Request.UserAgent(),
+		"status", c.Writer.Status(),
+		"bytes", bytes,
+	}
+
+	if isEventStream(c) {
+		attrs = append(attrs,
+			"client_id", c.GetString(SSEClientIDKey),
+			"connection_lifetime", duration.String(),
+			"events_sent", c.GetUint64(SSEEventsSentKey),
+		)
+
+		h.log.Info("SSE connection closed", attrs...)
+		return
+	}
+
+	attrs = append(attrs, "duration", duration.String())
+
+	if len(c.Errors) > 0 {
+		attrs = append(attrs, "errors", c.Errors.String())
+	}
+
+	h.log.Info("HTTP request", attrs...)
+}
+
+func isEventStream(c *gin.Context) bool {
+	return c.Writer.Status() == http.StatusOK &&
+		strings.HasPrefix(c.Writer.Header().Get("Content-Type"), eventStreamContentType)
+}
@@ -0,0 +1,72 @@
+package http_handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLogHandler_Log(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(buf *bytes.Buffer, handler gin.HandlerFunc) *gin.Engine {
+		accessLog := &AccessLogHandler{log: slog.New(slog.NewJSONHandler(buf, nil))}
+
+		router := gin.New()
+		router.Use(NewRequestIDHandler().Handle, accessLog.Log)
+		router.GET("/test", handler)
+		return router
+	}
+
+	t.Run("Logs regular requests", func(t *testing.T) {
+		var buf bytes.Buffer
+		router := newRouter(&buf, func(c *gin.Context) {
+			c.String(http.StatusTeapot, "hello")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test?a=1", nil)
+		req.Header.Set(RequestIDHeader, "req-1")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+		assert.Equal(t, "HTTP request", entry["msg"])
+		assert.Equal(t, "req-1", entry["request_id"])
+		assert.Equal(t, "GET", entry["method"])
+		assert.Equal(t, "/test", entry["path"])
+		assert.Equal(t, "a=1", entry["query"])
+		assert.EqualValues(t, http.StatusTeapot, entry["status"])
+		assert.EqualValues(t, 5, entry["bytes"])
+		assert.Contains(t, entry, "duration")
+	})
+
+	t.Run("Logs streaming requests on disconnect", func(t *testing.T) {
+		var buf bytes.Buffer
+		router := newRouter(&buf, func(c *gin.Context) {
+			c.Header("Content-Type", "text/event-stream")
+			c.String(http.StatusOK, "data: {}\n\n")
+			c.Set(SSEClientIDKey, "client-1")
+			c.Set(SSEEventsSentKey, uint64(1))
+		})
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+		assert.Equal(t, "SSE connection closed", entry["msg"])
+		assert.NotEmpty(t, entry["request_id"])
+		assert.Equal(t, "client-1", entry["client_id"])
+		assert.EqualValues(t, 1, entry["events_sent"])
+		assert.Contains(t, entry, "connection_lifetime")
+		assert.NotContains(t, entry, "duration")
+	})
+}
@@ -0,0 +1,42 @@
+package http_handlers
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const bearerPrefix = "Bearer "
+
+// AdminAuthHandler protects the admin endpoints with a static bearer token. Without a token, the admin
+// endpoints are disabled.
+type AdminAuthHandler struct {
+	log   *slog.Logger
+	token string
+}
+
+func NewAdminAuthHandler(token string) *AdminAuthHandler {
+	return &AdminAuthHandler{
+		log:   slog.Default(),
+		token: token,
+	}
+}
+
+func (h *AdminAuthHandler) Authorize(c *gin.Context) {
+	if h.token == "" {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Admin API is disabled"})
+		return
+	}
+
+	token, found := strings.CutPrefix(c.GetHeader("Authorization"), bearerPrefix)
+	if !found || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		h.log.Warn("Unauthorized admin request", "path", c.Request.URL.Path, "request_id", RequestIDFromContext(c))
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	c.Next()
+}
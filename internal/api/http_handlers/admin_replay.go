@@ -0,0 +1,50 @@
+package http_handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReplayStepper interface {
+	Step(n int) int
+}
+
+type AdminReplay struct {
+	log     *slog.Logger
+	stepper ReplayStepper
+}
+
+// NewAdminReplay accepts a nil stepper when the prices are not replayed stepwise.
+func NewAdminReplay(stepper ReplayStepper) *AdminReplay {
+	return &AdminReplay{
+		log:     slog.Default(),
+		stepper: stepper,
+	}
+}
+
+// Step releases the next updates of a stepwise replay, as many as the 'count' parameter (1 by default).
+func (h *AdminReplay) Step(c *gin.Context) {
+	if h.stepper == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Prices are not replayed stepwise"})
+		return
+	}
+
+	count := 1
+	if countParam := c.Query("count"); countParam != "" {
+		n, err := strconv.Atoi(countParam)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid count parameter"})
+			return
+		}
+		count = n
+	}
+
+	remaining := h.stepper.Step(count)
+
+	h.log.Debug("Replay stepped", "count", count, "remaining", remaining)
+
+	c.JSON(http.StatusOK, gin.H{"remaining": remaining})
+}
@@ -0,0 +1,60 @@
+package http_handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockReplayStepper struct {
+	mock.Mock
+}
+
+func (m *MockReplayStepper) Step(n int) int {
+	args := m.Called(n)
+	return args.Int(0)
+}
+
+func TestAdminReplay_Step(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	request := func(stepper ReplayStepper, url string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/admin/replay/step", NewAdminReplay(stepper).Step)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, url, nil))
+		return w
+	}
+
+	t.Run("Steps the replay", func(t *testing.T) {
+		stepper := new(MockReplayStepper)
+		stepper.On("Step", 1).Return(9).Once()
+		stepper.On("Step", 5).Return(4).Once()
+
+		w := request(stepper, "/admin/replay/step")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"remaining":9}`, w.Body.String())
+
+		w = request(stepper, "/admin/replay/step?count=5")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"remaining":4}`, w.Body.String())
+
+		stepper.AssertExpectations(t)
+	})
+
+	t.Run("Rejects invalid counts", func(t *testing.T) {
+		for _, count := range []string{"0", "-1", "many"} {
+			assert.Equal(t, http.StatusBadRequest, request(new(MockReplayStepper), "/admin/replay/step?count="+count).Code, count)
+		}
+	})
+
+	t.Run("Reports when the prices are not replayed stepwise", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotImplemented, request(nil, "/admin/replay/step").Code)
+	})
+}
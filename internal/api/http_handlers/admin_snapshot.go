@@ -0,0 +1,51 @@
+package http_handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/snapshot"
+)
+
+type SnapshotWriter interface {
+	WriteSnapshot() (string, snapshot.Stats, error)
+}
+
+type AdminSnapshot struct {
+	log    *slog.Logger
+	writer SnapshotWriter
+}
+
+// NewAdminSnapshot accepts a nil writer when the store type doesn't support snapshots.
+func NewAdminSnapshot(writer SnapshotWriter) *AdminSnapshot {
+	return &AdminSnapshot{
+		log:    slog.Default(),
+		writer: writer,
+	}
+}
+
+// Snapshot writes the stored price updates to the snapshot file, to be restored on startup.
+func (h *AdminSnapshot) Snapshot(c *gin.Context) {
+	if h.writer == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Snapshots are not supported by the store type"})
+		return
+	}
+
+	path, stats, err := h.writer.WriteSnapshot()
+	if err != nil {
+		h.log.Error("Failed to write snapshot", "request_id", RequestIDFromContext(c), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write snapshot"})
+		return
+	}
+
+	h.log.Info("Snapshot written", "path", path, "pairs", stats.Pairs, "updates", stats.Updates, "bytes", stats.Bytes)
+
+	c.JSON(http.StatusOK, gin.H{
+		"path":    path,
+		"pairs":   stats.Pairs,
+		"updates": stats.Updates,
+		"bytes":   stats.Bytes,
+	})
+}
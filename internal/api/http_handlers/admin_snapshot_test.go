@@ -0,0 +1,90 @@
+package http_handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/snapshot"
+)
+
+type MockSnapshotWriter struct {
+	mock.Mock
+}
+
+func (m *MockSnapshotWriter) WriteSnapshot() (string, snapshot.Stats, error) {
+	args := m.Called()
+	return args.String(0), args.Get(1).(snapshot.Stats), args.Error(2)
+}
+
+func TestAdminSnapshot_Snapshot(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(token string, writer SnapshotWriter) *gin.Engine {
+		router := gin.New()
+		router.POST("/admin/snapshot", NewAdminAuthHandler(token).Authorize, NewAdminSnapshot(writer).Snapshot)
+		return router
+	}
+
+	request := func(router *gin.Engine, authorization string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/snapshot", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Writes a snapshot", func(t *testing.T) {
+		writer := new(MockSnapshotWriter)
+		writer.On("WriteSnapshot").Return("/data/prices.snapshot", snapshot.Stats{Pairs: 2, Updates: 10, Bytes: 300}, nil)
+
+		w := request(newRouter("secret", writer), "Bearer secret")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "/data/prices.snapshot", response["path"])
+		assert.EqualValues(t, 10, response["updates"])
+
+		writer.AssertExpectations(t)
+	})
+
+	t.Run("Reports write failures", func(t *testing.T) {
+		writer := new(MockSnapshotWriter)
+		writer.On("WriteSnapshot").Return("", snapshot.Stats{}, errors.New("disk full"))
+
+		w := request(newRouter("secret", writer), "Bearer secret")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "disk full")
+	})
+
+	t.Run("Reports unsupported store types", func(t *testing.T) {
+		w := request(newRouter("secret", nil), "Bearer secret")
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+
+	t.Run("Rejects unauthorized requests", func(t *testing.T) {
+		writer := new(MockSnapshotWriter)
+		router := newRouter("secret", writer)
+
+		for _, authorization := range []string{"", "Bearer wrong", "secret", "Basic secret"} {
+			assert.Equal(t, http.StatusUnauthorized, request(router, authorization).Code, authorization)
+		}
+
+		writer.AssertNotCalled(t, "WriteSnapshot")
+	})
+
+	t.Run("Disables the admin API without a token", func(t *testing.T) {
+		w := request(newRouter("", new(MockSnapshotWriter)), "Bearer ")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
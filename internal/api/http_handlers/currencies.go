@@ -0,0 +1,55 @@
+package http_handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+type CurrencyRegistry interface {
+	All() []domain.CurrencyInfo
+}
+
+type CurrencyResponse struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Decimals int32  `json:"decimals"`
+	Kind     string `json:"kind"`
+}
+
+type Currencies struct {
+	registry CurrencyRegistry
+}
+
+func NewCurrencies(registry CurrencyRegistry) *Currencies {
+	return &Currencies{
+		registry: registry,
+	}
+}
+
+// List returns the supported currencies, optionally only the ones of the kind given by the 'kind' parameter.
+func (h *Currencies) List(c *gin.Context) {
+	kind := domain.CurrencyKind(c.Query("kind"))
+	if kind != "" && kind != domain.Fiat && kind != domain.Crypto {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid kind parameter"})
+		return
+	}
+
+	response := make([]CurrencyResponse, 0)
+	for _, currency := range h.registry.All() {
+		if kind != "" && currency.Kind != kind {
+			continue
+		}
+
+		response = append(response, CurrencyResponse{
+			Code:     string(currency.Code),
+			Name:     currency.Name,
+			Decimals: currency.Decimals,
+			Kind:     string(currency.Kind),
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
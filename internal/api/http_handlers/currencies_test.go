@@ -0,0 +1,51 @@
+package http_handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+func TestCurrencies_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry, err := domain.NewCurrencyRegistry(
+		domain.CurrencyInfo{Code: domain.USD, Name: "US Dollar", Decimals: 2, Kind: domain.Fiat},
+		domain.CurrencyInfo{Code: domain.BTC, Name: "Bitcoin", Decimals: 8, Kind: domain.Crypto},
+	)
+	require.NoError(t, err)
+
+	request := func(url string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/currencies", NewCurrencies(registry).List)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w
+	}
+
+	t.Run("Lists the currencies", func(t *testing.T) {
+		w := request("/currencies")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[
+			{"code":"BTC","name":"Bitcoin","decimals":8,"kind":"crypto"},
+			{"code":"USD","name":"US Dollar","decimals":2,"kind":"fiat"}
+		]`, w.Body.String())
+	})
+
+	t.Run("Filters the currencies by kind", func(t *testing.T) {
+		w := request("/currencies?kind=fiat")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[{"code":"USD","name":"US Dollar","decimals":2,"kind":"fiat"}]`, w.Body.String())
+	})
+
+	t.Run("Rejects invalid kinds", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, request("/currencies?kind=token").Code)
+	})
+}
@@ -0,0 +1,63 @@
+package http_handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+type PairsLister interface {
+	Pairs() []domain.Pair
+}
+
+type PairResponse struct {
+	Symbol    string `json:"symbol"`
+	Base      string `json:"base"`
+	Quote     string `json:"quote"`
+	Synthetic bool   `json:"synthetic,omitempty"`
+}
+
+type Pairs struct {
+	lister    PairsLister
+	synthetic []domain.Pair
+}
+
+// NewPairs lists the monitored pairs of the lister, and the synthetic ones derived from them.
+func NewPairs(lister PairsLister, synthetic []domain.Pair) *Pairs {
+	return &Pairs{
+		lister:    lister,
+		synthetic: synthetic,
+	}
+}
+
+// List returns the pairs whose prices are served, sorted by symbol.
+func (h *Pairs) List(c *gin.Context) {
+	pairs := h.lister.Pairs()
+
+	response := make([]PairResponse, 0, len(pairs)+len(h.synthetic))
+	for _, pair := range pairs {
+		response = append(response, PairResponse{
+			Symbol: pair.String(),
+			Base:   string(pair.From),
+			Quote:  string(pair.To),
+		})
+	}
+
+	for _, pair := range h.synthetic {
+		response = append(response, PairResponse{
+			Symbol:    pair.String(),
+			Base:      string(pair.From),
+			Quote:     string(pair.To),
+			Synthetic: true,
+		})
+	}
+
+	sort.Slice(response, func(i, j int) bool {
+		return response[i].Symbol < response[j].Symbol
+	})
+
+	c.JSON(http.StatusOK, response)
+}
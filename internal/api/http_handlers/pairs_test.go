@@ -0,0 +1,46 @@
+package http_handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+type MockPairsLister struct {
+	mock.Mock
+}
+
+func (m *MockPairsLister) Pairs() []domain.Pair {
+	args := m.Called()
+	return args.Get(0).([]domain.Pair)
+}
+
+func TestPairs_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	lister := new(MockPairsLister)
+	lister.On("Pairs").Return([]domain.Pair{
+		domain.NewPair(domain.ETH, domain.USD),
+		domain.NewPair(domain.BTC, domain.USDT),
+	})
+
+	router := gin.New()
+	router.GET("/pairs", NewPairs(lister, []domain.Pair{domain.NewPair(domain.ETH, domain.BTC)}).List)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pairs", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[
+		{"symbol":"BTCUSDT","base":"BTC","quote":"USDT"},
+		{"symbol":"ETHBTC","base":"ETH","quote":"BTC","synthetic":true},
+		{"symbol":"ETHUSD","base":"ETH","quote":"USD"}
+	]`, w.Body.String())
+}
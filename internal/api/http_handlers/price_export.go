@@ -0,0 +1,96 @@
+package http_handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/export"
+)
+
+type PriceExport struct {
+	log    *slog.Logger
+	source export.RangeSource
+}
+
+// NewPriceExport accepts a nil source when the store type doesn't support range queries.
+func NewPriceExport(source export.RangeSource) *PriceExport {
+	return &PriceExport{
+		log:    slog.Default(),
+		source: source,
+	}
+}
+
+// Export streams the price updates of a pair received between the unix timestamps given by the 'from' and 'to'
+// parameters, as csv (default), ndjson or parquet. 'to' defaults to now.
+func (h *PriceExport) Export(c *gin.Context) {
+	if h.source == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Exports are not supported by the store type"})
+		return
+	}
+
+	pair, err := domain.NewPairFromString(c.Param("pair"))
+	if err != nil {
+		h.log.Error("Invalid pair parameter", "pair", c.Param("pair"), "request_id", RequestIDFromContext(c), "error", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pair parameter"})
+		return
+	}
+
+	from, ok := h.timestampParam(c, "from", time.Unix(0, 0))
+	if !ok {
+		return
+	}
+
+	to, ok := h.timestampParam(c, "to", time.Now())
+	if !ok {
+		return
+	}
+
+	format, err := export.ParseFormat(c.DefaultQuery("format", string(export.FormatCSV)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format parameter, expected csv, ndjson or parquet"})
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", "attachment; filename="+pair.String()+"."+string(format))
+	c.Status(http.StatusOK)
+
+	writer, err := export.NewWriter(format, c.Writer)
+	if err != nil {
+		h.log.Error("Failed to create export writer", "request_id", RequestIDFromContext(c), "error", err.Error())
+		return
+	}
+
+	written, err := export.Export(c.Request.Context(), h.source, pair, from, to, writer)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		// the response is already being streamed, so the failure can only be logged
+		h.log.Error("Failed to export prices", "pair", pair.String(), "request_id", RequestIDFromContext(c), "error", err.Error())
+		return
+	}
+
+	h.log.Debug("Prices exported", "pair", pair.String(), "format", format, "updates", written)
+}
+
+func (h *PriceExport) timestampParam(c *gin.Context, name string, defaultValue time.Time) (time.Time, bool) {
+	param := c.Query(name)
+	if param == "" {
+		return defaultValue, true
+	}
+
+	timestamp, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		h.log.Error("Invalid "+name+" parameter", name, param, "request_id", RequestIDFromContext(c), "error", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name + " parameter"})
+		return time.Time{}, false
+	}
+
+	return time.Unix(timestamp, 0), true
+}
@@ -0,0 +1,91 @@
+package http_handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/export"
+)
+
+type MockRangeSource struct {
+	mock.Mock
+}
+
+func (m *MockRangeSource) GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate {
+	args := m.Called(pair, from, to, limit)
+	return args.Get(0).([]domain.PriceUpdate)
+}
+
+func TestPriceExport_Export(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var (
+		btcUsd     = domain.NewPair(domain.BTC, domain.USD)
+		receivedAt = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	)
+
+	request := func(source export.RangeSource, url string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/prices/:pair/export", NewPriceExport(source).Export)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w
+	}
+
+	t.Run("Streams the range as csv", func(t *testing.T) {
+		source := new(MockRangeSource)
+		source.On("GetRange", btcUsd, time.Unix(100, 0), time.Unix(200, 0), mock.Anything).Return([]domain.PriceUpdate{
+			{Pair: btcUsd, Price: decimal.RequireFromString("50000.10"), ReceivedAt: receivedAt},
+		})
+
+		w := request(source, "/prices/BTCUSD/export?from=100&to=200")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		assert.Equal(t, "attachment; filename=BTCUSD.csv", w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "pair,price,received_at\nBTCUSD,50000.1,2025-01-01T10:00:00Z\n", w.Body.String())
+
+		source.AssertExpectations(t)
+	})
+
+	t.Run("Streams the range as ndjson", func(t *testing.T) {
+		source := new(MockRangeSource)
+		source.On("GetRange", btcUsd, mock.Anything, mock.Anything, mock.Anything).Return([]domain.PriceUpdate{
+			{Pair: btcUsd, Price: decimal.NewFromInt(50000), ReceivedAt: receivedAt},
+		})
+
+		w := request(source, "/prices/BTCUSD/export?format=ndjson")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Equal(t, `{"pair":"BTCUSD","price":"50000","received_at":"2025-01-01T10:00:00Z"}`, strings.TrimSpace(w.Body.String()))
+	})
+
+	t.Run("Rejects invalid parameters", func(t *testing.T) {
+		source := new(MockRangeSource)
+
+		for _, url := range []string{
+			"/prices/INVALID/export",
+			"/prices/BTCUSD/export?from=yesterday",
+			"/prices/BTCUSD/export?to=now",
+			"/prices/BTCUSD/export?format=xml",
+		} {
+			assert.Equal(t, http.StatusBadRequest, request(source, url).Code, url)
+		}
+
+		source.AssertNotCalled(t, "GetRange", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Reports unsupported store types", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotImplemented, request(nil, "/prices/BTCUSD/export").Code)
+	})
+}
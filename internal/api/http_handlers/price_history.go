@@ -0,0 +1,109 @@
+package http_handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+const (
+	HistoryTruncatedHeader      = "X-History-Truncated"
+	HistoryAvailableSinceHeader = "X-History-Available-Since"
+	HistoryLimitReachedHeader   = "X-History-Limit-Reached"
+)
+
+const (
+	// defaultHistoryWindow bounds the history returned when no 'since' parameter is given.
+	defaultHistoryWindow = 24 * time.Hour
+	defaultHistoryLimit  = 1000
+	maxHistoryLimit      = 10000
+)
+
+type PricesHistoryProvider interface {
+	GetHistory(pair domain.Pair, since time.Time, limit int) []domain.PriceUpdate
+	HistoryAvailableSince(pair domain.Pair) (time.Time, bool)
+}
+
+type PriceHistory struct {
+	log             *slog.Logger
+	historyProvider PricesHistoryProvider
+	converter       PriceConverter
+}
+
+// NewPriceHistory answers the history of the prices, converted on request when a converter is given.
+func NewPriceHistory(historyProvider PricesHistoryProvider, converter PriceConverter) *PriceHistory {
+	return &PriceHistory{
+		log:             slog.Default(),
+		historyProvider: historyProvider,
+		converter:       converter,
+	}
+}
+
+// History returns up to 'limit' (1000 by default, 10000 at most) stored price updates of a pair, in ascending order,
+// since the unix timestamp given by the 'since' parameter, the last 24 hours by default. Larger ranges are exported.
+// The 'fields' parameter set to full adds the quotes to the prices, and 'convert' converts them to another currency,
+// at the latest FX rate.
+// When the requested range starts before the stored history, the truncation is flagged through response headers.
+func (h *PriceHistory) History(c *gin.Context) {
+	pair, err := domain.NewPairFromString(c.Param("pair"))
+	if err != nil {
+		h.log.Error("Invalid pair parameter", "pair", c.Param("pair"), "request_id", RequestIDFromContext(c), "error", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pair parameter"})
+		return
+	}
+
+	since := time.Now().Add(-defaultHistoryWindow)
+	if sinceParam := c.Query("since"); sinceParam != "" {
+		timestamp, err := strconv.ParseInt(sinceParam, 10, 64)
+		if err != nil {
+			h.log.Error("Invalid since parameter", "since", sinceParam, "request_id", RequestIDFromContext(c), "error", err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since parameter"})
+			return
+		}
+		since = time.Unix(timestamp, 0)
+	}
+
+	limit := defaultHistoryLimit
+	if limitParam := c.Query("limit"); limitParam != "" {
+		value, err := strconv.Atoi(limitParam)
+		if err != nil || value <= 0 || value > maxHistoryLimit {
+			h.log.Error("Invalid limit parameter", "limit", limitParam, "request_id", RequestIDFromContext(c))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+			return
+		}
+		limit = value
+	}
+
+	render, ok := priceRenderer(c, h.log, pair, h.converter)
+	if !ok {
+		return
+	}
+
+	history := h.historyProvider.GetHistory(pair, since, limit)
+	if len(history) == limit {
+		c.Header(HistoryLimitReachedHeader, "true")
+	}
+
+	if availableSince, limited := h.historyProvider.HistoryAvailableSince(pair); limited && since.Before(availableSince) {
+		c.Header(HistoryTruncatedHeader, "true")
+		c.Header(HistoryAvailableSinceHeader, strconv.FormatInt(availableSince.Unix(), 10))
+	}
+
+	response := make([]any, 0, len(history))
+	for _, update := range history {
+		event, err := render(update)
+		if err != nil {
+			h.log.Error("Failed to render price history", "pair", pair.String(), "request_id", RequestIDFromContext(c), "error", err.Error())
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Conversion unavailable"})
+			return
+		}
+		response = append(response, event)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,189 @@
+package http_handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/fx"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+)
+
+type MockPricesHistoryProvider struct {
+	mock.Mock
+}
+
+func (m *MockPricesHistoryProvider) GetHistory(pair domain.Pair, since time.Time, limit int) []domain.PriceUpdate {
+	args := m.Called(pair, since, limit)
+	return args.Get(0).([]domain.PriceUpdate)
+}
+
+func (m *MockPricesHistoryProvider) HistoryAvailableSince(pair domain.Pair) (time.Time, bool) {
+	args := m.Called(pair)
+	return args.Get(0).(time.Time), args.Bool(1)
+}
+
+func TestPriceHistory_History(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	btcUsd := domain.NewPair(domain.BTC, domain.USD)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	history := []domain.PriceUpdate{
+		{Pair: btcUsd, Price: decimal.NewFromFloat(50000), ReceivedAt: now.Add(-30 * time.Second)},
+		{Pair: btcUsd, Price: decimal.NewFromFloat(51000.5), ReceivedAt: now.Add(-15 * time.Second)},
+	}
+
+	inDefaultWindow := mock.MatchedBy(func(since time.Time) bool {
+		return !since.Before(now.Add(-defaultHistoryWindow)) && since.Before(time.Now().Add(-defaultHistoryWindow+time.Second))
+	})
+
+	newConvertingRouter := func(historyProvider *MockPricesHistoryProvider, converter PriceConverter) *gin.Engine {
+		router := gin.New()
+		router.GET("/prices/:pair/history", NewPriceHistory(historyProvider, converter).History)
+		return router
+	}
+
+	newRouter := func(historyProvider *MockPricesHistoryProvider) *gin.Engine {
+		return newConvertingRouter(historyProvider, nil)
+	}
+
+	t.Run("Returns the pair history since the given timestamp", func(t *testing.T) {
+		historyProvider := new(MockPricesHistoryProvider)
+		historyProvider.On("GetHistory", btcUsd, time.Unix(now.Unix()-60, 0), defaultHistoryLimit).Return(history)
+		historyProvider.On("HistoryAvailableSince", btcUsd).Return(time.Time{}, false)
+
+		w := httptest.NewRecorder()
+		url := "/prices/BTCUSD/history?since=" + strconv.FormatInt(now.Unix()-60, 10)
+		newRouter(historyProvider).ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []sse.PriceStreamResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response, 2)
+		assert.Equal(t, "BTCUSD", response[1].Pair)
+		assert.Equal(t, "51000.5", response[1].Price)
+		assert.Empty(t, w.Header().Get(HistoryTruncatedHeader))
+
+		historyProvider.AssertExpectations(t)
+	})
+
+	t.Run("Adds the quotes in full mode", func(t *testing.T) {
+		quoted := []domain.PriceUpdate{{
+			Pair:       btcUsd,
+			Price:      decimal.NewFromFloat(50000),
+			ReceivedAt: now,
+			Quote:      &domain.Quote{High24h: decimal.NewNullDecimal(decimal.NewFromFloat(52000))},
+		}}
+
+		historyProvider := new(MockPricesHistoryProvider)
+		historyProvider.On("GetHistory", btcUsd, inDefaultWindow, defaultHistoryLimit).Return(quoted)
+		historyProvider.On("HistoryAvailableSince", btcUsd).Return(time.Time{}, false)
+
+		router := newRouter(historyProvider)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/prices/BTCUSD/history?fields=full", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []sse.PriceStreamResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response, 1)
+		require.NotNil(t, response[0].QuoteResponse)
+		assert.Equal(t, "52000", response[0].High24h)
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/prices/BTCUSD/history", nil))
+		assert.NotContains(t, w.Body.String(), "high_24h", "Expected the default shape without the fields parameter")
+	})
+
+	t.Run("Converts the history at the latest rate", func(t *testing.T) {
+		historyProvider := new(MockPricesHistoryProvider)
+		historyProvider.On("GetHistory", btcUsd, inDefaultWindow, defaultHistoryLimit).Return(history)
+		historyProvider.On("HistoryAvailableSince", btcUsd).Return(time.Time{}, false)
+
+		converter := new(MockPriceConverter)
+		converter.On("Supports", mock.Anything).Return(true)
+		for _, update := range history {
+			converted := update
+			converted.Pair = domain.NewPair(domain.BTC, domain.EUR)
+			converted.Price = update.Price.Mul(decimal.RequireFromString("0.5"))
+
+			converter.On("Convert", update, domain.EUR).Return(converted, fx.Conversion{
+				From:   domain.USD,
+				To:     domain.EUR,
+				Rate:   decimal.RequireFromString("0.5"),
+				RateAt: now,
+			}, nil)
+		}
+
+		w := httptest.NewRecorder()
+		newConvertingRouter(historyProvider, converter).
+			ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/prices/BTCUSD/history?convert=EUR", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []sse.PriceStreamResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response, 2)
+		assert.Equal(t, "BTCEUR", response[1].Pair)
+		assert.Equal(t, "25500.25", response[1].Price)
+		assert.Equal(t, "0.5", response[1].Conversion.Rate)
+	})
+
+	t.Run("Flags a range starting before the stored history", func(t *testing.T) {
+		availableSince := now.Add(-45 * time.Second)
+
+		historyProvider := new(MockPricesHistoryProvider)
+		historyProvider.On("GetHistory", btcUsd, time.Unix(now.Unix()-60, 0), defaultHistoryLimit).Return(history)
+		historyProvider.On("HistoryAvailableSince", btcUsd).Return(availableSince, true)
+
+		w := httptest.NewRecorder()
+		url := "/prices/BTCUSD/history?since=" + strconv.FormatInt(now.Unix()-60, 10)
+		newRouter(historyProvider).ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "true", w.Header().Get(HistoryTruncatedHeader))
+		assert.Equal(t, strconv.FormatInt(availableSince.Unix(), 10), w.Header().Get(HistoryAvailableSinceHeader))
+	})
+
+	t.Run("Limits the history read", func(t *testing.T) {
+		historyProvider := new(MockPricesHistoryProvider)
+		historyProvider.On("GetHistory", btcUsd, inDefaultWindow, 2).Return(history)
+		historyProvider.On("GetHistory", btcUsd, inDefaultWindow, 3).Return(history)
+		historyProvider.On("HistoryAvailableSince", btcUsd).Return(time.Time{}, false)
+
+		router := newRouter(historyProvider)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/prices/BTCUSD/history?limit=2", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "true", w.Header().Get(HistoryLimitReachedHeader))
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/prices/BTCUSD/history?limit=3", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(HistoryLimitReachedHeader))
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		router := newRouter(new(MockPricesHistoryProvider))
+
+		for _, url := range []string{"/prices/BTC/history", "/prices/BTCUSD/history?since=yesterday", "/prices/BTCUSD/history?fields=all",
+			"/prices/BTCUSD/history?limit=0", "/prices/BTCUSD/history?limit=10001", "/prices/BTCUSD/history?limit=all"} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code, url)
+		}
+	})
+}
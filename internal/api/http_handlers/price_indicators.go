@@ -0,0 +1,182 @@
+package http_handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/analytics"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+)
+
+type IndicatorsProvider interface {
+	Indicators(pair domain.Pair) (analytics.Indicators, bool)
+}
+
+type IndicatorsResponse struct {
+	Pair    string                     `json:"pair"`
+	At      string                     `json:"at"`
+	Price   string                     `json:"price"`
+	Samples int                        `json:"samples"`
+	Windows []WindowIndicatorsResponse `json:"windows"`
+	VWAP24h string                     `json:"vwap_24h,omitempty"`
+}
+
+// WindowIndicatorsResponse holds the indicators over the last Size prices, omitted until that many are received.
+type WindowIndicatorsResponse struct {
+	Size       int    `json:"size"`
+	SMA        string `json:"sma,omitempty"`
+	EMA        string `json:"ema,omitempty"`
+	StdDev     string `json:"stddev,omitempty"`
+	Volatility string `json:"volatility,omitempty"`
+}
+
+func NewIndicatorsResponse(indicators analytics.Indicators) IndicatorsResponse {
+	response := IndicatorsResponse{
+		Pair:    indicators.Pair.String(),
+		At:      indicators.At.Format(time.RFC3339Nano),
+		Price:   indicators.Price.String(),
+		Samples: indicators.Samples,
+		Windows: make([]WindowIndicatorsResponse, 0, len(indicators.Windows)),
+	}
+
+	if indicators.VWAP24h.Valid {
+		response.VWAP24h = indicators.VWAP24h.Decimal.String()
+	}
+
+	for _, window := range indicators.Windows {
+		windowResponse := WindowIndicatorsResponse{Size: window.Size}
+		if window.SMA.Valid {
+			windowResponse.SMA = window.SMA.Decimal.String()
+		}
+		if window.EMA.Valid {
+			windowResponse.EMA = window.EMA.Decimal.String()
+		}
+		if window.StdDev.Valid {
+			windowResponse.StdDev = window.StdDev.Decimal.String()
+		}
+		if window.Volatility.Valid {
+			windowResponse.Volatility = window.Volatility.Decimal.String()
+		}
+		response.Windows = append(response.Windows, windowResponse)
+	}
+
+	return response
+}
+
+type PriceIndicators struct {
+	log               *slog.Logger
+	clientsManager    SseClientsManager
+	provider          IndicatorsProvider
+	clientsBufferSize int
+	closeOnce         sync.Once
+	done              chan struct{}
+}
+
+// NewPriceIndicators accepts a nil provider when the analytics are disabled.
+func NewPriceIndicators(cfg *config.Config, clientsManager SseClientsManager, provider IndicatorsProvider) *PriceIndicators {
+	return &PriceIndicators{
+		log:               slog.Default(),
+		clientsManager:    clientsManager,
+		provider:          provider,
+		clientsBufferSize: cfg.SseClientsBufferSize,
+		done:              make(chan struct{}),
+	}
+}
+
+// Close ends the running streams, like PriceStreamer.Close.
+func (h *PriceIndicators) Close() {
+	h.closeOnce.Do(func() {
+		close(h.done)
+	})
+}
+
+// Indicators returns the rolling indicators of a pair, as of its latest price.
+func (h *PriceIndicators) Indicators(c *gin.Context) {
+	pair, ok := h.pairParam(c)
+	if !ok {
+		return
+	}
+
+	indicators, ok := h.provider.Indicators(pair)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No price received for the pair yet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, NewIndicatorsResponse(indicators))
+}
+
+// Stream streams the indicators of a pair, starting with the current ones, then on every price received.
+func (h *PriceIndicators) Stream(c *gin.Context) {
+	pair, ok := h.pairParam(c)
+	if !ok {
+		return
+	}
+
+	w := c.Writer
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Transfer-Encoding", "chunked")
+
+	client, err := sse.NewClient(uuid.New().String(), c.Writer, h.clientsBufferSize, h.renderIndicators)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Streaming not supported"})
+		return
+	}
+
+	h.clientsManager.RegisterClient(client)
+	defer h.clientsManager.UnregisterClient(client)
+
+	if current, ok := h.provider.Indicators(pair); ok {
+		if err := client.Send(domain.PriceUpdate{Pair: pair, Price: current.Price, ReceivedAt: current.At}); err != nil {
+			h.log.Error("Failed to send current indicators", "error", err.Error())
+		}
+	}
+
+	// flush the headers so that clients know the stream is established before the first update
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Writer.Flush()
+
+	// blocks while the client is connected
+	listenUntilDone(c, client, pair, h.done)
+
+	c.Set(SSEClientIDKey, client.ID())
+	c.Set(SSEEventsSentKey, client.EventsSent())
+}
+
+// renderIndicators renders the indicators as of the price of an update, skipping the updates not analyzed, e.g. the
+// stale ones.
+func (h *PriceIndicators) renderIndicators(update domain.PriceUpdate) (any, error) {
+	indicators, ok := h.provider.Indicators(update.Pair)
+	if !ok || !indicators.At.Equal(update.ReceivedAt) {
+		return nil, errors.Errorf("no indicators as of %s", update.ReceivedAt.Format(time.RFC3339Nano))
+	}
+
+	return NewIndicatorsResponse(indicators), nil
+}
+
+func (h *PriceIndicators) pairParam(c *gin.Context) (domain.Pair, bool) {
+	if h.provider == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Analytics is disabled"})
+		return domain.Pair{}, false
+	}
+
+	pair, err := domain.NewPairFromString(c.Param("pair"))
+	if err != nil {
+		h.log.Error("Invalid pair parameter", "pair", c.Param("pair"), "request_id", RequestIDFromContext(c), "error", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pair parameter"})
+		return domain.Pair{}, false
+	}
+
+	return pair, true
+}
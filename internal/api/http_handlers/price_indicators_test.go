@@ -0,0 +1,157 @@
+package http_handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/analytics"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+	"github.com/tonytcb/crypto-pricing-api/test/mocks"
+)
+
+type MockIndicatorsProvider struct {
+	mock.Mock
+}
+
+func (m *MockIndicatorsProvider) Indicators(pair domain.Pair) (analytics.Indicators, bool) {
+	args := m.Called(pair)
+	return args.Get(0).(analytics.Indicators), args.Bool(1)
+}
+
+func TestPriceIndicators_Indicators(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var (
+		btcUsd     = domain.NewPair(domain.BTC, domain.USD)
+		now        = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		indicators = analytics.Indicators{
+			Pair:    btcUsd,
+			At:      now,
+			Price:   decimal.NewFromInt(50000),
+			Samples: 20,
+			Windows: []analytics.WindowIndicators{
+				{
+					Size:       20,
+					SMA:        decimal.NewNullDecimal(decimal.NewFromInt(49000)),
+					EMA:        decimal.NewNullDecimal(decimal.NewFromInt(49500)),
+					StdDev:     decimal.NewNullDecimal(decimal.NewFromInt(600)),
+					Volatility: decimal.NewNullDecimal(decimal.RequireFromString("0.01")),
+				},
+				{Size: 50},
+			},
+			VWAP24h: decimal.NewNullDecimal(decimal.NewFromInt(48000)),
+		}
+	)
+
+	get := func(provider IndicatorsProvider, url string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/prices/:pair/indicators", NewPriceIndicators(&config.Config{}, nil, provider).Indicators)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w
+	}
+
+	t.Run("Returns the indicators of the pair", func(t *testing.T) {
+		provider := new(MockIndicatorsProvider)
+		provider.On("Indicators", btcUsd).Return(indicators, true)
+
+		w := get(provider, "/prices/BTC-USD/indicators")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"pair": "BTCUSD",
+			"at": "2025-01-01T10:00:00Z",
+			"price": "50000",
+			"samples": 20,
+			"windows": [
+				{"size": 20, "sma": "49000", "ema": "49500", "stddev": "600", "volatility": "0.01"},
+				{"size": 50}
+			],
+			"vwap_24h": "48000"
+		}`, w.Body.String())
+	})
+
+	t.Run("Answers not found before the first price", func(t *testing.T) {
+		provider := new(MockIndicatorsProvider)
+		provider.On("Indicators", btcUsd).Return(analytics.Indicators{}, false)
+
+		assert.Equal(t, http.StatusNotFound, get(provider, "/prices/BTCUSD/indicators").Code)
+	})
+
+	t.Run("Invalid pair parameter", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get(new(MockIndicatorsProvider), "/prices/XXXYYY/indicators").Code)
+	})
+
+	t.Run("Answers not implemented when the analytics are disabled", func(t *testing.T) {
+		w := get(nil, "/prices/BTCUSD/indicators")
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+		assert.Contains(t, w.Body.String(), "Analytics is disabled")
+	})
+}
+
+func TestPriceIndicators_Stream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		now    = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		engine = analytics.NewEngine([]int{2})
+	)
+
+	engine.Update(domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(100), ReceivedAt: now})
+
+	registered := make(chan *sse.Client, 1)
+	clientsManager := &MockSseClientsManager{registered: registered}
+	clientsManager.On("RegisterClient", mock.Anything).Return()
+	clientsManager.On("UnregisterClient", mock.Anything).Return()
+
+	handler := NewPriceIndicators(&config.Config{SseClientsBufferSize: 10}, clientsManager, engine)
+	defer handler.Close()
+
+	w := mocks.NewThreadSafeRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/prices/BTCUSD/indicators/stream", nil)
+	c.Params = []gin.Param{{Key: "pair", Value: "BTCUSD"}}
+
+	go handler.Stream(c)
+
+	var client *sse.Client
+	select {
+	case client = <-registered:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the client registered")
+	}
+
+	assert.Eventually(t, func() bool {
+		return strings.Count(w.BodyString(), "data: ") == 1
+	}, time.Second, 10*time.Millisecond, "Expected the current indicators streamed first")
+	assert.Contains(t, w.BodyString(), `"samples":1`)
+
+	update := domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(110), ReceivedAt: now.Add(time.Second)}
+	engine.Update(update)
+	require.NoError(t, client.Send(update))
+
+	// stale updates are not analyzed, so no indicators are streamed for them
+	stale := domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(90), ReceivedAt: now.Add(-time.Second)}
+	engine.Update(stale)
+	require.NoError(t, client.Send(stale))
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(w.BodyString(), `"sma":"105"`)
+	}, time.Second, 10*time.Millisecond, "Expected the indicators streamed on every price")
+
+	assert.Never(t, func() bool {
+		return strings.Count(w.BodyString(), "data: ") > 2
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
@@ -0,0 +1,60 @@
+package http_handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+type LatestPriceProvider interface {
+	Latest(pair domain.Pair) (domain.PriceUpdate, bool)
+}
+
+type PriceLatest struct {
+	log            *slog.Logger
+	latestProvider LatestPriceProvider
+	converter      PriceConverter
+}
+
+// NewPriceLatest answers the latest price of the pairs, converted on request when a converter is given.
+func NewPriceLatest(latestProvider LatestPriceProvider, converter PriceConverter) *PriceLatest {
+	return &PriceLatest{
+		log:            slog.Default(),
+		latestProvider: latestProvider,
+		converter:      converter,
+	}
+}
+
+// Latest returns the latest price of a pair, rendered like the streamed ones, as per the 'fields' and 'convert'
+// parameters.
+func (h *PriceLatest) Latest(c *gin.Context) {
+	pair, err := domain.NewPairFromString(c.Param("pair"))
+	if err != nil {
+		h.log.Error("Invalid pair parameter", "pair", c.Param("pair"), "request_id", RequestIDFromContext(c), "error", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pair parameter"})
+		return
+	}
+
+	render, ok := priceRenderer(c, h.log, pair, h.converter)
+	if !ok {
+		return
+	}
+
+	latest, ok := h.latestProvider.Latest(pair)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No price received for the pair yet"})
+		return
+	}
+
+	response, err := render(latest)
+	if err != nil {
+		h.log.Error("Failed to render latest price", "pair", pair.String(), "request_id", RequestIDFromContext(c), "error", err.Error())
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Conversion unavailable"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
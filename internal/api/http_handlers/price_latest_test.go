@@ -0,0 +1,156 @@
+package http_handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/fx"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+)
+
+type MockLatestPriceProvider struct {
+	mock.Mock
+}
+
+func (m *MockLatestPriceProvider) Latest(pair domain.Pair) (domain.PriceUpdate, bool) {
+	args := m.Called(pair)
+	return args.Get(0).(domain.PriceUpdate), args.Bool(1)
+}
+
+type MockPriceConverter struct {
+	mock.Mock
+}
+
+func (m *MockPriceConverter) Supports(currency domain.Currency) bool {
+	return m.Called(currency).Bool(0)
+}
+
+func (m *MockPriceConverter) Convert(update domain.PriceUpdate, to domain.Currency) (domain.PriceUpdate, fx.Conversion, error) {
+	args := m.Called(update, to)
+	return args.Get(0).(domain.PriceUpdate), args.Get(1).(fx.Conversion), args.Error(2)
+}
+
+func TestPriceLatest_Latest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		btcEur = domain.NewPair(domain.BTC, domain.EUR)
+		now    = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		latest = domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(50000), ReceivedAt: now}
+	)
+
+	newRouter := func(provider *MockLatestPriceProvider, converter PriceConverter) *gin.Engine {
+		router := gin.New()
+		router.GET("/prices/:pair/latest", NewPriceLatest(provider, converter).Latest)
+		return router
+	}
+
+	get := func(router *gin.Engine, url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w
+	}
+
+	t.Run("Returns the latest price", func(t *testing.T) {
+		provider := new(MockLatestPriceProvider)
+		provider.On("Latest", btcUsd).Return(latest, true)
+
+		w := get(newRouter(provider, nil), "/prices/BTC-USD/latest")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"pair":"BTCUSD","price":"50000","received_at":"2025-01-01T10:00:00Z"}`, w.Body.String())
+	})
+
+	t.Run("Formats the reception time in nanoseconds on request", func(t *testing.T) {
+		precise := latest
+		precise.ReceivedAt = now.Add(123456789 * time.Nanosecond)
+
+		provider := new(MockLatestPriceProvider)
+		provider.On("Latest", btcUsd).Return(precise, true)
+
+		router := newRouter(provider, nil)
+
+		w := get(router, "/prices/BTCUSD/latest?precision=ns")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"pair":"BTCUSD","price":"50000","received_at":"2025-01-01T10:00:00.123456789Z"}`, w.Body.String())
+
+		w = get(router, "/prices/BTCUSD/latest")
+		assert.JSONEq(t, `{"pair":"BTCUSD","price":"50000","received_at":"2025-01-01T10:00:00Z"}`, w.Body.String(),
+			"Expected the reception time in seconds by default")
+	})
+
+	t.Run("Answers not found before the first price", func(t *testing.T) {
+		provider := new(MockLatestPriceProvider)
+		provider.On("Latest", btcUsd).Return(domain.PriceUpdate{}, false)
+
+		w := get(newRouter(provider, nil), "/prices/BTCUSD/latest")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Converts the price to the requested currency", func(t *testing.T) {
+		provider := new(MockLatestPriceProvider)
+		provider.On("Latest", btcUsd).Return(latest, true)
+
+		converter := new(MockPriceConverter)
+		converter.On("Supports", mock.Anything).Return(true)
+		converter.On("Convert", latest, domain.EUR).Return(
+			domain.PriceUpdate{Pair: btcEur, Price: decimal.NewFromInt(46000), ReceivedAt: now},
+			fx.Conversion{From: domain.USD, To: domain.EUR, Rate: decimal.RequireFromString("0.92"), RateAt: now.Add(-time.Hour)},
+			nil,
+		)
+
+		w := get(newRouter(provider, converter), "/prices/BTCUSD/latest?convert=eur")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response sse.PriceStreamResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "BTCEUR", response.Pair)
+		assert.Equal(t, "46000", response.Price)
+		assert.Equal(t, &sse.ConversionResponse{From: "USD", Rate: "0.92", RateAt: "2025-01-01T09:00:00Z"}, response.Conversion)
+	})
+
+	t.Run("Answers unavailable without a rate", func(t *testing.T) {
+		provider := new(MockLatestPriceProvider)
+		provider.On("Latest", btcUsd).Return(latest, true)
+
+		converter := new(MockPriceConverter)
+		converter.On("Supports", mock.Anything).Return(true)
+		converter.On("Convert", latest, domain.EUR).Return(domain.PriceUpdate{}, fx.Conversion{}, errors.New("no FX rate for EUR"))
+
+		w := get(newRouter(provider, converter), "/prices/BTCUSD/latest?convert=EUR")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		converter := new(MockPriceConverter)
+		converter.On("Supports", domain.Currency("XYZ")).Return(false)
+		converter.On("Supports", mock.Anything).Return(true)
+
+		router := newRouter(new(MockLatestPriceProvider), converter)
+
+		for _, url := range []string{
+			"/prices/BTC/latest",
+			"/prices/BTCUSD/latest?fields=all",
+			"/prices/BTCUSD/latest?precision=ms",
+			"/prices/BTCUSD/latest?convert=XYZ",
+			"/prices/BTCUSD/latest?convert=BTC",
+		} {
+			assert.Equal(t, http.StatusBadRequest, get(router, url).Code, url)
+		}
+
+		w := get(newRouter(new(MockLatestPriceProvider), nil), "/prices/BTCUSD/latest?convert=EUR")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Conversion is disabled")
+	})
+}
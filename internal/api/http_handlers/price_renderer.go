@@ -0,0 +1,94 @@
+package http_handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/fx"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+)
+
+const (
+	// fieldsFull is the value of the 'fields' parameter requesting the quotes along with the prices.
+	fieldsFull = "full"
+	// precisionNano is the value of the 'precision' parameter requesting the reception times in nanoseconds.
+	precisionNano = "ns"
+)
+
+type PriceConverter interface {
+	Supports(currency domain.Currency) bool
+	Convert(update domain.PriceUpdate, to domain.Currency) (domain.PriceUpdate, fx.Conversion, error)
+}
+
+// priceRenderer returns how the prices of a pair are rendered, as requested by the parameters: 'fields' set to full
+// adds the quotes, 'precision' set to ns formats the reception times in nanoseconds instead of seconds, and 'convert'
+// converts the prices to another currency, e.g. EUR. An invalid parameter is answered with a bad request, returning
+// false. The converter is nil when the conversions are disabled.
+func priceRenderer(c *gin.Context, log *slog.Logger, pair domain.Pair, converter PriceConverter) (sse.Renderer, bool) {
+	newResponse := sse.NewPriceStreamResponse
+
+	switch fields := c.Query("fields"); fields {
+	case "":
+	case fieldsFull:
+		newResponse = sse.NewFullPriceStreamResponse
+	default:
+		log.Error("Invalid fields parameter", "fields", fields, "request_id", RequestIDFromContext(c))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fields parameter"})
+		return nil, false
+	}
+
+	switch precision := c.Query("precision"); precision {
+	case "":
+	case precisionNano:
+		newSecondsResponse := newResponse
+		newResponse = func(update domain.PriceUpdate) sse.PriceStreamResponse {
+			response := newSecondsResponse(update)
+			response.ReceivedAt = update.ReceivedAt.Format(time.RFC3339Nano)
+			return response
+		}
+	default:
+		log.Error("Invalid precision parameter", "precision", precision, "request_id", RequestIDFromContext(c))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid precision parameter"})
+		return nil, false
+	}
+
+	convert := c.Query("convert")
+	if convert == "" {
+		return func(update domain.PriceUpdate) (any, error) {
+			return newResponse(update), nil
+		}, true
+	}
+
+	if converter == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Conversion is disabled"})
+		return nil, false
+	}
+
+	to := domain.Currency(strings.ToUpper(convert))
+	if !converter.Supports(to) || !converter.Supports(pair.To) || to == pair.From {
+		log.Error("Invalid convert parameter", "convert", convert, "pair", pair.String(), "request_id", RequestIDFromContext(c))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid convert parameter"})
+		return nil, false
+	}
+
+	return func(update domain.PriceUpdate) (any, error) {
+		converted, conversion, err := converter.Convert(update, to)
+		if err != nil {
+			return nil, err
+		}
+
+		response := newResponse(converted)
+		response.Conversion = &sse.ConversionResponse{
+			From:   string(conversion.From),
+			Rate:   conversion.Rate.String(),
+			RateAt: conversion.RateAt.Format(time.RFC3339Nano),
+		}
+
+		return response, nil
+	}, true
+}
@@ -4,6 +4,8 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -17,41 +19,46 @@ import (
 type SseClientsManager interface {
 	RegisterClient(client *sse.Client)
 	UnregisterClient(client *sse.Client)
-	GetHistory(pair domain.Pair, since time.Time) []domain.PriceUpdate
+	GetHistory(pair domain.Pair, since time.Time, limit int) []domain.PriceUpdate
 }
 
 type PriceStreamer struct {
-	log            *slog.Logger
-	cfg            *config.Config
-	clientsManager SseClientsManager
+	log               *slog.Logger
+	clientsManager    SseClientsManager
+	converter         PriceConverter
+	clientsBufferSize atomic.Int64
+	closeOnce         sync.Once
+	done              chan struct{}
 }
 
-func NewPriceStreamer(cfg *config.Config, clientsManager SseClientsManager) *PriceStreamer {
-	return &PriceStreamer{
+// NewPriceStreamer streams the prices to the clients, converted on request when a converter is given.
+func NewPriceStreamer(cfg *config.Config, clientsManager SseClientsManager, converter PriceConverter) *PriceStreamer {
+	h := &PriceStreamer{
 		log:            slog.Default(),
-		cfg:            cfg,
 		clientsManager: clientsManager,
+		converter:      converter,
+		done:           make(chan struct{}),
 	}
-}
 
-func (h *PriceStreamer) Stream(c *gin.Context) {
-	w := c.Writer
+	h.SetClientsBufferSize(cfg.SseClientsBufferSize)
 
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Transfer-Encoding", "chunked")
+	return h
+}
 
-	clientID := uuid.New().String()
-	client, err := sse.NewClient(clientID, c.Writer, h.cfg.SseClientsBufferSize)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Streaming not supported"})
-		return
-	}
+// SetClientsBufferSize changes the buffer size of the clients connecting from now on.
+func (h *PriceStreamer) SetClientsBufferSize(size int) {
+	h.clientsBufferSize.Store(int64(size))
+}
 
-	h.clientsManager.RegisterClient(client)
-	defer h.clientsManager.UnregisterClient(client)
+// Close ends the running streams, so that the server can shut down gracefully without waiting for the clients
+// to disconnect. Streams requested afterward end right away.
+func (h *PriceStreamer) Close() {
+	h.closeOnce.Do(func() {
+		close(h.done)
+	})
+}
 
+func (h *PriceStreamer) Stream(c *gin.Context) {
 	var pair = domain.Pair{
 		From: domain.BTC,
 		To:   domain.USD,
@@ -59,25 +66,54 @@ func (h *PriceStreamer) Stream(c *gin.Context) {
 
 	// Parse the 'pair' parameter to listen to prices from
 	if pairParam := c.Param("pair"); pairParam != "" {
+		var err error
 		pair, err = domain.NewPairFromString(pairParam)
 		if err != nil {
-			h.log.Error("Invalid pair parameter", "pair", pairParam, "error", err.Error())
+			h.log.Error("Invalid pair parameter", "pair", pairParam, "request_id", RequestIDFromContext(c), "error", err.Error())
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pair parameter"})
 			return
 		}
 	}
 
-	// Stream historical data if the 'since' parameter is provided
+	render, ok := priceRenderer(c, h.log, pair, h.converter)
+	if !ok {
+		return
+	}
+
+	var since time.Time
 	if sinceParam := c.Query("since"); sinceParam != "" {
 		timestamp, err := strconv.ParseInt(sinceParam, 10, 64)
 		if err != nil {
-			h.log.Error("Invalid since parameter", "since", sinceParam, "error", err.Error())
+			h.log.Error("Invalid since parameter", "since", sinceParam, "request_id", RequestIDFromContext(c), "error", err.Error())
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since parameter"})
 			return
 		}
-		since := time.Unix(timestamp, 0)
+		since = time.Unix(timestamp, 0)
+	}
 
-		history := h.clientsManager.GetHistory(pair, since)
+	w := c.Writer
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Transfer-Encoding", "chunked")
+
+	bufferSize := int(h.clientsBufferSize.Load())
+
+	clientID := uuid.New().String()
+	client, err := sse.NewClient(clientID, c.Writer, bufferSize, render)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Streaming not supported"})
+		return
+	}
+
+	// the client is registered once the request is valid, with its renderer set
+	h.clientsManager.RegisterClient(client)
+	defer h.clientsManager.UnregisterClient(client)
+
+	// Stream historical data if the 'since' parameter is provided, up to what the client buffer holds before listening
+	if !since.IsZero() {
+		history := h.clientsManager.GetHistory(pair, since, bufferSize)
 		for _, priceUpdate := range history {
 			if err := client.Send(priceUpdate); err != nil {
 				h.log.Error("Failed to send history price update", "error", err.Error())
@@ -85,7 +121,27 @@ func (h *PriceStreamer) Stream(c *gin.Context) {
 		}
 	}
 
-	go client.Listen(pair)
+	// flush the headers so that clients know the stream is established before the first update
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Writer.Flush()
+
+	// blocks while the client is connected
+	listenUntilDone(c, client, pair, h.done)
+
+	c.Set(SSEClientIDKey, client.ID())
+	c.Set(SSEEventsSentKey, client.EventsSent())
+}
+
+// listenUntilDone streams the updates of the pair to the client until the request ends or done is closed. It listens on
+// the calling goroutine, so that the client no longer writes to the response once the handler returns.
+func listenUntilDone(c *gin.Context, client *sse.Client, pair domain.Pair, done <-chan struct{}) {
+	go func() {
+		select {
+		case <-c.Request.Context().Done():
+		case <-done:
+		}
+		client.Close()
+	}()
 
-	<-c.Request.Context().Done() // blocks while the client is connected
+	client.Listen(pair)
 }
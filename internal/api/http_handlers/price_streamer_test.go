@@ -1,9 +1,11 @@
 package http_handlers
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
 	"github.com/tonytcb/crypto-pricing-api/internal/domain"
@@ -18,20 +21,26 @@ import (
 	"github.com/tonytcb/crypto-pricing-api/test/mocks"
 )
 
+// MockSseClientsManager records the calls with the clients IDs, as formatting a client would read its state while it
+// streams. The registered clients are sent to registered, when set.
 type MockSseClientsManager struct {
 	mock.Mock
+	registered chan *sse.Client
 }
 
 func (m *MockSseClientsManager) RegisterClient(client *sse.Client) {
-	m.Called(client)
+	m.Called(client.ID())
+	if m.registered != nil {
+		m.registered <- client
+	}
 }
 
 func (m *MockSseClientsManager) UnregisterClient(client *sse.Client) {
-	m.Called(client)
+	m.Called(client.ID())
 }
 
-func (m *MockSseClientsManager) GetHistory(pair domain.Pair, since time.Time) []domain.PriceUpdate {
-	args := m.Called(pair, since)
+func (m *MockSseClientsManager) GetHistory(pair domain.Pair, since time.Time, limit int) []domain.PriceUpdate {
+	args := m.Called(pair, since, limit)
 	return args.Get(0).([]domain.PriceUpdate)
 }
 
@@ -39,50 +48,127 @@ func TestPriceStreamer_Stream(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	t.Run("Default BTCUSD pair streaming", func(t *testing.T) {
-		clientsManager := new(MockSseClientsManager)
+		registered := make(chan *sse.Client, 1)
+		clientsManager := &MockSseClientsManager{registered: registered}
 		clientsManager.On("RegisterClient", mock.Anything).Return()
+		clientsManager.On("UnregisterClient", mock.Anything).Return()
 
 		cfg := &config.Config{SseClientsBufferSize: 10}
-		handler := NewPriceStreamer(cfg, clientsManager)
+		handler := NewPriceStreamer(cfg, clientsManager, nil)
 
 		w := mocks.NewThreadSafeRecorder()
-		c, _ := gin.CreateTestContext(w.Recorder())
+		c, _ := gin.CreateTestContext(w)
 		c.Request = httptest.NewRequest(http.MethodGet, "/stream", nil)
 
-		go handler.Stream(c)
+		stop := streamInBackground(handler, c)
 
-		assert.Eventually(t, func() bool {
-			return w.Code() == http.StatusOK
-		}, 100*time.Millisecond, 10*time.Millisecond, "Status code should be OK")
+		waitRegistered(t, registered)
+		require.True(t, w.WaitFlushed(), "Expected the headers flushed")
 
+		assert.Equal(t, http.StatusOK, w.Code())
+		assert.Equal(t, "text/event-stream", w.GetHeader("Content-Type"))
 		assert.Empty(t, w.BodyString())
 
+		stop()
+
 		clientsManager.AssertExpectations(t)
 	})
 
 	t.Run("Custom pair streaming", func(t *testing.T) {
-		clientsManager := new(MockSseClientsManager)
+		registered := make(chan *sse.Client, 1)
+		clientsManager := &MockSseClientsManager{registered: registered}
 		clientsManager.On("RegisterClient", mock.Anything).Return()
+		clientsManager.On("UnregisterClient", mock.Anything).Return()
 
 		cfg := &config.Config{SseClientsBufferSize: 10}
-		handler := NewPriceStreamer(cfg, clientsManager)
+		handler := NewPriceStreamer(cfg, clientsManager, nil)
 
 		w := mocks.NewThreadSafeRecorder()
-		c, _ := gin.CreateTestContext(w.Recorder())
+		c, _ := gin.CreateTestContext(w)
 		c.Request = httptest.NewRequest(http.MethodGet, "/stream/ETHUSD", nil)
 		c.Params = []gin.Param{{Key: "pair", Value: "ETHUSD"}}
 
-		go handler.Stream(c)
+		stop := streamInBackground(handler, c)
 
-		assert.Eventually(t, func() bool {
-			return w.Code() == http.StatusOK
-		}, 100*time.Millisecond, 10*time.Millisecond, "Status code should be OK")
+		waitRegistered(t, registered)
+		require.True(t, w.WaitFlushed(), "Expected the headers flushed")
 
+		assert.Equal(t, http.StatusOK, w.Code())
+		assert.Equal(t, "text/event-stream", w.GetHeader("Content-Type"))
 		assert.Empty(t, w.BodyString())
 
+		stop()
+
 		clientsManager.AssertExpectations(t)
 	})
 
+	t.Run("Closing ends the stream once the client no longer writes", func(t *testing.T) {
+		registered := make(chan *sse.Client, 1)
+		clientsManager := &MockSseClientsManager{registered: registered}
+		clientsManager.On("RegisterClient", mock.Anything).Return()
+		clientsManager.On("UnregisterClient", mock.Anything).Return()
+
+		handler := NewPriceStreamer(&config.Config{SseClientsBufferSize: 10}, clientsManager, nil)
+
+		w := mocks.NewThreadSafeRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/stream", nil)
+
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			handler.Stream(c)
+		}()
+
+		client := waitRegistered(t, registered)
+		require.True(t, w.WaitFlushed(), "Expected the headers flushed")
+
+		handler.Close()
+
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("Expected the stream to end")
+		}
+
+		assert.True(t, client.IsClosed(), "Expected the client closed before the handler returns")
+		assert.NoError(t, client.Send(domain.PriceUpdate{Pair: domain.NewPair(domain.BTC, domain.USD), Price: decimal.NewFromInt(1)}))
+		assert.Empty(t, w.BodyString(), "Expected nothing written once the stream ended")
+
+		clientsManager.AssertCalled(t, "UnregisterClient", client.ID())
+	})
+
+	t.Run("Invalid parameters are rejected before registering the client", func(t *testing.T) {
+		tests := []struct {
+			url     string
+			pair    string
+			wantErr string
+		}{
+			{url: "/stream?fields=all", wantErr: "Invalid fields parameter"},
+			{url: "/stream?convert=EUR", wantErr: "Conversion is disabled"},
+			{url: "/stream?since=yesterday", wantErr: "Invalid since parameter"},
+			{url: "/stream/XXXYYY", pair: "XXXYYY", wantErr: "Invalid pair parameter"},
+		}
+
+		for _, tt := range tests {
+			clientsManager := new(MockSseClientsManager)
+			handler := NewPriceStreamer(&config.Config{SseClientsBufferSize: 10}, clientsManager, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.pair != "" {
+				c.Params = []gin.Param{{Key: "pair", Value: tt.pair}}
+			}
+
+			handler.Stream(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, tt.url)
+			assert.Contains(t, w.Body.String(), tt.wantErr, tt.url)
+			clientsManager.AssertNotCalled(t, "RegisterClient", mock.Anything)
+		}
+	})
+
 	t.Run("Historical data streaming", func(t *testing.T) {
 		now := time.Now()
 		sinceTime := now.Add(-1 * time.Hour)
@@ -95,32 +181,64 @@ func TestPriceStreamer_Stream(t *testing.T) {
 			{Pair: btcUsd, Price: decimal.NewFromFloat(51000), ReceivedAt: now.Add(-15 * time.Second)},
 		}
 
-		clientsManager := new(MockSseClientsManager)
+		registered := make(chan *sse.Client, 1)
+		clientsManager := &MockSseClientsManager{registered: registered}
 		clientsManager.On("RegisterClient", mock.Anything).Return()
-		clientsManager.On("GetHistory", btcUsd, mock.Anything).Return(history)
+		clientsManager.On("UnregisterClient", mock.Anything).Return()
+		clientsManager.On("GetHistory", btcUsd, time.Unix(sinceTime.Unix(), 0), 10).Return(history)
 
 		cfg := &config.Config{SseClientsBufferSize: 10}
-		handler := NewPriceStreamer(cfg, clientsManager)
+		handler := NewPriceStreamer(cfg, clientsManager, nil)
 
-		w := httptest.NewRecorder()
+		w := mocks.NewThreadSafeRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Request = httptest.NewRequest(http.MethodGet, "/?since="+sinceTimestamp, nil)
-		c.Request.URL.RawQuery = "since=" + sinceTimestamp
 
-		// Create a channel to signal when we're done with the test
-		done := make(chan struct{})
+		stop := streamInBackground(handler, c)
 
-		go func() {
-			go func() {
-				time.Sleep(50 * time.Millisecond)
-				close(done)
-			}()
-			handler.Stream(c)
-		}()
+		waitRegistered(t, registered)
+
+		// the headers are flushed first, then each update of the history
+		for range len(history) + 1 {
+			require.True(t, w.WaitFlushed(), "Expected the headers and the history flushed")
+		}
+
+		assert.Equal(t, http.StatusOK, w.Code())
+		assert.Equal(t, 2, strings.Count(w.BodyString(), "data: "))
+		assert.Contains(t, w.BodyString(), `"price":"50000"`)
+		assert.Contains(t, w.BodyString(), `"price":"51000"`)
 
-		// Wait for the test to complete
-		<-done
+		stop()
 
 		clientsManager.AssertExpectations(t)
 	})
 }
+
+// streamInBackground streams until the returned function is called, which returns once the stream is closed.
+func streamInBackground(handler *PriceStreamer, c *gin.Context) func() {
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	c.Request = c.Request.WithContext(ctx)
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		handler.Stream(c)
+	}()
+
+	return func() {
+		cancel()
+		<-stopped
+	}
+}
+
+func waitRegistered(t *testing.T, registered <-chan *sse.Client) *sse.Client {
+	t.Helper()
+
+	select {
+	case client := <-registered:
+		return client
+	case <-time.After(time.Second):
+		t.Fatal("Expected the client registered")
+		return nil
+	}
+}
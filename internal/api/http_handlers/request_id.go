@@ -0,0 +1,53 @@
+package http_handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	RequestIDKey    = "request_id"
+
+	maxRequestIDLength = 128
+)
+
+type RequestIDHandler struct {
+}
+
+func NewRequestIDHandler() *RequestIDHandler {
+	return &RequestIDHandler{}
+}
+
+// Handle propagates the X-Request-ID sent by the caller, or assigns a new one when it is missing or invalid.
+// The ID is exposed to the next handlers through the gin context and echoed back in the response headers.
+func (h RequestIDHandler) Handle(c *gin.Context) {
+	requestID := c.GetHeader(RequestIDHeader)
+	if !isValidRequestID(requestID) {
+		requestID = uuid.New().String()
+	}
+
+	c.Set(RequestIDKey, requestID)
+	c.Header(RequestIDHeader, requestID)
+
+	c.Next()
+}
+
+// RequestIDFromContext returns the request ID assigned by RequestIDHandler, if any.
+func RequestIDFromContext(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
+
+func isValidRequestID(v string) bool {
+	if v == "" || len(v) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range v {
+		if r < 0x21 || r > 0x7e { // printable ASCII only, no spaces
+			return false
+		}
+	}
+
+	return true
+}
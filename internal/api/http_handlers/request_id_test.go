@@ -0,0 +1,60 @@
+package http_handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDHandler_Handle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(captured *string) *gin.Engine {
+		router := gin.New()
+		router.Use(NewRequestIDHandler().Handle)
+		router.GET("/", func(c *gin.Context) {
+			*captured = RequestIDFromContext(c)
+			c.Status(http.StatusOK)
+		})
+		return router
+	}
+
+	tests := []struct {
+		name          string
+		headerValue   string
+		expectInbound bool
+	}{
+		{name: "propagates inbound request id", headerValue: "abc-123", expectInbound: true},
+		{name: "generates request id when missing", headerValue: "", expectInbound: false},
+		{name: "generates request id when invalid", headerValue: "has spaces", expectInbound: false},
+		{name: "generates request id when too long", headerValue: strings.Repeat("a", 129), expectInbound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured string
+			router := newRouter(&captured)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.headerValue != "" {
+				req.Header.Set(RequestIDHeader, tt.headerValue)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.NotEmpty(t, captured)
+			assert.Equal(t, captured, w.Header().Get(RequestIDHeader))
+
+			if tt.expectInbound {
+				assert.Equal(t, tt.headerValue, captured)
+			} else {
+				assert.NotEqual(t, tt.headerValue, captured)
+			}
+		})
+	}
+}
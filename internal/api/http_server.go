@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
@@ -20,21 +21,76 @@ type CorsHandler interface {
 	Allowed(c *gin.Context)
 }
 
+type RequestIDHandler interface {
+	Handle(c *gin.Context)
+}
+
+type AccessLogHandler interface {
+	Log(c *gin.Context)
+}
+
 type PriceStreamingHandler interface {
 	Stream(c *gin.Context)
 }
 
+type PriceHistoryHandler interface {
+	History(c *gin.Context)
+}
+
+type PriceLatestHandler interface {
+	Latest(c *gin.Context)
+}
+
+type PriceIndicatorsHandler interface {
+	Indicators(c *gin.Context)
+	Stream(c *gin.Context)
+}
+
+type PriceExportHandler interface {
+	Export(c *gin.Context)
+}
+
+type CurrenciesHandler interface {
+	List(c *gin.Context)
+}
+
+type PairsHandler interface {
+	List(c *gin.Context)
+}
+
+type AdminAuthHandler interface {
+	Authorize(c *gin.Context)
+}
+
+type AdminSnapshotHandler interface {
+	Snapshot(c *gin.Context)
+}
+
+type AdminReplayHandler interface {
+	Step(c *gin.Context)
+}
+
 type HTTPHandlers struct {
-	HealthHandler         HealthHandler
-	CorsHandler           CorsHandler
-	PriceStreamingHandler PriceStreamingHandler
+	RequestIDHandler       RequestIDHandler
+	AccessLogHandler       AccessLogHandler
+	HealthHandler          HealthHandler
+	CorsHandler            CorsHandler
+	PriceStreamingHandler  PriceStreamingHandler
+	PriceHistoryHandler    PriceHistoryHandler
+	PriceLatestHandler     PriceLatestHandler
+	PriceIndicatorsHandler PriceIndicatorsHandler
+	PriceExportHandler     PriceExportHandler
+	CurrenciesHandler      CurrenciesHandler
+	PairsHandler           PairsHandler
+	AdminAuthHandler       AdminAuthHandler
+	AdminSnapshotHandler   AdminSnapshotHandler
+	AdminReplayHandler     AdminReplayHandler
 }
 
 type HTTPServer struct {
-	log      *slog.Logger
-	srv      *http.Server
-	cfg      *config.Config
-	handlers HTTPHandlers
+	log *slog.Logger
+	srv *http.Server
+	cfg *config.Config
 }
 
 func NewHTTPServer(
@@ -43,27 +99,55 @@ func NewHTTPServer(
 	handlers HTTPHandlers,
 ) *HTTPServer {
 	return &HTTPServer{
-		log:      log,
-		cfg:      cfg,
-		handlers: handlers,
+		log: log,
+		cfg: cfg,
+		srv: &http.Server{
+			Addr:    cfg.RestAPIPort,
+			Handler: newRouter(handlers),
+		},
 	}
 }
 
-func (m *HTTPServer) Start() error {
+func newRouter(handlers HTTPHandlers) http.Handler {
 	gin.SetMode(gin.ReleaseMode)
 
 	router := gin.New()
-	router.Use(gin.Recovery())
-
-	router.GET("/health", m.handlers.HealthHandler.IsHealthy)
-	router.GET("/prices/:pair/stream", m.handlers.CorsHandler.Allowed, m.handlers.PriceStreamingHandler.Stream)
+	router.Use(
+		gin.Recovery(),
+		handlers.RequestIDHandler.Handle,
+		handlers.AccessLogHandler.Log,
+	)
+
+	router.GET("/health", handlers.HealthHandler.IsHealthy)
+	router.GET("/prices/:pair/stream", handlers.CorsHandler.Allowed, handlers.PriceStreamingHandler.Stream)
+	router.GET("/prices/:pair/history", handlers.CorsHandler.Allowed, handlers.PriceHistoryHandler.History)
+	router.GET("/prices/:pair/latest", handlers.CorsHandler.Allowed, handlers.PriceLatestHandler.Latest)
+	router.GET("/prices/:pair/indicators", handlers.CorsHandler.Allowed, handlers.PriceIndicatorsHandler.Indicators)
+	router.GET("/prices/:pair/indicators/stream", handlers.CorsHandler.Allowed, handlers.PriceIndicatorsHandler.Stream)
+	router.GET("/prices/:pair/export", handlers.CorsHandler.Allowed, handlers.PriceExportHandler.Export)
+	router.GET("/currencies", handlers.CorsHandler.Allowed, handlers.CurrenciesHandler.List)
+	router.GET("/pairs", handlers.CorsHandler.Allowed, handlers.PairsHandler.List)
+
+	admin := router.Group("/admin", handlers.AdminAuthHandler.Authorize)
+	admin.POST("/snapshot", handlers.AdminSnapshotHandler.Snapshot)
+	admin.POST("/replay/step", handlers.AdminReplayHandler.Step)
+
+	return router.Handler()
+}
 
-	m.srv = &http.Server{
-		Addr:    m.cfg.RestAPIPort,
-		Handler: router.Handler(),
+// Start listens on the configured address and serves until the server is stopped.
+func (m *HTTPServer) Start() error {
+	listener, err := net.Listen("tcp", m.cfg.RestAPIPort)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen")
 	}
 
-	if err := m.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	return m.Serve(listener)
+}
+
+// Serve serves on the given listener until the server is stopped, e.g. on an ephemeral port in tests.
+func (m *HTTPServer) Serve(listener net.Listener) error {
+	if err := m.srv.Serve(listener); err != nil && err != http.ErrServerClosed {
 		return err
 	}
 
@@ -2,86 +2,258 @@ package app
 
 import (
 	"context"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"sync"
 
 	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/tonytcb/crypto-pricing-api/internal/api"
 	"github.com/tonytcb/crypto-pricing-api/internal/api/http_handlers"
 	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/analytics"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/backfill"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
 	"github.com/tonytcb/crypto-pricing-api/internal/infra/coindesk"
 	"github.com/tonytcb/crypto-pricing-api/internal/infra/event_listener"
-	"github.com/tonytcb/crypto-pricing-api/internal/infra/event_provider"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/export"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/pubsub"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/rates"
 	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
-	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/in_memory"
 )
 
 type Application struct {
-	cfg            *config.Config
-	log            *slog.Logger
-	httpServer     *api.HTTPServer
-	eventProvider  *event_provider.HTTPPulling
-	eventListener  *event_listener.PricesListener
-	clientsManager *sse.Hub
+	mu              sync.Mutex
+	cfg             *config.Config
+	log             *slog.Logger
+	logLevel        *slog.LevelVar
+	httpServer      *api.HTTPServer
+	eventProvider   EventProvider
+	pairsPoller     *pairsPoller
+	eventListeners  []*event_listener.PricesListener
+	redisClient     *redis.Client
+	clientsManager  *sse.Hub
+	pricesRepo      sse.PricesRepository
+	priceStreamer   *http_handlers.PriceStreamer
+	priceIndicators *http_handlers.PriceIndicators
+	monitoredPairs  *monitoredPairs
+	listener        net.Listener
+	cancel          context.CancelFunc
 }
 
-func NewApplication(ctx context.Context, cfg *config.Config, log *slog.Logger) (*Application, error) {
-	pairToMonitor, err := cfg.PairToMonitor()
+func NewApplication(
+	ctx context.Context,
+	cfg *config.Config,
+	log *slog.Logger,
+	logLevel *slog.LevelVar,
+	opts ...Option,
+) (_ *Application, err error) {
+	// the pollers, backfills and rates refreshes started are stopped with the application
+	ctx, cancel := context.WithCancel(ctx)
+
+	// the components started or opened so far are released when the application can't be created
+	var cleanups []func()
+	defer func() {
+		if err != nil {
+			cancel()
+			for i := len(cleanups) - 1; i >= 0; i-- {
+				cleanups[i]()
+			}
+		}
+	}()
+
+	var injected components
+	for _, opt := range opts {
+		opt(&injected)
+	}
+
+	clk := injected.clock
+	if clk == nil {
+		clk = clock.New()
+	}
+
+	pairsToMonitor, err := cfg.PairsToMonitor()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse pairs to monitor configuration")
+	}
+
+	pairsToDerive, err := cfg.PairsToDerive()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse synthetic pairs configuration")
+	}
+
+	redisClient, err := newRedisClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if redisClient != nil {
+		cleanups = append(cleanups, func() { _ = redisClient.Close() })
+	}
+
+	pricesRepo := injected.pricesRepo
+	if pricesRepo == nil {
+		if pricesRepo, err = newPricesRepository(cfg, redisClient); err != nil {
+			return nil, err
+		}
+	}
+	cleanups = append(cleanups, func() { closeComponent(log, "prices repository", pricesRepo) })
+
+	snapshotter := newRepositorySnapshotter(cfg.SnapshotPath, pricesRepo)
+
+	var snapshotWriter http_handlers.SnapshotWriter
+	if snapshotter != nil {
+		snapshotWriter = snapshotter
+	}
+
+	if cfg.SnapshotRestoreOnStartup {
+		if snapshotter == nil {
+			return nil, errors.Errorf("the %s store type does not support snapshots", cfg.StoreType)
+		}
+		if err = snapshotter.restore(log, pricesRepo); err != nil {
+			return nil, err
+		}
+	}
+
+	var exportSource export.RangeSource
+	if source, ok := pricesRepo.(export.RangeSource); ok {
+		exportSource = source
+	}
+
+	coinDeskHTTPClient := injected.httpClient
+	if coinDeskHTTPClient == nil {
+		coinDeskHTTPClient = &http.Client{Timeout: cfg.CoinDeskClientTimeout}
+	}
+
+	priceAPI := coindesk.NewPricingAPI(coinDeskHTTPClient, cfg, clk)
+
+	var (
+		pricesEventProvider = injected.eventProvider
+		replayStepper       http_handlers.ReplayStepper
+	)
+	if pricesEventProvider == nil {
+		if pricesEventProvider, replayStepper, err = newEventProvider(cfg, priceAPI, clk); err != nil {
+			return nil, err
+		}
+	}
+	cleanups = append(cleanups, func() { closeComponent(log, "prices event provider", pricesEventProvider) })
+
+	converter, err := newPriceConverter(ctx, cfg, priceAPI, clk)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to parse pair to monitor configuration")
+		return nil, err
 	}
 
 	var (
-		coinDeskHTTPClient  = &http.Client{Timeout: cfg.CoinDeskClientTimeout}
-		priceAPI            = coindesk.NewPricingAPI(coinDeskHTTPClient, cfg)
-		pricesEventProvider = event_provider.NewHTTPPulling(priceAPI, cfg.PricesPullingInterval, cfg.PricesChannelBufferSize)
-		pricesRepo          = in_memory.NewPricesByRingBuffer(cfg.StoreMaxItems)
-		clientsManager      = sse.NewHub(pricesRepo, cfg.SSEClientsCleanUpInterval)
+		clientsManager = sse.NewHub(pricesRepo, cfg.SSEClientsCleanUpInterval, cfg.PricesChannelBufferSize, clk)
+		priceStreamer  = http_handlers.NewPriceStreamer(cfg, clientsManager, converter)
+		poller         = newPairsPoller(ctx, pricesEventProvider, cfg.PricesChannelBufferSize)
+		monitored      = newMonitoredPairs(pairsToMonitor)
 	)
 
-	eventsChan, err := pricesEventProvider.Start(ctx, pairToMonitor)
+	if cfg.BackfillEnabled {
+		backfill.NewBackfiller(priceAPI, pricesRepo, cfg.BackfillPeriod, cfg.BackfillInterval, clk).Run(ctx, pairsToMonitor)
+	}
+
+	if cfg.PricesPullingEnabled {
+		if err = poller.Sync(pairsToMonitor); err != nil {
+			return nil, errors.Wrap(err, "failed to start event provider")
+		}
+	}
+
+	var (
+		clientsNotifier    event_listener.Notifier = clientsManager
+		indicatorsProvider http_handlers.IndicatorsProvider
+	)
+
+	// the indicators are computed on every instance, from the prices received, synthetic ones included
+	if cfg.AnalyticsEnabled {
+		windows, err := cfg.AnalyticsWindowSizes()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse analytics windows configuration")
+		}
+
+		engine := analytics.NewEngine(windows)
+		clientsNotifier = analytics.NewIndicatorsNotifier(clientsNotifier, engine)
+		indicatorsProvider = engine
+	}
+
+	// the synthetic prices are derived on every instance, from the quoted ones received
+	if len(pairsToDerive) > 0 {
+		clientsNotifier = rates.NewTriangulatingNotifier(clientsNotifier, rates.NewEngine(pairsToDerive))
+	}
+
+	eventListeners, err := newEventListeners(ctx, cfg, redisClient, poller, clientsNotifier)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to start event provider")
+		return nil, err
 	}
 
-	eventListener := event_listener.NewPricesListener(clientsManager, eventsChan)
+	priceIndicators := http_handlers.NewPriceIndicators(cfg, clientsManager, indicatorsProvider)
 
 	handlers := api.HTTPHandlers{
-		CorsHandler:           http_handlers.NewCorsHandler(),
-		HealthHandler:         http_handlers.NewHealthHandler(),
-		PriceStreamingHandler: http_handlers.NewPriceStreamer(cfg, clientsManager),
+		RequestIDHandler:       http_handlers.NewRequestIDHandler(),
+		AccessLogHandler:       http_handlers.NewAccessLogHandler(),
+		CorsHandler:            http_handlers.NewCorsHandler(),
+		HealthHandler:          http_handlers.NewHealthHandler(),
+		PriceStreamingHandler:  priceStreamer,
+		PriceHistoryHandler:    http_handlers.NewPriceHistory(clientsManager, converter),
+		PriceLatestHandler:     http_handlers.NewPriceLatest(clientsManager, converter),
+		PriceIndicatorsHandler: priceIndicators,
+		PriceExportHandler:     http_handlers.NewPriceExport(exportSource),
+		CurrenciesHandler:      http_handlers.NewCurrencies(domain.DefaultCurrencies),
+		PairsHandler:           http_handlers.NewPairs(monitored, pairsToDerive),
+		AdminAuthHandler:       http_handlers.NewAdminAuthHandler(cfg.AdminAPIToken),
+		AdminSnapshotHandler:   http_handlers.NewAdminSnapshot(snapshotWriter),
+		AdminReplayHandler:     http_handlers.NewAdminReplay(replayStepper),
 	}
 
 	httpServer := api.NewHTTPServer(log, cfg, handlers)
 
 	return &Application{
-		cfg:            cfg,
-		log:            log,
-		httpServer:     httpServer,
-		eventProvider:  pricesEventProvider,
-		eventListener:  eventListener,
-		clientsManager: clientsManager,
+		cfg:             cfg,
+		log:             log,
+		logLevel:        logLevel,
+		httpServer:      httpServer,
+		eventProvider:   pricesEventProvider,
+		pairsPoller:     poller,
+		eventListeners:  eventListeners,
+		redisClient:     redisClient,
+		clientsManager:  clientsManager,
+		pricesRepo:      pricesRepo,
+		priceStreamer:   priceStreamer,
+		priceIndicators: priceIndicators,
+		monitoredPairs:  monitored,
+		listener:        injected.listener,
+		cancel:          cancel,
 	}, nil
 }
 
-func (a Application) Run(ctx context.Context) error {
+func (a *Application) Run(ctx context.Context) error {
 	errGroup, _ := errgroup.WithContext(ctx)
 
 	a.log.Info("Running application")
 
 	errGroup.Go(func() error {
+		if a.listener != nil {
+			a.log.Info("Starting http server", "address", a.listener.Addr().String())
+			return a.httpServer.Serve(a.listener)
+		}
+
 		a.log.Info("Starting http server", "port", a.cfg.RestAPIPort)
 
 		return a.httpServer.Start()
 	})
 
-	errGroup.Go(func() error {
-		a.log.Info("Starting prices event listener")
+	for _, eventListener := range a.eventListeners {
+		errGroup.Go(func() error {
+			a.log.Info("Starting prices event listener")
 
-		return a.eventListener.Start(ctx)
-	})
+			return eventListener.Start(ctx)
+		})
+	}
 
 	errGroup.Go(func() error {
 		a.log.Info("Starting clients manager")
@@ -94,9 +266,59 @@ func (a Application) Run(ctx context.Context) error {
 	return errGroup.Wait()
 }
 
-func (a Application) Stop() {
+func (a *Application) Stop() {
 	a.log.Info("Stopping application")
 
-	_ = a.httpServer.Stop()
+	// the streams are ended first, as the server waits for the running requests to finish
+	a.priceStreamer.Close()
+	a.priceIndicators.Close()
+	if err := a.httpServer.Stop(); err != nil {
+		a.log.Error("Failed to stop http server", "error", err.Error())
+	}
 	a.clientsManager.Stop()
+	a.cancel()
+
+	closeComponent(a.log, "prices event provider", a.eventProvider)
+	closeComponent(a.log, "prices repository", a.pricesRepo)
+
+	if a.redisClient != nil {
+		_ = a.redisClient.Close()
+	}
+}
+
+// closeComponent closes the component when it holds resources to release.
+func closeComponent(log *slog.Logger, name string, component any) {
+	if closer, ok := component.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Error("Failed to close "+name, "error", err.Error())
+		}
+	}
+}
+
+// newEventListeners delivers the polled prices to the clients. With the redis fan-out, the polled prices are
+// published to every instance instead, and the published ones are delivered to the clients.
+func newEventListeners(
+	ctx context.Context,
+	cfg *config.Config,
+	redisClient *redis.Client,
+	poller *pairsPoller,
+	clientsNotifier event_listener.Notifier,
+) ([]*event_listener.PricesListener, error) {
+	if cfg.PricesFanout != config.FanoutRedis {
+		return []*event_listener.PricesListener{
+			event_listener.NewPricesListener(clientsNotifier, poller.Events()),
+		}, nil
+	}
+
+	notifier := pubsub.NewRedisNotifier(redisClient, cfg.PricesFanoutChannel, cfg.PricesChannelBufferSize)
+
+	published, err := notifier.Subscribe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*event_listener.PricesListener{
+		event_listener.NewPricesListener(notifier, poller.Events()),
+		event_listener.NewPricesListener(clientsNotifier, published),
+	}, nil
 }
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/in_memory"
+)
+
+// closableProvider fails to start the ETHUSD pair, recording the contexts of the pairs started and whether it's closed.
+type closableProvider struct {
+	mu       sync.Mutex
+	contexts []context.Context
+	closed   bool
+}
+
+func (p *closableProvider) Start(ctx context.Context, pair domain.Pair) (<-chan domain.PriceUpdate, error) {
+	if pair == domain.NewPair(domain.ETH, domain.USD) {
+		return nil, errors.New("unsupported pair")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.contexts = append(p.contexts, ctx)
+
+	return make(chan domain.PriceUpdate), nil
+}
+
+func (p *closableProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+type closableRepository struct {
+	*in_memory.PricesByRingBuffer
+	closed bool
+}
+
+func (r *closableRepository) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestNewApplication(t *testing.T) {
+	t.Run("Should release the components opened when failing", func(t *testing.T) {
+		cfg := &config.Config{
+			LogLevel:                  "info",
+			RestAPIPort:               ":8080",
+			PairPriceToMonitor:        "BTCUSD,ETHUSD",
+			StoreMaxItems:             10,
+			SseClientsBufferSize:      10,
+			SSEClientsCleanUpInterval: time.Minute,
+			CoinDeskAPIURL:            "http://localhost/data/price",
+			CoinDeskRetryMaxAttempts:  1,
+			CoinDeskClientTimeout:     time.Second,
+			PricesPullingInterval:     time.Hour,
+			PricesChannelBufferSize:   10,
+			PricesPullingEnabled:      true,
+		}
+
+		var (
+			provider = &closableProvider{}
+			repo     = &closableRepository{PricesByRingBuffer: in_memory.NewPricesByRingBuffer(10)}
+			log      = slog.New(slog.NewTextHandler(io.Discard, nil))
+		)
+
+		_, err := NewApplication(context.Background(), cfg, log, new(slog.LevelVar),
+			WithEventProvider(provider), WithPricesRepository(repo))
+		require.Error(t, err)
+
+		assert.True(t, provider.closed, "Expected the event provider closed")
+		assert.True(t, repo.closed, "Expected the prices repository closed")
+
+		for _, ctx := range provider.contexts {
+			assert.Error(t, ctx.Err(), "Expected the pairs started to be stopped")
+		}
+	})
+}
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"log/slog"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -13,6 +17,40 @@ import (
 const (
 	mainEnvFile = ".env"
 	hide        = "hide"
+	maskedValue = "****"
+)
+
+const (
+	StoreTypeRingBuffer = "ring_buffer"
+	StoreTypeSlice      = "slice"
+	StoreTypeWAL        = "wal"
+	StoreTypeSQLite     = "sqlite"
+	StoreTypeRedis      = "redis"
+	StoreTypeTiered     = "tiered"
+	StoreTypeDownsample = "downsampled"
+	StoreTypeCompressed = "compressed"
+)
+
+const (
+	ProviderCoinDesk  = "coindesk"
+	ProviderReplay    = "replay"
+	ProviderSimulated = "simulated"
+)
+
+const (
+	FXSourceCoinDesk = "coindesk"
+	FXSourceFile     = "file"
+)
+
+// MinAnalyticsWindow and MaxAnalyticsWindow bound the number of prices the indicators are computed over
+const (
+	MinAnalyticsWindow = 2
+	MaxAnalyticsWindow = 10000
+)
+
+const (
+	FanoutLocal = "local"
+	FanoutRedis = "redis"
 )
 
 type Config struct {
@@ -21,12 +59,44 @@ type Config struct {
 	RestAPIPort string `mapstructure:"REST_API_PORT"`
 
 	PairPriceToMonitor        string        `mapstructure:"PAIR_PRICE_TO_MONITOR"`
+	SyntheticPairs            string        `mapstructure:"SYNTHETIC_PAIRS"`
 	StoreMaxItems             int           `mapstructure:"STORE_MAX_ITEMS"`
 	SseClientsBufferSize      int           `mapstructure:"SSE_CLIENTS_BUFFER_SIZE"`
 	SSEClientsCleanUpInterval time.Duration `mapstructure:"SSE_CLIENTS_CLEAN_UP_INTERVAL"`
 
+	// Storage configurations
+	StoreType                 string        `mapstructure:"STORE_TYPE"`
+	StoreWALDir               string        `mapstructure:"STORE_WAL_DIR"`
+	StoreWALFsyncPolicy       string        `mapstructure:"STORE_WAL_FSYNC_POLICY"`
+	StoreWALFsyncInterval     time.Duration `mapstructure:"STORE_WAL_FSYNC_INTERVAL"`
+	StoreWALSegmentMaxBytes   int64         `mapstructure:"STORE_WAL_SEGMENT_MAX_BYTES"`
+	StoreWALSegmentMaxAge     time.Duration `mapstructure:"STORE_WAL_SEGMENT_MAX_AGE"`
+	StoreWALRetention         time.Duration `mapstructure:"STORE_WAL_RETENTION"`
+	StoreWALMaxSegments       int           `mapstructure:"STORE_WAL_MAX_SEGMENTS"`
+	StoreSQLitePath           string        `mapstructure:"STORE_SQLITE_PATH"`
+	StoreSQLiteRetention      time.Duration `mapstructure:"STORE_SQLITE_RETENTION"`
+	StoreRedisKeyPrefix       string        `mapstructure:"STORE_REDIS_KEY_PREFIX"`
+	StoreRedisRetention       time.Duration `mapstructure:"STORE_REDIS_RETENTION"`
+	StoreTieredCold           string        `mapstructure:"STORE_TIERED_COLD"`
+	StoreDownsamplingPolicy   string        `mapstructure:"STORE_DOWNSAMPLING_POLICY"`
+	StoreDownsamplingInterval time.Duration `mapstructure:"STORE_DOWNSAMPLING_INTERVAL"`
+	StoreCompressedBlockSize  int           `mapstructure:"STORE_COMPRESSED_BLOCK_SIZE"`
+
+	// Snapshot configurations
+	SnapshotPath             string `mapstructure:"SNAPSHOT_PATH"`
+	SnapshotRestoreOnStartup bool   `mapstructure:"SNAPSHOT_RESTORE_ON_STARTUP"`
+
+	// Admin API configurations, the admin endpoints are disabled without a token
+	AdminAPIToken string `mapstructure:"ADMIN_API_TOKEN" config:"hide"`
+
+	// Redis configurations, used by the redis store and fan-out
+	RedisURL string `mapstructure:"REDIS_URL"`
+
 	// CoinDesk HTTP Client configurations
 	CoinDeskAPIURL           string        `mapstructure:"COIN_DESK_API_URL"`
+	CoinDeskHistoryAPIURL    string        `mapstructure:"COIN_DESK_HISTORY_API_URL"`
+	CoinDeskFullAPIURL       string        `mapstructure:"COIN_DESK_FULL_API_URL"`
+	CoinDeskAPIKey           string        `mapstructure:"COIN_DESK_API_KEY" config:"hide"`
 	CoinDeskRetryMaxAttempts int           `mapstructure:"COIN_DESK_RETRY_MAX_ATTEMPTS"`
 	CoinDeskClientTimeout    time.Duration `mapstructure:"COIN_DESK_CLIENT_TIMEOUT"`
 	CoinDeskRetryTimeout     time.Duration `mapstructure:"COIN_DESK_RETRY_TIMEOUT"`
@@ -36,10 +106,203 @@ type Config struct {
 	// Pulling configurations
 	PricesPullingInterval   time.Duration `mapstructure:"PRICES_PULLING_INTERVAL"`
 	PricesChannelBufferSize int           `mapstructure:"PRICES_CHANNEL_BUFFER_SIZE"`
+	PricesPullingEnabled    bool          `mapstructure:"PRICES_PULLING_ENABLED"`
+	// PricesFullQuotes pulls the bid, ask, 24h volume, high, low and change along with the prices
+	PricesFullQuotes bool `mapstructure:"PRICES_FULL_QUOTES"`
+
+	// Provider configurations: where the prices come from, and whether they are recorded
+	PricesProvider             string  `mapstructure:"PRICES_PROVIDER"`
+	PricesRecordPath           string  `mapstructure:"PRICES_RECORD_PATH"`
+	PricesReplayPath           string  `mapstructure:"PRICES_REPLAY_PATH"`
+	PricesReplaySpeed          float64 `mapstructure:"PRICES_REPLAY_SPEED"`
+	PricesReplayStepwise       bool    `mapstructure:"PRICES_REPLAY_STEPWISE"`
+	PricesReplayKeepTimestamps bool    `mapstructure:"PRICES_REPLAY_KEEP_TIMESTAMPS"`
+
+	// Simulation configurations, generating prices in place of the upstream
+	PricesSimulationModel           string        `mapstructure:"PRICES_SIMULATION_MODEL"`
+	PricesSimulationInterval        time.Duration `mapstructure:"PRICES_SIMULATION_INTERVAL"`
+	PricesSimulationSeed            uint64        `mapstructure:"PRICES_SIMULATION_SEED"`
+	PricesSimulationInitialPrice    float64       `mapstructure:"PRICES_SIMULATION_INITIAL_PRICE"`
+	PricesSimulationDrift           float64       `mapstructure:"PRICES_SIMULATION_DRIFT"`
+	PricesSimulationVolatility      float64       `mapstructure:"PRICES_SIMULATION_VOLATILITY"`
+	PricesSimulationJumpProbability float64       `mapstructure:"PRICES_SIMULATION_JUMP_PROBABILITY"`
+	PricesSimulationJumpSize        float64       `mapstructure:"PRICES_SIMULATION_JUMP_SIZE"`
+	PricesSimulationGapProbability  float64       `mapstructure:"PRICES_SIMULATION_GAP_PROBABILITY"`
+	PricesSimulationGapDuration     time.Duration `mapstructure:"PRICES_SIMULATION_GAP_DURATION"`
+
+	// Backfill configurations: seeding the history from the upstream on startup
+	BackfillEnabled  bool          `mapstructure:"BACKFILL_ENABLED"`
+	BackfillPeriod   time.Duration `mapstructure:"BACKFILL_PERIOD"`
+	BackfillInterval time.Duration `mapstructure:"BACKFILL_INTERVAL"`
+
+	// FX configurations: converting the prices to other currencies on request
+	FXEnabled         bool          `mapstructure:"FX_ENABLED"`
+	FXSource          string        `mapstructure:"FX_SOURCE"`
+	FXFilePath        string        `mapstructure:"FX_FILE_PATH"`
+	FXBaseCurrency    string        `mapstructure:"FX_BASE_CURRENCY"`
+	FXCurrencies      string        `mapstructure:"FX_CURRENCIES"`
+	FXRefreshInterval time.Duration `mapstructure:"FX_REFRESH_INTERVAL"`
+
+	// Analytics configurations: rolling indicators over the latest prices of each pair
+	AnalyticsEnabled bool   `mapstructure:"ANALYTICS_ENABLED"`
+	AnalyticsWindows string `mapstructure:"ANALYTICS_WINDOWS"`
+
+	// Fan-out configurations: how price updates reach the clients of every instance
+	PricesFanout        string `mapstructure:"PRICES_FANOUT"`
+	PricesFanoutChannel string `mapstructure:"PRICES_FANOUT_CHANNEL"`
+
+	// unknownKeys holds the keys found in the env files that do not map to any configuration field
+	unknownKeys []string
+}
+
+// UsesRedis tells whether any component is configured to use Redis.
+func (c Config) UsesRedis() bool {
+	return c.StoreType == StoreTypeRedis || c.PricesFanout == FanoutRedis ||
+		(c.StoreType == StoreTypeTiered && c.StoreTieredCold == StoreTypeRedis)
+}
+
+// PairsToMonitor parses PAIR_PRICE_TO_MONITOR, which accepts a comma separated list of pairs, e.g. BTCUSD,ETHUSD.
+func (c Config) PairsToMonitor() ([]domain.Pair, error) {
+	pairs, err := parsePairs(c.PairPriceToMonitor)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pairs) == 0 {
+		return nil, errors.New("at least one pair is required")
+	}
+
+	return pairs, nil
+}
+
+// PairsToDerive parses SYNTHETIC_PAIRS, the comma separated list of pairs derived from the monitored ones, e.g. ETHBTC.
+func (c Config) PairsToDerive() ([]domain.Pair, error) {
+	return parsePairs(c.SyntheticPairs)
+}
+
+// FXRates parses FX_BASE_CURRENCY and FX_CURRENCIES, the comma separated list of currencies the prices can be
+// converted to, e.g. EUR,GBP.
+func (c Config) FXRates() (domain.Currency, []domain.Currency, error) {
+	base, err := parseCurrency(c.FXBaseCurrency)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var currencies []domain.Currency
+	for _, value := range strings.Split(c.FXCurrencies, ",") {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+
+		currency, err := parseCurrency(value)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if currency != base && !slices.Contains(currencies, currency) {
+			currencies = append(currencies, currency)
+		}
+	}
+
+	return base, currencies, nil
 }
 
-func (c Config) PairToMonitor() (domain.Pair, error) {
-	return domain.NewPairFromString(c.PairPriceToMonitor)
+// AnalyticsWindowSizes parses ANALYTICS_WINDOWS, the comma separated list of the number of prices the indicators are
+// computed over, e.g. 20,50, skipping the repeated ones.
+func (c Config) AnalyticsWindowSizes() ([]int, error) {
+	var sizes []int
+
+	for _, value := range strings.Split(c.AnalyticsWindows, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.Errorf("invalid window %q, expected a number of prices", value)
+		}
+		if size < MinAnalyticsWindow || size > MaxAnalyticsWindow {
+			return nil, errors.Errorf("invalid window %d, expected between %d and %d prices",
+				size, MinAnalyticsWindow, MaxAnalyticsWindow)
+		}
+
+		if !slices.Contains(sizes, size) {
+			sizes = append(sizes, size)
+		}
+	}
+
+	if len(sizes) == 0 {
+		return nil, errors.New("at least one window is required")
+	}
+
+	return sizes, nil
+}
+
+// parseCurrency parses the code of a fiat currency of the registry, case-insensitively.
+func parseCurrency(value string) (domain.Currency, error) {
+	code := domain.Currency(strings.ToUpper(strings.TrimSpace(value)))
+
+	currency, ok := domain.DefaultCurrencies.Lookup(code)
+	if !ok {
+		return "", errors.Errorf("unknown currency %q", value)
+	}
+	if currency.Kind != domain.Fiat {
+		return "", errors.Errorf("%s is not a fiat currency", code)
+	}
+
+	return code, nil
+}
+
+// parsePairs parses a comma separated list of pairs, skipping the repeated ones.
+func parsePairs(list string) ([]domain.Pair, error) {
+	var (
+		pairs = make([]domain.Pair, 0)
+		seen  = make(map[domain.Pair]struct{})
+	)
+
+	for _, value := range strings.Split(list, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		pair, err := domain.NewPairFromString(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, exists := seen[pair]; exists {
+			continue
+		}
+
+		seen[pair] = struct{}{}
+		pairs = append(pairs, pair)
+	}
+
+	return pairs, nil
+}
+
+// SlogLevel maps LOG_LEVEL to a slog.Level, defaulting to info.
+func (c Config) SlogLevel() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// Override sets a configuration value with higher priority than env files and environment variables,
+// e.g. from command line flags. Overrides are kept across reloads.
+func Override(key, value string) error {
+	for _, known := range knownKeys() {
+		if known == key {
+			viper.Set(key, value)
+			return nil
+		}
+	}
+
+	return errors.Errorf("unknown configuration key: %s", key)
 }
 
 func Load(filenames ...string) (*Config, error) {
@@ -50,6 +313,15 @@ func Load(filenames ...string) (*Config, error) {
 	viper.SetConfigType("env")
 	viper.AutomaticEnv()
 
+	// binding every known key lets environment variables be used even when the key is missing from the env files
+	for _, key := range knownKeys() {
+		if err := viper.BindEnv(key); err != nil {
+			return nil, errors.Wrapf(err, "error to bind env, key: %s", key)
+		}
+	}
+
+	var fileKeys = map[string]struct{}{}
+
 	for _, filename := range filenames {
 		if _, err := os.Stat(filename); err != nil {
 			continue
@@ -68,7 +340,18 @@ func Load(filenames ...string) (*Config, error) {
 		if err := viper.Unmarshal(&cfg); err != nil {
 			return nil, errors.Wrapf(err, "error to unmarshal config, filename: %s", filename)
 		}
+
+		for _, key := range viper.AllKeys() {
+			fileKeys[key] = struct{}{}
+		}
 	}
 
+	// environment variables alone are enough to configure the application, even without env files
+	if err := viper.Unmarshal(&cfg); err != nil {
+		return nil, errors.Wrap(err, "error to unmarshal config")
+	}
+
+	cfg.unknownKeys = unknownKeys(fileKeys)
+
 	return cfg, nil
 }
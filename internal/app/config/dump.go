@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+)
+
+// Change describes a configuration key whose value differs between two configurations.
+// Values of hidden fields are masked.
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+type field struct {
+	key   string
+	value string // masked value, safe to print
+	raw   string
+}
+
+// Dump writes the effective configuration as KEY=value lines, masking the fields tagged with `config:"hide"`.
+func (c Config) Dump(w io.Writer) error {
+	for _, f := range c.fields() {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", f.key, f.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Diff returns the keys whose values differ from the current configuration to the next one, in declaration order.
+func Diff(current, next *Config) []Change {
+	var (
+		changes       []Change
+		currentFields = current.fields()
+		nextFields    = next.fields()
+	)
+
+	for i := range currentFields {
+		if currentFields[i].raw != nextFields[i].raw {
+			changes = append(changes, Change{
+				Key: currentFields[i].key,
+				Old: currentFields[i].value,
+				New: nextFields[i].value,
+			})
+		}
+	}
+
+	return changes
+}
+
+// fields returns the printable value of every configuration key, with secrets masked.
+func (c Config) fields() []field {
+	var (
+		v      = reflect.ValueOf(c)
+		t      = v.Type()
+		fields = make([]field, 0, t.NumField())
+	)
+
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+
+		key := structField.Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+
+		raw := fmt.Sprintf("%v", v.Field(i).Interface())
+		value := raw
+
+		switch {
+		case structField.Tag.Get("config") == hide && value != "":
+			value = maskedValue
+		case structField.Type.Kind() == reflect.String:
+			value = maskURLCredentials(value)
+		}
+
+		fields = append(fields, field{key: key, value: value, raw: raw})
+	}
+
+	return fields
+}
+
+func maskURLCredentials(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return value
+	}
+
+	if u.User != nil {
+		u.User = url.User(maskedValue)
+	}
+
+	// upstream APIs accept credentials as query parameters
+	query := u.Query()
+	for _, param := range []string{"api_key", "apikey", "token"} {
+		if query.Has(param) {
+			query.Set(param, maskedValue)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
@@ -0,0 +1,384 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// suggestionDistanceRatio bounds the edit distance, relative to the key length, for an unknown key
+// to be reported as a misspelling of a known one
+const suggestionDistanceRatio = 5
+
+// backfillMaxCandles is the number of candles the upstream returns at most per history request
+const backfillMaxCandles = 2000
+
+// ValidationError reports every configuration problem found, so that all of them can be fixed at once.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration (%d problems):\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// Validate checks required fields, ranges, formats and unknown keys, returning a ValidationError listing all problems found.
+// The settings only the components they configure can check, like the downsampling policy, are checked when wiring them.
+func (c Config) Validate() error {
+	v := &validator{}
+
+	v.required("ENV", c.Environment)
+	v.oneOf("LOG_LEVEL", strings.ToLower(c.LogLevel), []string{"debug", "info", "warn", "error"})
+	v.address("REST_API_PORT", c.RestAPIPort)
+
+	monitored, err := c.PairsToMonitor()
+	if err != nil {
+		v.addf("PAIR_PRICE_TO_MONITOR: %s", err.Error())
+	}
+
+	if derived, err := c.PairsToDerive(); err != nil {
+		v.addf("SYNTHETIC_PAIRS: %s", err.Error())
+	} else if monitored != nil {
+		for _, pair := range derived {
+			if slices.Contains(monitored, pair) {
+				v.addf("SYNTHETIC_PAIRS: %s is monitored, it can't be derived", pair)
+			}
+		}
+	}
+
+	v.positiveInt("STORE_MAX_ITEMS", c.StoreMaxItems)
+	v.positiveInt("SSE_CLIENTS_BUFFER_SIZE", c.SseClientsBufferSize)
+	v.positiveDuration("SSE_CLIENTS_CLEAN_UP_INTERVAL", c.SSEClientsCleanUpInterval)
+
+	v.oneOf("STORE_TYPE", c.StoreType, []string{StoreTypeRingBuffer, StoreTypeSlice, StoreTypeWAL, StoreTypeSQLite, StoreTypeRedis, StoreTypeTiered, StoreTypeDownsample, StoreTypeCompressed})
+
+	coldStoreType := ""
+	if c.StoreType == StoreTypeTiered {
+		v.oneOf("STORE_TIERED_COLD", c.StoreTieredCold, []string{StoreTypeSQLite, StoreTypeRedis})
+		coldStoreType = c.StoreTieredCold
+	}
+
+	if c.StoreType == StoreTypeWAL {
+		v.required("STORE_WAL_DIR", c.StoreWALDir)
+		v.oneOf("STORE_WAL_FSYNC_POLICY", c.StoreWALFsyncPolicy, []string{"always", "interval", "never"})
+		if c.StoreWALFsyncPolicy == "interval" {
+			v.positiveDuration("STORE_WAL_FSYNC_INTERVAL", c.StoreWALFsyncInterval)
+		}
+		v.positiveInt64("STORE_WAL_SEGMENT_MAX_BYTES", c.StoreWALSegmentMaxBytes)
+		v.nonNegativeDuration("STORE_WAL_SEGMENT_MAX_AGE", c.StoreWALSegmentMaxAge)
+		v.nonNegativeDuration("STORE_WAL_RETENTION", c.StoreWALRetention)
+		v.nonNegativeInt("STORE_WAL_MAX_SEGMENTS", c.StoreWALMaxSegments)
+	}
+
+	if c.StoreType == StoreTypeSQLite || coldStoreType == StoreTypeSQLite {
+		v.required("STORE_SQLITE_PATH", c.StoreSQLitePath)
+		v.nonNegativeDuration("STORE_SQLITE_RETENTION", c.StoreSQLiteRetention)
+	}
+
+	if c.StoreType == StoreTypeRedis || coldStoreType == StoreTypeRedis {
+		v.nonNegativeDuration("STORE_REDIS_RETENTION", c.StoreRedisRetention)
+	}
+
+	if c.StoreType == StoreTypeDownsample {
+		v.required("STORE_DOWNSAMPLING_POLICY", c.StoreDownsamplingPolicy)
+		v.positiveDuration("STORE_DOWNSAMPLING_INTERVAL", c.StoreDownsamplingInterval)
+	}
+
+	if c.StoreType == StoreTypeCompressed {
+		v.positiveInt("STORE_COMPRESSED_BLOCK_SIZE", c.StoreCompressedBlockSize)
+	}
+
+	if c.SnapshotRestoreOnStartup || c.AdminAPIToken != "" {
+		v.required("SNAPSHOT_PATH", c.SnapshotPath)
+	}
+
+	if c.FXEnabled {
+		v.oneOf("FX_SOURCE", c.FXSource, []string{FXSourceCoinDesk, FXSourceFile})
+		if c.FXSource == FXSourceFile {
+			v.required("FX_FILE_PATH", c.FXFilePath)
+		}
+		if _, err := parseCurrency(c.FXBaseCurrency); err != nil {
+			v.addf("FX_BASE_CURRENCY: %s", err.Error())
+		} else if _, currencies, err := c.FXRates(); err != nil {
+			v.addf("FX_CURRENCIES: %s", err.Error())
+		} else if len(currencies) == 0 {
+			v.addf("FX_CURRENCIES: at least one currency other than FX_BASE_CURRENCY is required")
+		}
+		v.positiveDuration("FX_REFRESH_INTERVAL", c.FXRefreshInterval)
+	}
+
+	if c.AnalyticsEnabled {
+		if _, err := c.AnalyticsWindowSizes(); err != nil {
+			v.addf("ANALYTICS_WINDOWS: %s", err.Error())
+		}
+	}
+
+	v.oneOf("PRICES_FANOUT", c.PricesFanout, []string{FanoutLocal, FanoutRedis})
+
+	if c.UsesRedis() {
+		v.redisURL("REDIS_URL", c.RedisURL)
+	}
+
+	v.httpURL("COIN_DESK_API_URL", c.CoinDeskAPIURL)
+	v.positiveInt("COIN_DESK_RETRY_MAX_ATTEMPTS", c.CoinDeskRetryMaxAttempts)
+	v.positiveDuration("COIN_DESK_CLIENT_TIMEOUT", c.CoinDeskClientTimeout)
+	v.nonNegativeDuration("COIN_DESK_RETRY_TIMEOUT", c.CoinDeskRetryTimeout)
+	v.positiveDuration("COIN_DESK_RETRY_INITIAL_WAIT", c.CoinDeskRetryInitialWait)
+	v.positiveDuration("COIN_DESK_RETRY_MAX_WAIT", c.CoinDeskRetryMaxWait)
+
+	if c.CoinDeskRetryMaxWait < c.CoinDeskRetryInitialWait {
+		v.addf("COIN_DESK_RETRY_MAX_WAIT: must be greater than or equal to COIN_DESK_RETRY_INITIAL_WAIT (%s)", c.CoinDeskRetryInitialWait)
+	}
+
+	if c.PricesFullQuotes {
+		v.httpURL("COIN_DESK_FULL_API_URL", c.CoinDeskFullAPIURL)
+	}
+
+	if c.BackfillEnabled {
+		v.httpURL("COIN_DESK_HISTORY_API_URL", c.CoinDeskHistoryAPIURL)
+		v.positiveDuration("BACKFILL_PERIOD", c.BackfillPeriod)
+
+		if c.BackfillInterval != time.Minute && c.BackfillInterval != time.Hour {
+			v.addf("BACKFILL_INTERVAL: must be 1m or 1h, got %s", c.BackfillInterval)
+		} else if candles := c.BackfillPeriod / c.BackfillInterval; candles > backfillMaxCandles {
+			v.addf("BACKFILL_PERIOD: must cover at most %d intervals of %s, got %d", backfillMaxCandles, c.BackfillInterval, candles)
+		}
+	}
+
+	v.oneOf("PRICES_PROVIDER", c.PricesProvider, []string{ProviderCoinDesk, ProviderReplay, ProviderSimulated})
+
+	if c.PricesProvider == ProviderReplay {
+		v.required("PRICES_REPLAY_PATH", c.PricesReplayPath)
+		if !c.PricesReplayStepwise && c.PricesReplaySpeed <= 0 {
+			v.addf("PRICES_REPLAY_SPEED: must be greater than zero, got %g", c.PricesReplaySpeed)
+		}
+		if c.PricesRecordPath != "" && c.PricesRecordPath == c.PricesReplayPath {
+			v.addf("PRICES_RECORD_PATH: must differ from PRICES_REPLAY_PATH")
+		}
+	}
+
+	if c.PricesProvider == ProviderSimulated {
+		v.oneOf("PRICES_SIMULATION_MODEL", c.PricesSimulationModel, []string{"gbm", "jumps", "flat", "gaps"})
+		v.positiveDuration("PRICES_SIMULATION_INTERVAL", c.PricesSimulationInterval)
+		if c.PricesSimulationInitialPrice <= 0 {
+			v.addf("PRICES_SIMULATION_INITIAL_PRICE: must be greater than zero, got %g", c.PricesSimulationInitialPrice)
+		}
+		if c.PricesSimulationVolatility < 0 {
+			v.addf("PRICES_SIMULATION_VOLATILITY: must not be negative, got %g", c.PricesSimulationVolatility)
+		}
+		v.probability("PRICES_SIMULATION_JUMP_PROBABILITY", c.PricesSimulationJumpProbability)
+		v.probability("PRICES_SIMULATION_GAP_PROBABILITY", c.PricesSimulationGapProbability)
+		if c.PricesSimulationJumpSize < 0 || c.PricesSimulationJumpSize >= 1 {
+			v.addf("PRICES_SIMULATION_JUMP_SIZE: must be between 0 and 1 (excluded), got %g", c.PricesSimulationJumpSize)
+		}
+		v.nonNegativeDuration("PRICES_SIMULATION_GAP_DURATION", c.PricesSimulationGapDuration)
+	}
+
+	v.positiveDuration("PRICES_PULLING_INTERVAL", c.PricesPullingInterval)
+	v.nonNegativeInt("PRICES_CHANNEL_BUFFER_SIZE", c.PricesChannelBufferSize)
+
+	for _, key := range c.unknownKeys {
+		if suggestion := closestKey(key); suggestion != "" {
+			v.addf("%s: unknown configuration key, did you mean %s?", key, suggestion)
+			continue
+		}
+		v.addf("%s: unknown configuration key", key)
+	}
+
+	if len(v.problems) > 0 {
+		return ValidationError{Problems: v.problems}
+	}
+
+	return nil
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf("%s: is required", key)
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed []string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.addf("%s: must be one of [%s], got %q", key, strings.Join(allowed, ", "), value)
+}
+
+func (v *validator) positiveInt(key string, value int) {
+	if value <= 0 {
+		v.addf("%s: must be greater than zero, got %d", key, value)
+	}
+}
+
+func (v *validator) positiveInt64(key string, value int64) {
+	if value <= 0 {
+		v.addf("%s: must be greater than zero, got %d", key, value)
+	}
+}
+
+func (v *validator) nonNegativeInt(key string, value int) {
+	if value < 0 {
+		v.addf("%s: must not be negative, got %d", key, value)
+	}
+}
+
+func (v *validator) positiveDuration(key string, value time.Duration) {
+	if value <= 0 {
+		v.addf("%s: must be a duration greater than zero, got %s", key, value)
+	}
+}
+
+func (v *validator) nonNegativeDuration(key string, value time.Duration) {
+	if value < 0 {
+		v.addf("%s: must not be a negative duration, got %s", key, value)
+	}
+}
+
+func (v *validator) probability(key string, value float64) {
+	if value < 0 || value > 1 {
+		v.addf("%s: must be between 0 and 1, got %g", key, value)
+	}
+}
+
+func (v *validator) address(key, value string) {
+	if value == "" {
+		v.addf("%s: is required", key)
+		return
+	}
+
+	_, port, err := net.SplitHostPort(value)
+	if err != nil {
+		v.addf("%s: must be in the [host]:port format, got %q", key, value)
+		return
+	}
+
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		v.addf("%s: invalid port %q", key, port)
+	}
+}
+
+func (v *validator) httpURL(key, value string) {
+	if value == "" {
+		v.addf("%s: is required", key)
+		return
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.addf("%s: must be an absolute http(s) URL, got %q", key, value)
+	}
+}
+
+// redisURL doesn't print the value on failure, as it may hold credentials.
+func (v *validator) redisURL(key, value string) {
+	if value == "" {
+		v.addf("%s: is required", key)
+		return
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+		v.addf("%s: must be an absolute redis(s) URL, e.g. redis://localhost:6379/0", key)
+	}
+}
+
+// knownKeys returns the configuration keys declared through the mapstructure tags of Config.
+func knownKeys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("mapstructure"); key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// unknownKeys returns, sorted and upper-cased, the given keys that are not declared by Config.
+func unknownKeys(keys map[string]struct{}) []string {
+	known := make(map[string]struct{})
+	for _, key := range knownKeys() {
+		known[strings.ToUpper(key)] = struct{}{}
+	}
+
+	var unknown []string
+	for key := range keys {
+		key = strings.ToUpper(key)
+		if _, ok := known[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+
+	sort.Strings(unknown)
+
+	return unknown
+}
+
+// closestKey returns the known key the given one is most likely a misspelling of, or empty if none is close enough.
+// Keys with the same words in a different order (e.g. COIN_DESK_MAX_RETRY_ATTEMPTS) are considered a match.
+func closestKey(key string) string {
+	var (
+		best         string
+		bestDistance = max(1, len(key)/suggestionDistanceRatio) + 1
+	)
+
+	for _, candidate := range knownKeys() {
+		if sortedWords(key) == sortedWords(candidate) {
+			return candidate
+		}
+
+		if d := levenshtein(key, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	return best
+}
+
+func sortedWords(key string) string {
+	words := strings.Split(key, "_")
+	sort.Strings(words)
+	return strings.Join(words, "_")
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
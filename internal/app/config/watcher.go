@@ -0,0 +1,116 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+)
+
+// reloadDebounce groups the burst of file system events editors produce when saving a file
+const reloadDebounce = 500 * time.Millisecond
+
+// Watcher reloads the configuration whenever one of its env files changes or the process receives a SIGHUP.
+type Watcher struct {
+	log       *slog.Logger
+	filenames []string
+}
+
+func NewWatcher(filenames ...string) *Watcher {
+	return &Watcher{
+		log:       slog.Default(),
+		filenames: filenames,
+	}
+}
+
+// Watch blocks until the context is done, calling onReload with every new valid configuration.
+// Invalid configurations are logged and discarded, so the running configuration is kept.
+func (w *Watcher) Watch(ctx context.Context, onReload func(cfg *Config)) error {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "failed to create file watcher")
+	}
+	defer func() {
+		_ = fsWatcher.Close()
+	}()
+
+	// directories are watched instead of files, as editors usually replace files instead of writing into them
+	watched := make(map[string]struct{})
+	filenames := append(append([]string{}, w.filenames...), mainEnvFile)
+
+	for _, filename := range filenames {
+		absPath, err := filepath.Abs(filename)
+		if err != nil {
+			return errors.Wrapf(err, "failed to resolve config file path, filename: %s", filename)
+		}
+
+		watched[absPath] = struct{}{}
+
+		if err := fsWatcher.Add(filepath.Dir(absPath)); err != nil {
+			w.log.Warn("Unable to watch config directory", "dir", filepath.Dir(absPath), "error", err.Error())
+		}
+	}
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	debounce := time.NewTimer(reloadDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if _, exists := watched[filepath.Clean(event.Name)]; !exists {
+				continue
+			}
+
+			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) {
+				debounce.Reset(reloadDebounce)
+			}
+
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.log.Error("Config file watcher error", "error", err.Error())
+
+		case <-sighup:
+			w.log.Info("SIGHUP received, reloading configuration")
+			w.reload(onReload)
+
+		case <-debounce.C:
+			w.log.Info("Config file changed, reloading configuration")
+			w.reload(onReload)
+		}
+	}
+}
+
+func (w *Watcher) reload(onReload func(cfg *Config)) {
+	cfg, err := Load(w.filenames...)
+	if err != nil {
+		w.log.Error("Failed to reload configuration, keeping the running one", "error", err.Error())
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		w.log.Error("Reloaded configuration is invalid, keeping the running one", "error", err.Error())
+		return
+	}
+
+	onReload(cfg)
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_Watch(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.env")
+	writeEnvFile := func(content string) {
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+	}
+
+	valid := validConfig()
+	var validContent string
+	for _, f := range valid.fields() {
+		validContent += f.key + "=" + f.raw + "\n"
+	}
+
+	writeEnvFile(validContent)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan *Config, 1)
+	go func() {
+		_ = NewWatcher(filename).Watch(ctx, func(cfg *Config) {
+			reloaded <- cfg
+		})
+	}()
+
+	time.Sleep(100 * time.Millisecond) // give time for the watcher to start
+
+	t.Run("Invalid configuration is not reloaded", func(t *testing.T) {
+		writeEnvFile(validContent + "STORE_MAX_ITEMS=0\n")
+
+		select {
+		case <-reloaded:
+			t.Fatal("Invalid configuration should not be reloaded")
+		case <-time.After(2 * reloadDebounce):
+		}
+	})
+
+	t.Run("Valid configuration is reloaded", func(t *testing.T) {
+		writeEnvFile(validContent + "STORE_MAX_ITEMS=42\n")
+
+		select {
+		case cfg := <-reloaded:
+			require.Equal(t, 42, cfg.StoreMaxItems)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected configuration to be reloaded")
+		}
+	})
+}
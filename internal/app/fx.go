@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/api/http_handlers"
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/fx"
+)
+
+// newPriceConverter returns the converter of the prices to other currencies, refreshing its rates until ctx is done,
+// or nil when the conversions are disabled.
+func newPriceConverter(
+	ctx context.Context,
+	cfg *config.Config,
+	priceAPI fx.PriceAPI,
+	clk clock.Clock,
+) (http_handlers.PriceConverter, error) {
+	if !cfg.FXEnabled {
+		return nil, nil
+	}
+
+	base, currencies, err := cfg.FXRates()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse FX currencies configuration")
+	}
+
+	var source fx.Source
+	switch cfg.FXSource {
+	case config.FXSourceFile:
+		source = fx.NewFileSource(cfg.FXFilePath)
+	default:
+		source = fx.NewPriceAPISource(priceAPI, clk)
+	}
+
+	converter := fx.NewConverter(source, base, currencies, cfg.FXRefreshInterval, clk)
+	converter.Start(ctx)
+
+	return converter, nil
+}
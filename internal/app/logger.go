@@ -0,0 +1,16 @@
+package app
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+)
+
+// NewLogger creates the application logger. Its level can be changed at runtime through the returned LevelVar.
+func NewLogger(cfg *config.Config) (*slog.Logger, *slog.LevelVar) {
+	level := new(slog.LevelVar)
+	level.Set(cfg.SlogLevel())
+
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level})), level
+}
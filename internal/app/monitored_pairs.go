@@ -0,0 +1,31 @@
+package app
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// monitoredPairs holds the pairs of PAIR_PRICE_TO_MONITOR, updated on reloads. Unlike the pairs poller, it is also
+// set on the instances that receive the prices from another one instead of polling them.
+type monitoredPairs struct {
+	mu    sync.RWMutex
+	pairs []domain.Pair
+}
+
+func newMonitoredPairs(pairs []domain.Pair) *monitoredPairs {
+	return &monitoredPairs{pairs: slices.Clone(pairs)}
+}
+
+func (m *monitoredPairs) Pairs() []domain.Pair {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.pairs)
+}
+
+func (m *monitoredPairs) set(pairs []domain.Pair) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pairs = slices.Clone(pairs)
+}
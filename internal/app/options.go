@@ -0,0 +1,55 @@
+package app
+
+import (
+	"net"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/coindesk"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+)
+
+// Option replaces a component the application otherwise builds from its configuration, e.g. in tests.
+type Option func(*components)
+
+type components struct {
+	httpClient    coindesk.HTTPClient
+	pricesRepo    sse.PricesRepository
+	eventProvider EventProvider
+	listener      net.Listener
+	clock         clock.Clock
+}
+
+// WithHTTPClient sets the HTTP client of the upstream API.
+func WithHTTPClient(client coindesk.HTTPClient) Option {
+	return func(c *components) {
+		c.httpClient = client
+	}
+}
+
+// WithPricesRepository sets the prices repository, in place of the configured store type.
+func WithPricesRepository(repo sse.PricesRepository) Option {
+	return func(c *components) {
+		c.pricesRepo = repo
+	}
+}
+
+// WithEventProvider sets the prices provider, in place of the configured one.
+func WithEventProvider(provider EventProvider) Option {
+	return func(c *components) {
+		c.eventProvider = provider
+	}
+}
+
+// WithListener serves the HTTP API on the given listener instead of REST_API_PORT, e.g. on an ephemeral port.
+func WithListener(listener net.Listener) Option {
+	return func(c *components) {
+		c.listener = listener
+	}
+}
+
+// WithClock sets the clock of the pulling, the retries and the clients cleanup, e.g. a clock.Fake in tests.
+func WithClock(clk clock.Clock) Option {
+	return func(c *components) {
+		c.clock = clk
+	}
+}
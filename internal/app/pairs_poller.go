@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+type EventProvider interface {
+	Start(ctx context.Context, pair domain.Pair) (<-chan domain.PriceUpdate, error)
+}
+
+// pairsPoller runs the event provider for a dynamic set of pairs, merging their updates into a single channel.
+type pairsPoller struct {
+	mu       sync.Mutex
+	ctx      context.Context
+	log      *slog.Logger
+	provider EventProvider
+	events   chan domain.PriceUpdate
+	cancels  map[domain.Pair]context.CancelFunc
+}
+
+func newPairsPoller(ctx context.Context, provider EventProvider, bufferSize int) *pairsPoller {
+	return &pairsPoller{
+		ctx:      ctx,
+		log:      slog.Default(),
+		provider: provider,
+		events:   make(chan domain.PriceUpdate, bufferSize),
+		cancels:  make(map[domain.Pair]context.CancelFunc),
+	}
+}
+
+func (p *pairsPoller) Events() <-chan domain.PriceUpdate {
+	return p.events
+}
+
+func (p *pairsPoller) Add(pair domain.Pair) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.cancels[pair]; exists {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(p.ctx)
+
+	updates, err := p.provider.Start(ctx, pair)
+	if err != nil {
+		cancel()
+		return errors.Wrapf(err, "failed to start event provider, pair: %s", pair)
+	}
+
+	p.cancels[pair] = cancel
+
+	go func() {
+		for update := range updates {
+			select {
+			case p.events <- update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	p.log.Info("Started polling pair", "pair", pair.String())
+
+	return nil
+}
+
+func (p *pairsPoller) Remove(pair domain.Pair) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cancel, exists := p.cancels[pair]
+	if !exists {
+		return
+	}
+
+	cancel()
+	delete(p.cancels, pair)
+
+	p.log.Info("Stopped polling pair", "pair", pair.String())
+}
+
+// Sync starts and stops polling so that exactly the given pairs are polled. The new pairs are started first, and if
+// one fails the ones started are stopped again, leaving the polled pairs unchanged.
+func (p *pairsPoller) Sync(pairs []domain.Pair) error {
+	wanted := make(map[domain.Pair]struct{}, len(pairs))
+	for _, pair := range pairs {
+		wanted[pair] = struct{}{}
+	}
+
+	var added []domain.Pair
+	for _, pair := range pairs {
+		if p.polls(pair) {
+			continue
+		}
+
+		if err := p.Add(pair); err != nil {
+			for _, started := range added {
+				p.Remove(started)
+			}
+			return err
+		}
+
+		added = append(added, pair)
+	}
+
+	for _, pair := range p.Pairs() {
+		if _, keep := wanted[pair]; !keep {
+			p.Remove(pair)
+		}
+	}
+
+	return nil
+}
+
+func (p *pairsPoller) polls(pair domain.Pair) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, exists := p.cancels[pair]
+	return exists
+}
+
+func (p *pairsPoller) Pairs() []domain.Pair {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pairs := make([]domain.Pair, 0, len(p.cancels))
+	for pair := range p.cancels {
+		pairs = append(pairs, pair)
+	}
+
+	return pairs
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+func TestPairsPoller_Sync(t *testing.T) {
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		btcEur = domain.NewPair(domain.BTC, domain.EUR)
+		ethUsd = domain.NewPair(domain.ETH, domain.USD)
+	)
+
+	sortedPairs := func(poller *pairsPoller) []domain.Pair {
+		pairs := poller.Pairs()
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].String() < pairs[j].String() })
+		return pairs
+	}
+
+	t.Run("Should poll exactly the given pairs", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		poller := newPairsPoller(ctx, &closableProvider{}, 10)
+
+		require.NoError(t, poller.Sync([]domain.Pair{btcUsd}))
+		require.NoError(t, poller.Sync([]domain.Pair{btcEur}))
+
+		assert.Equal(t, []domain.Pair{btcEur}, sortedPairs(poller))
+	})
+
+	t.Run("Should leave the polled pairs unchanged when a pair fails to start", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		provider := &closableProvider{}
+		poller := newPairsPoller(ctx, provider, 10)
+
+		require.NoError(t, poller.Sync([]domain.Pair{btcUsd}))
+
+		err := poller.Sync([]domain.Pair{btcEur, ethUsd})
+		require.Error(t, err)
+
+		assert.Equal(t, []domain.Pair{btcUsd}, sortedPairs(poller))
+
+		require.Len(t, provider.contexts, 2)
+		assert.NoError(t, provider.contexts[0].Err(), "Expected the polled pair to keep polling")
+		assert.Error(t, provider.contexts[1].Err(), "Expected the pair started to be stopped again")
+	})
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/api/http_handlers"
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/event_provider"
+)
+
+// intervalSetter is implemented by the providers whose polling interval can be changed while running.
+type intervalSetter interface {
+	SetInterval(interval time.Duration)
+}
+
+// upstreamAPI fetches the prices from the upstream, alone or along with their quotes.
+type upstreamAPI interface {
+	event_provider.PriceAPI
+	event_provider.QuoteAPI
+}
+
+// newEventProvider returns the configured provider, recording its prices when PRICES_RECORD_PATH is set. The
+// returned stepper is only set for stepwise replays.
+func newEventProvider(
+	cfg *config.Config,
+	priceAPI upstreamAPI,
+	clk clock.Clock,
+) (EventProvider, http_handlers.ReplayStepper, error) {
+	var (
+		provider EventProvider
+		stepper  http_handlers.ReplayStepper
+	)
+
+	switch cfg.PricesProvider {
+	case config.ProviderCoinDesk, "":
+		if cfg.PricesFullQuotes {
+			provider = event_provider.NewHTTPQuotePulling(priceAPI, cfg.PricesPullingInterval, cfg.PricesChannelBufferSize, clk)
+		} else {
+			provider = event_provider.NewHTTPPulling(priceAPI, cfg.PricesPullingInterval, cfg.PricesChannelBufferSize, clk)
+		}
+
+	case config.ProviderReplay:
+		replay, err := event_provider.NewReplayFromFile(cfg.PricesReplayPath, event_provider.ReplayOptions{
+			Speed:          cfg.PricesReplaySpeed,
+			Stepwise:       cfg.PricesReplayStepwise,
+			KeepTimestamps: cfg.PricesReplayKeepTimestamps,
+		}, cfg.PricesChannelBufferSize, clk)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to load prices recording")
+		}
+
+		provider = replay
+		if replay.Stepwise() {
+			stepper = replay
+		}
+
+	case config.ProviderSimulated:
+		model, err := event_provider.ParseSimulationModel(cfg.PricesSimulationModel)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		provider = event_provider.NewSimulated(event_provider.SimulationOptions{
+			Model:           model,
+			Interval:        cfg.PricesSimulationInterval,
+			Seed:            cfg.PricesSimulationSeed,
+			InitialPrice:    decimal.NewFromFloat(cfg.PricesSimulationInitialPrice),
+			Drift:           cfg.PricesSimulationDrift,
+			Volatility:      cfg.PricesSimulationVolatility,
+			JumpProbability: cfg.PricesSimulationJumpProbability,
+			JumpSize:        cfg.PricesSimulationJumpSize,
+			GapProbability:  cfg.PricesSimulationGapProbability,
+			GapDuration:     cfg.PricesSimulationGapDuration,
+		}, cfg.PricesChannelBufferSize, clk)
+
+	default:
+		return nil, nil, errors.Errorf("unknown prices provider: %s", cfg.PricesProvider)
+	}
+
+	if cfg.PricesRecordPath != "" {
+		recorder, err := event_provider.NewRecorder(provider, cfg.PricesRecordPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		provider = recorder
+	}
+
+	return provider, stepper, nil
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+)
+
+// restartRequiredReason explains, per configuration key, why a change cannot be applied while running.
+// Keys not listed here and not handled by Reload also require a restart.
+func restartRequiredReason(key string) string {
+	switch {
+	case key == "ENV":
+		return "the environment is fixed for the process lifetime"
+	case key == "REST_API_PORT":
+		return "the HTTP server is already listening on the current address"
+	case key == "PRICES_CHANNEL_BUFFER_SIZE":
+		return "event channels are allocated on startup"
+	case strings.HasPrefix(key, "STORE_"), key == "REDIS_URL":
+		return "the storage is opened on startup"
+	case strings.HasPrefix(key, "BACKFILL_"):
+		return "the history is only backfilled on startup"
+	case strings.HasPrefix(key, "COIN_DESK_"):
+		return "the CoinDesk client is created on startup"
+	default:
+		return "the setting is only read on startup"
+	}
+}
+
+// Reload applies the changes of a new configuration that are safe to apply while running.
+// Changes that need a restart are logged and ignored, so the running configuration keeps their current values.
+func (a *Application) Reload(next *config.Config) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// the watcher only validates the configuration itself, the components settings are checked here
+	if problems := componentsProblems(next); len(problems) > 0 {
+		a.log.Error("Reloaded configuration is invalid, keeping the running one", "error", config.ValidationError{Problems: problems}.Error())
+		return
+	}
+
+	changes := config.Diff(a.cfg, next)
+	if len(changes) == 0 {
+		a.log.Info("Configuration reloaded without changes")
+		return
+	}
+
+	applied := *a.cfg
+
+	for _, change := range changes {
+		log := a.log.With("key", change.Key, "old", change.Old, "new", change.New)
+
+		switch change.Key {
+		case "LOG_LEVEL":
+			a.logLevel.Set(next.SlogLevel())
+			applied.LogLevel = next.LogLevel
+
+		case "PAIR_PRICE_TO_MONITOR":
+			pairs, err := next.PairsToMonitor()
+			if err == nil && a.cfg.PricesPullingEnabled {
+				err = a.pairsPoller.Sync(pairs)
+			}
+			if err != nil {
+				log.Error("Failed to apply configuration change", "error", err.Error())
+				continue
+			}
+			a.monitoredPairs.set(pairs)
+			applied.PairPriceToMonitor = next.PairPriceToMonitor
+
+		case "PRICES_PULLING_INTERVAL":
+			provider, ok := a.eventProvider.(intervalSetter)
+			if !ok {
+				log.Warn("Configuration change requires a restart, ignoring it", "reason", "the prices provider has no pulling interval")
+				continue
+			}
+			provider.SetInterval(next.PricesPullingInterval)
+			applied.PricesPullingInterval = next.PricesPullingInterval
+
+		case "STORE_MAX_ITEMS":
+			repo, ok := a.pricesRepo.(resizableRepository)
+			if !ok {
+				log.Warn("Configuration change requires a restart, ignoring it", "reason", "the store type does not support resizing")
+				continue
+			}
+			repo.Resize(next.StoreMaxItems)
+			applied.StoreMaxItems = next.StoreMaxItems
+
+		case "SSE_CLIENTS_BUFFER_SIZE":
+			a.priceStreamer.SetClientsBufferSize(next.SseClientsBufferSize)
+			applied.SseClientsBufferSize = next.SseClientsBufferSize
+
+		case "SSE_CLIENTS_CLEAN_UP_INTERVAL":
+			a.clientsManager.SetCleanUpInterval(next.SSEClientsCleanUpInterval)
+			applied.SSEClientsCleanUpInterval = next.SSEClientsCleanUpInterval
+
+		default:
+			log.Warn("Configuration change requires a restart, ignoring it", "reason", restartRequiredReason(change.Key))
+			continue
+		}
+
+		log.Info("Configuration change applied")
+	}
+
+	// the running components keep a reference to the initial configuration, so it is replaced instead of mutated
+	a.cfg = &applied
+}
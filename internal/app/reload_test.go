@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+func TestApplication_Reload(t *testing.T) {
+	cfg := &config.Config{
+		LogLevel:                  "info",
+		RestAPIPort:               ":8080",
+		PairPriceToMonitor:        "BTCUSD",
+		StoreMaxItems:             10,
+		SseClientsBufferSize:      10,
+		SSEClientsCleanUpInterval: time.Minute,
+		CoinDeskAPIURL:            "http://localhost/data/price",
+		CoinDeskRetryMaxAttempts:  1,
+		CoinDeskClientTimeout:     time.Second,
+		PricesPullingInterval:     time.Hour,
+		PricesChannelBufferSize:   10,
+		PricesPullingEnabled:      true,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logLevel := new(slog.LevelVar)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	application, err := NewApplication(ctx, cfg, log, logLevel)
+	require.NoError(t, err)
+
+	next := *cfg
+	next.LogLevel = "debug"
+	next.PairPriceToMonitor = "ETHUSD,BTCUSD"
+	next.RestAPIPort = ":9090"
+	next.CoinDeskClientTimeout = 2 * time.Second
+
+	application.Reload(&next)
+
+	t.Run("Applies safe changes", func(t *testing.T) {
+		assert.Equal(t, slog.LevelDebug, logLevel.Level())
+		assert.Equal(t, "ETHUSD,BTCUSD", application.cfg.PairPriceToMonitor)
+
+		pairs := application.pairsPoller.Pairs()
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].String() < pairs[j].String() })
+		assert.Equal(t, []domain.Pair{
+			domain.NewPair(domain.BTC, domain.USD),
+			domain.NewPair(domain.ETH, domain.USD),
+		}, pairs)
+	})
+
+	t.Run("Rejects changes requiring a restart", func(t *testing.T) {
+		assert.Equal(t, ":8080", application.cfg.RestAPIPort)
+		assert.Equal(t, time.Second, application.cfg.CoinDeskClientTimeout)
+	})
+
+	t.Run("Removes pairs no longer monitored", func(t *testing.T) {
+		next := *application.cfg
+		next.PairPriceToMonitor = "ETHUSD"
+
+		application.Reload(&next)
+
+		assert.Equal(t, []domain.Pair{domain.NewPair(domain.ETH, domain.USD)}, application.pairsPoller.Pairs())
+		assert.Equal(t, []domain.Pair{domain.NewPair(domain.ETH, domain.USD)}, application.monitoredPairs.Pairs())
+	})
+
+	t.Run("Rejects configurations the components can't apply", func(t *testing.T) {
+		next := *application.cfg
+		next.LogLevel = "warn"
+		next.SyntheticPairs = "BTCEUR"
+
+		application.Reload(&next)
+
+		assert.Equal(t, slog.LevelDebug, logLevel.Level(), "Expected the whole configuration to be rejected")
+		assert.Equal(t, "debug", application.cfg.LogLevel)
+	})
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/snapshot"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+)
+
+// compactableRepository is implemented by the repositories downsampling their prices.
+type compactableRepository interface {
+	Compact(now time.Time)
+}
+
+// repositorySnapshotter writes the snapshots of the in-memory repositories, the persistent ones don't need them.
+type repositorySnapshotter struct {
+	path   string
+	source snapshot.Source
+}
+
+// newRepositorySnapshotter returns nil when the repository doesn't support snapshots.
+func newRepositorySnapshotter(path string, repo sse.PricesRepository) *repositorySnapshotter {
+	source, ok := repo.(snapshot.Source)
+	if !ok {
+		return nil
+	}
+
+	return &repositorySnapshotter{path: path, source: source}
+}
+
+func (s *repositorySnapshotter) WriteSnapshot() (string, snapshot.Stats, error) {
+	stats, err := snapshot.Write(s.path, s.source)
+	return s.path, stats, err
+}
+
+// restore loads the snapshot into the repository. A missing snapshot is not an error, e.g. on the first deploy.
+func (s *repositorySnapshotter) restore(log *slog.Logger, repo sse.PricesRepository) error {
+	if _, err := os.Stat(s.path); errors.Is(err, os.ErrNotExist) {
+		log.Warn("No snapshot to restore", "path", s.path)
+		return nil
+	}
+
+	stats, err := snapshot.Restore(s.path, repo)
+	if err != nil {
+		return errors.Wrap(err, "failed to restore snapshot")
+	}
+
+	// the restored averaged prices are held raw until compacted back to their tier
+	if compactable, ok := repo.(compactableRepository); ok {
+		compactable.Compact(time.Now())
+	}
+
+	log.Info("Snapshot restored", "path", s.path, "pairs", stats.Pairs, "updates", stats.Updates,
+		"skipped", stats.Skipped, "truncated", stats.Truncated)
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/in_memory"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/on_disk"
+)
+
+func TestRepositorySnapshotter(t *testing.T) {
+	var (
+		log    = slog.New(slog.NewTextHandler(io.Discard, nil))
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		path   = filepath.Join(t.TempDir(), "prices.snapshot")
+	)
+
+	t.Run("Should restore the snapshot of an in-memory repository", func(t *testing.T) {
+		repo := in_memory.NewPricesByRingBuffer(10)
+		repo.Store(domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(50000), ReceivedAt: time.Now().UTC()})
+
+		snapshotter := newRepositorySnapshotter(path, repo)
+		require.NotNil(t, snapshotter)
+
+		_, stats, err := snapshotter.WriteSnapshot()
+		require.NoError(t, err)
+		assert.Equal(t, 1, stats.Updates)
+
+		restored := in_memory.NewPricesByRingBuffer(10)
+		require.NoError(t, newRepositorySnapshotter(path, restored).restore(log, restored))
+		assert.Equal(t, repo.GetAll(btcUsd), restored.GetAll(btcUsd))
+	})
+
+	t.Run("Should ignore a missing snapshot", func(t *testing.T) {
+		repo := in_memory.NewPricesByRingBuffer(10)
+		snapshotter := newRepositorySnapshotter(filepath.Join(t.TempDir(), "missing.snapshot"), repo)
+
+		assert.NoError(t, snapshotter.restore(log, repo))
+	})
+
+	t.Run("Should not support persistent repositories", func(t *testing.T) {
+		repo, err := on_disk.NewPricesBySQLite(on_disk.SQLiteOptions{Path: filepath.Join(t.TempDir(), "prices.db")})
+		require.NoError(t, err)
+		defer func() {
+			_ = repo.Close()
+		}()
+
+		assert.Nil(t, newRepositorySnapshotter(path, repo))
+	})
+}
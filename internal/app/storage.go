@@ -0,0 +1,133 @@
+package app
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/compressed"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/downsampling"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/in_memory"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/in_redis"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/on_disk"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/tiered"
+)
+
+// resizableRepository is implemented by the repositories whose history size can be changed while running.
+type resizableRepository interface {
+	Resize(maxHistorySize int)
+}
+
+// newRedisClient returns nil when no component is configured to use Redis.
+func newRedisClient(cfg *config.Config) (*redis.Client, error) {
+	if !cfg.UsesRedis() {
+		return nil, nil
+	}
+
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse redis url")
+	}
+
+	return redis.NewClient(opts), nil
+}
+
+// OpenPersistentStore opens the persistent prices repository configured, for offline commands to read from it while
+// the server may be running. Stores only held by a server process, in memory or in its write-ahead log, are rejected.
+// The returned function closes the repository.
+func OpenPersistentStore(cfg *config.Config) (sse.PricesRepository, func(), error) {
+	switch cfg.StoreType {
+	case config.StoreTypeSQLite, config.StoreTypeRedis, config.StoreTypeTiered:
+	default:
+		return nil, nil, errors.Errorf("the %s store type is not persistent, expected sqlite, redis or tiered", cfg.StoreType)
+	}
+
+	redisClient, err := newRedisClient(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	repo, err := newPricesRepository(cfg, redisClient)
+	if err != nil {
+		if redisClient != nil {
+			_ = redisClient.Close()
+		}
+		return nil, nil, err
+	}
+
+	closeFn := func() {
+		if closer, ok := repo.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		if redisClient != nil {
+			_ = redisClient.Close()
+		}
+	}
+
+	return repo, closeFn, nil
+}
+
+func newPricesRepository(cfg *config.Config, redisClient *redis.Client) (sse.PricesRepository, error) {
+	switch cfg.StoreType {
+	case config.StoreTypeRingBuffer, "":
+		return in_memory.NewPricesByRingBuffer(cfg.StoreMaxItems), nil
+
+	case config.StoreTypeSlice:
+		return in_memory.NewPricesBySliceRepo(cfg.StoreMaxItems), nil
+
+	case config.StoreTypeWAL:
+		repo, err := on_disk.NewPricesByWAL(on_disk.WALOptions{
+			Dir:             cfg.StoreWALDir,
+			FsyncPolicy:     on_disk.FsyncPolicy(cfg.StoreWALFsyncPolicy),
+			FsyncInterval:   cfg.StoreWALFsyncInterval,
+			SegmentMaxBytes: cfg.StoreWALSegmentMaxBytes,
+			SegmentMaxAge:   cfg.StoreWALSegmentMaxAge,
+			Retention:       cfg.StoreWALRetention,
+			MaxSegments:     cfg.StoreWALMaxSegments,
+		}, cfg.StoreMaxItems)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open wal prices repository")
+		}
+		return repo, nil
+
+	case config.StoreTypeSQLite:
+		repo, err := on_disk.NewPricesBySQLite(on_disk.SQLiteOptions{
+			Path:      cfg.StoreSQLitePath,
+			Retention: cfg.StoreSQLiteRetention,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open sqlite prices repository")
+		}
+		return repo, nil
+
+	case config.StoreTypeRedis:
+		return in_redis.NewPricesBySortedSet(redisClient, cfg.StoreRedisKeyPrefix, cfg.StoreMaxItems, cfg.StoreRedisRetention), nil
+
+	case config.StoreTypeTiered:
+		coldCfg := *cfg
+		coldCfg.StoreType = cfg.StoreTieredCold
+		coldCfg.StoreMaxItems = 0 // the cold store is only bounded by its retention
+
+		cold, err := newPricesRepository(&coldCfg, redisClient)
+		if err != nil {
+			return nil, err
+		}
+		return tiered.NewPricesByTiers(in_memory.NewPricesByRingBuffer(cfg.StoreMaxItems), cold), nil
+
+	case config.StoreTypeDownsample:
+		policy, err := downsampling.ParsePolicy(cfg.StoreDownsamplingPolicy)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse downsampling policy")
+		}
+		return downsampling.NewPricesByResolution(policy, cfg.StoreDownsamplingInterval), nil
+
+	case config.StoreTypeCompressed:
+		return compressed.NewPricesByCompressedBlocks(cfg.StoreMaxItems, cfg.StoreCompressedBlockSize), nil
+
+	default:
+		return nil, errors.Errorf("unknown store type: %s", cfg.StoreType)
+	}
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/rates"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/downsampling"
+)
+
+// ValidateConfig validates the configuration along with the settings only the components it configures can check,
+// returning a config.ValidationError listing all problems found.
+func ValidateConfig(cfg *config.Config) error {
+	var problems []string
+
+	if err := cfg.Validate(); err != nil {
+		var validationErr config.ValidationError
+		if !errors.As(err, &validationErr) {
+			return err
+		}
+		problems = validationErr.Problems
+	}
+
+	problems = append(problems, componentsProblems(cfg)...)
+	if len(problems) > 0 {
+		return config.ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// componentsProblems checks the settings parsed by the components, skipping the ones config.Validate rejects.
+func componentsProblems(cfg *config.Config) []string {
+	var problems []string
+
+	monitored, monitoredErr := cfg.PairsToMonitor()
+	derived, derivedErr := cfg.PairsToDerive()
+	if monitoredErr == nil && derivedErr == nil {
+		for _, pair := range derived {
+			if !rates.CanDerive(monitored, pair) {
+				problems = append(problems, fmt.Sprintf("SYNTHETIC_PAIRS: %s can't be derived from the monitored pairs in up to %d legs", pair, rates.MaxLegs))
+			}
+		}
+	}
+
+	if cfg.StoreType == config.StoreTypeDownsample && cfg.StoreDownsamplingPolicy != "" {
+		if _, err := downsampling.ParsePolicy(cfg.StoreDownsamplingPolicy); err != nil {
+			problems = append(problems, "STORE_DOWNSAMPLING_POLICY: "+err.Error())
+		}
+	}
+
+	return problems
+}
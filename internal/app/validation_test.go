@@ -0,0 +1,77 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	validConfig := func() *config.Config {
+		return &config.Config{
+			Environment:               "development",
+			LogLevel:                  "info",
+			RestAPIPort:               ":8080",
+			PairPriceToMonitor:        "BTCUSD,ETHUSD",
+			StoreMaxItems:             1000,
+			StoreType:                 config.StoreTypeRingBuffer,
+			PricesFanout:              config.FanoutLocal,
+			PricesPullingEnabled:      true,
+			PricesProvider:            config.ProviderCoinDesk,
+			SseClientsBufferSize:      100,
+			SSEClientsCleanUpInterval: 30 * time.Second,
+			CoinDeskAPIURL:            "https://min-api.cryptocompare.com/data/price",
+			CoinDeskRetryMaxAttempts:  3,
+			CoinDeskClientTimeout:     3 * time.Second,
+			CoinDeskRetryTimeout:      100 * time.Millisecond,
+			CoinDeskRetryInitialWait:  100 * time.Millisecond,
+			CoinDeskRetryMaxWait:      time.Second,
+			PricesPullingInterval:     5 * time.Second,
+			PricesChannelBufferSize:   100,
+		}
+	}
+
+	t.Run("Accepts a valid configuration", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.SyntheticPairs = "ETHBTC,USD-BTC"
+		assert.NoError(t, ValidateConfig(cfg))
+	})
+
+	t.Run("Validates that the synthetic pairs can be derived", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.SyntheticPairs = "ETHBTC,BTCEUR"
+
+		assert.ErrorContains(t, ValidateConfig(cfg), "SYNTHETIC_PAIRS: BTCEUR can't be derived")
+	})
+
+	t.Run("Validates the downsampling policy", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.StoreType = config.StoreTypeDownsample
+		cfg.StoreDownsamplingPolicy = "1m:24h"
+		cfg.StoreDownsamplingInterval = time.Minute
+
+		assert.ErrorContains(t, ValidateConfig(cfg), "STORE_DOWNSAMPLING_POLICY: the first tier must keep the raw prices")
+
+		cfg.StoreDownsamplingPolicy = "raw:24h,1m:720h,1h:8760h"
+		assert.NoError(t, ValidateConfig(cfg))
+	})
+
+	t.Run("Lists the problems of the configuration and of the components together", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.SyntheticPairs = "BTCEUR"
+		cfg.StoreMaxItems = 0
+
+		err := ValidateConfig(cfg)
+
+		var validationErr config.ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.Len(t, validationErr.Problems, 2)
+		assert.Contains(t, err.Error(), "STORE_MAX_ITEMS")
+		assert.Contains(t, err.Error(), "SYNTHETIC_PAIRS: BTCEUR can't be derived")
+	})
+}
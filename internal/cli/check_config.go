@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app"
+)
+
+func newCheckConfigCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "check-config",
+		Short: "Validate the configuration and print its effective values, with secrets masked",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := opts.loadConfig()
+			if err != nil {
+				return err
+			}
+
+			if err = cfg.Dump(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+
+			if err = app.ValidateConfig(cfg); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "configuration is valid")
+
+			return nil
+		},
+	}
+}
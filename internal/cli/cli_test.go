@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+)
+
+func TestFormatUpdate(t *testing.T) {
+	update := sse.PriceStreamResponse{Pair: "BTCUSD", Price: "50500", ReceivedAt: "2025-01-01T10:00:00Z"}
+
+	tests := []struct {
+		name     string
+		previous decimal.Decimal
+		expected string
+	}{
+		{name: "first update", previous: decimal.Zero, expected: "2025-01-01T10:00:00Z  BTCUSD  50500"},
+		{name: "price up", previous: decimal.NewFromInt(50000), expected: "2025-01-01T10:00:00Z  BTCUSD  50500  ▲ +500 (+1.00%)"},
+		{name: "price down", previous: decimal.NewFromInt(51000), expected: "2025-01-01T10:00:00Z  BTCUSD  50500  ▼ -500 (-0.98%)"},
+		{name: "price unchanged", previous: decimal.NewFromInt(50500), expected: "2025-01-01T10:00:00Z  BTCUSD  50500  = 0 (0.00%)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatUpdate(update, tt.previous))
+		})
+	}
+}
+
+func TestExportOptions_TimeRange(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("Defaults to the whole history", func(t *testing.T) {
+		from, to, err := (&exportOptions{}).timeRange(now)
+		require.NoError(t, err)
+		assert.Equal(t, time.Unix(0, 0), from)
+		assert.Equal(t, now, to)
+	})
+
+	t.Run("Starts at the given period before now", func(t *testing.T) {
+		from, _, err := (&exportOptions{since: time.Hour}).timeRange(now)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(-time.Hour), from)
+	})
+
+	t.Run("Uses the given times", func(t *testing.T) {
+		from, to, err := (&exportOptions{since: time.Hour, from: "2024-12-31T00:00:00Z", to: "2025-01-01T00:00:00Z"}).timeRange(now)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-12-31T00:00:00Z", from.Format(time.RFC3339))
+		assert.Equal(t, "2025-01-01T00:00:00Z", to.Format(time.RFC3339))
+	})
+
+	t.Run("Rejects invalid ranges", func(t *testing.T) {
+		_, _, err := (&exportOptions{from: "yesterday"}).timeRange(now)
+		assert.Error(t, err)
+
+		_, _, err = (&exportOptions{from: "2025-01-02T00:00:00Z"}).timeRange(now)
+		assert.Error(t, err)
+	})
+}
+
+func TestExportCommand_FromServer(t *testing.T) {
+	var requested *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r
+		_, _ = w.Write([]byte("pair,price,received_at\nBTCUSD,50000.1,2025-01-01T10:00:00Z\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	var out bytes.Buffer
+
+	root := NewRootCommand("test")
+	root.SetOut(&out)
+	root.SetArgs([]string{"export", "--url", server.URL, "--pair", "BTCUSD", "--from", "2025-01-01T00:00:00Z", "--to", "2025-01-02T00:00:00Z"})
+
+	require.NoError(t, root.Execute())
+	require.NotNil(t, requested)
+	assert.Equal(t, "/prices/BTCUSD/export", requested.URL.Path)
+	assert.Equal(t, "1735689600", requested.URL.Query().Get("from"))
+	assert.Equal(t, "1735776000", requested.URL.Query().Get("to"))
+	assert.Equal(t, "csv", requested.URL.Query().Get("format"))
+	assert.Equal(t, "pair,price,received_at\nBTCUSD,50000.1,2025-01-01T10:00:00Z\n", out.String())
+}
+
+func TestRootCommand_ConfigFlags(t *testing.T) {
+	t.Cleanup(viper.Reset) // flag overrides are global, so they must not leak into other tests
+
+	var out bytes.Buffer
+
+	root := NewRootCommand("test")
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs([]string{"check-config", "--env-file", "", "--store-max-items", "7", "--pairs", "ETHUSD"})
+
+	_ = root.Execute() // the configuration is incomplete, only the flags are asserted
+
+	assert.Contains(t, out.String(), "STORE_MAX_ITEMS=7\n")
+	assert.Contains(t, out.String(), "PAIR_PRICE_TO_MONITOR=ETHUSD\n")
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/export"
+)
+
+type exportOptions struct {
+	url    string
+	store  bool
+	pair   string
+	since  time.Duration
+	from   string
+	to     string
+	format string
+	output string
+}
+
+func newExportCommand(rootOpts *options) *cobra.Command {
+	opts := &exportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Dump the price history of a running server, or of the configured persistent store, as CSV, NDJSON or Parquet",
+		Example: "  crypto-pricing-api export --pair BTCUSD --since 1h --format csv --output btcusd.csv\n" +
+			"  crypto-pricing-api export --store --pair BTCUSD --from 2025-01-01T00:00:00Z --format parquet -o btcusd.parquet",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runExport(cmd, rootOpts, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.url, "url", "http://localhost:8080", "base URL of the running server")
+	cmd.Flags().BoolVar(&opts.store, "store", false, "read from the configured sqlite, redis or tiered store instead of a running server")
+	cmd.Flags().StringVar(&opts.pair, "pair", "BTCUSD", "pair to export")
+	cmd.Flags().DurationVar(&opts.since, "since", 0, "only export prices received within the given period, e.g. 1h; ignored when --from is set")
+	cmd.Flags().StringVar(&opts.from, "from", "", "only export prices received from the given RFC3339 time")
+	cmd.Flags().StringVar(&opts.to, "to", "", "only export prices received before the given RFC3339 time; now when empty")
+	cmd.Flags().StringVar(&opts.format, "format", string(export.FormatCSV), "output format: csv, ndjson or parquet")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "output file; stdout when empty")
+
+	return cmd
+}
+
+func runExport(cmd *cobra.Command, rootOpts *options, opts *exportOptions) error {
+	pair, err := domain.NewPairFromString(opts.pair)
+	if err != nil {
+		return errors.Wrapf(err, "invalid pair %q", opts.pair)
+	}
+
+	format, err := export.ParseFormat(opts.format)
+	if err != nil {
+		return err
+	}
+
+	from, to, err := opts.timeRange(time.Now())
+	if err != nil {
+		return err
+	}
+
+	var out = cmd.OutOrStdout()
+	if opts.output != "" {
+		file, err := os.Create(opts.output)
+		if err != nil {
+			return errors.Wrap(err, "failed to create output file")
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		out = file
+	}
+
+	if opts.store {
+		return exportFromStore(cmd, rootOpts, pair, from, to, format, out)
+	}
+
+	return exportFromServer(cmd, opts.url, pair, from, to, format, out)
+}
+
+// timeRange returns the [from, to) range to export, from the unix epoch until now by default.
+func (o *exportOptions) timeRange(now time.Time) (time.Time, time.Time, error) {
+	from, to := time.Unix(0, 0), now
+
+	if o.since > 0 {
+		from = now.Add(-o.since)
+	}
+
+	if o.from != "" {
+		t, err := time.Parse(time.RFC3339, o.from)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.Wrapf(err, "invalid --from time %q", o.from)
+		}
+		from = t
+	}
+
+	if o.to != "" {
+		t, err := time.Parse(time.RFC3339, o.to)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.Wrapf(err, "invalid --to time %q", o.to)
+		}
+		to = t
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.Errorf("the export range start %s is not before its end %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	return from, to, nil
+}
+
+func exportFromStore(
+	cmd *cobra.Command,
+	rootOpts *options,
+	pair domain.Pair,
+	from, to time.Time,
+	format export.Format,
+	out io.Writer,
+) error {
+	cfg, err := rootOpts.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	repo, closeRepo, err := app.OpenPersistentStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+
+	source, ok := repo.(export.RangeSource)
+	if !ok {
+		return errors.Errorf("the %s store type does not support range queries", cfg.StoreType)
+	}
+
+	writer, err := export.NewWriter(format, out)
+	if err != nil {
+		return err
+	}
+
+	if _, err = export.Export(cmd.Context(), source, pair, from, to, writer); err != nil {
+		return errors.Wrap(err, "failed to export prices")
+	}
+
+	return writer.Close()
+}
+
+// exportFromServer streams the export of a running server, which applies the same formatting as exportFromStore.
+func exportFromServer(
+	cmd *cobra.Command,
+	baseURL string,
+	pair domain.Pair,
+	from, to time.Time,
+	format export.Format,
+	out io.Writer,
+) error {
+	query := url.Values{}
+	query.Set("from", strconv.FormatInt(from.Unix(), 10))
+	query.Set("to", strconv.FormatInt(to.Unix(), 10))
+	query.Set("format", string(format))
+
+	exportURL := strings.TrimSuffix(baseURL, "/") + "/prices/" + pair.String() + "/export?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, exportURL, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch export")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if _, err = io.Copy(out, resp.Body); err != nil {
+		return errors.Wrap(err, "failed to write export")
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/coindesk"
+)
+
+func newFetchCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:     "fetch <pair>",
+		Short:   "Query the current price of a pair from the upstream CoinDesk API",
+		Example: "  crypto-pricing-api fetch BTCUSD",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := opts.loadConfig()
+			if err != nil {
+				return err
+			}
+
+			pair, err := domain.NewPairFromString(args[0])
+			if err != nil {
+				return errors.Wrapf(err, "invalid pair %q", args[0])
+			}
+
+			priceAPI := coindesk.NewPricingAPI(&http.Client{Timeout: cfg.CoinDeskClientTimeout}, cfg, clock.New())
+
+			price, err := priceAPI.GetPrice(cmd.Context(), pair)
+			if err != nil {
+				return errors.Wrapf(err, "failed to fetch %s price", pair)
+			}
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\n", pair, price, time.Now().UTC().Format(time.RFC3339))
+			return err
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/loadtest"
+)
+
+func newLoadTestCommand() *cobra.Command {
+	var (
+		pairs []string
+		opts  loadtest.Options
+	)
+
+	cmd := &cobra.Command{
+		Use:     "loadtest <url>",
+		Short:   "Open concurrent price streams against a running server and report their latency, gaps and duplicates",
+		Example: "  crypto-pricing-api loadtest http://localhost:8080 --connections 10000 --ramp-up 30s --duration 2m",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.URL = args[0]
+
+			for _, value := range pairs {
+				pair, err := domain.NewPairFromString(value)
+				if err != nil {
+					return errors.Wrapf(err, "invalid pair %q", value)
+				}
+				opts.Pairs = append(opts.Pairs, pair)
+			}
+
+			ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Opening %d connections to %s over %s, for %s\n",
+				opts.Connections, opts.URL, opts.RampUp, opts.Duration)
+
+			report, err := loadtest.Run(ctx, &http.Client{}, opts)
+			if err != nil {
+				return err
+			}
+
+			if err = report.Write(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+
+			if report.Connected == 0 {
+				return errors.New("no connection could be opened")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&pairs, "pair", []string{"BTCUSD"}, "pairs to stream, spread evenly over the connections")
+	cmd.Flags().IntVar(&opts.Connections, "connections", 100, "number of concurrent streams")                        //nolint:mnd // default
+	cmd.Flags().DurationVar(&opts.RampUp, "ramp-up", 10*time.Second, "period over which the connections are opened") //nolint:mnd // default
+	cmd.Flags().DurationVar(&opts.Duration, "duration", time.Minute, "duration of the run, ramp-up included")
+
+	return cmd
+}
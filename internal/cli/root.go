@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+)
+
+// configFlag maps a command line flag to the configuration key it overrides.
+type configFlag struct {
+	name  string
+	key   string
+	usage string
+}
+
+func configFlags() []configFlag {
+	return []configFlag{
+		{name: "log-level", key: "LOG_LEVEL", usage: "log level: debug, info, warn or error"},
+		{name: "port", key: "REST_API_PORT", usage: "HTTP server address, e.g. :8080"},
+		{name: "pairs", key: "PAIR_PRICE_TO_MONITOR", usage: "comma separated pairs to monitor, e.g. BTCUSD,ETHUSD"},
+		{name: "store-max-items", key: "STORE_MAX_ITEMS", usage: "maximum number of prices stored per pair"},
+		{name: "pull-interval", key: "PRICES_PULLING_INTERVAL", usage: "interval between upstream price requests, e.g. 5s"},
+		{name: "coindesk-url", key: "COIN_DESK_API_URL", usage: "CoinDesk price endpoint URL"},
+		{name: "coindesk-api-key", key: "COIN_DESK_API_KEY", usage: "CoinDesk API key"},
+	}
+}
+
+// options holds the flags shared by every command.
+type options struct {
+	envFiles []string
+}
+
+// NewRootCommand builds the command line interface. Running it without a subcommand starts the server.
+func NewRootCommand(version string) *cobra.Command {
+	opts := &options{}
+
+	root := &cobra.Command{
+		Use:           "crypto-pricing-api",
+		Short:         "Real-time cryptocurrency prices streaming through Server-Sent Events",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			return applyConfigFlags(cmd.Flags())
+		},
+	}
+
+	root.PersistentFlags().StringSliceVar(
+		&opts.envFiles,
+		"env-file",
+		[]string{"./default.env", "./config/default.env"},
+		"env files to load, in increasing priority order; ./.env is always loaded last",
+	)
+
+	for _, f := range configFlags() {
+		root.PersistentFlags().String(f.name, "", f.usage+" (overrides "+f.key+")")
+	}
+
+	serve := newServeCommand(opts)
+
+	root.RunE = serve.RunE
+	root.AddCommand(
+		serve,
+		newCheckConfigCommand(opts),
+		newFetchCommand(opts),
+		newStreamCommand(),
+		newLoadTestCommand(),
+		newExportCommand(opts),
+		newVersionCommand(version),
+	)
+
+	return root
+}
+
+// applyConfigFlags overrides the configuration with the flags explicitly set by the user.
+func applyConfigFlags(flags *pflag.FlagSet) error {
+	for _, f := range configFlags() {
+		flag := flags.Lookup(f.name)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+
+		if err := config.Override(f.key, flag.Value.String()); err != nil {
+			return errors.Wrapf(err, "invalid flag --%s", f.name)
+		}
+	}
+
+	return nil
+}
+
+func (o *options) loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(o.envFiles...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load env configurations")
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"os/signal"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app"
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+)
+
+func newServeCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Start the HTTP server streaming prices (default command)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := opts.loadConfig()
+			if err != nil {
+				return err
+			}
+
+			if err = app.ValidateConfig(cfg); err != nil {
+				return err
+			}
+
+			return serve(cmd.Context(), cfg, opts.envFiles)
+		},
+	}
+}
+
+func serve(ctx context.Context, cfg *config.Config, envFiles []string) error {
+	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	log, logLevel := app.NewLogger(cfg)
+	slog.SetDefault(log)
+
+	log.Info("Initializing application")
+
+	application, err := app.NewApplication(ctx, cfg, log, logLevel)
+	if err != nil {
+		return errors.Wrap(err, "failed to create application")
+	}
+
+	go func() {
+		if err := config.NewWatcher(envFiles...).Watch(ctx, application.Reload); err != nil {
+			log.Error("Configuration hot-reload disabled", "error", err.Error())
+		}
+	}()
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- application.Run(ctx)
+	}()
+
+	select {
+	case <-ctx.Done(): // wait until we receive a signal to stop the app
+	case err = <-runErr:
+	}
+
+	log.Info("Shutting down application")
+
+	// the application is stopped even when it failed to run, releasing the stores and providers it opened
+	application.Stop()
+
+	if err != nil {
+		return errors.Wrap(err, "failed to run application")
+	}
+
+	log.Info("Exit application")
+
+	return nil
+}
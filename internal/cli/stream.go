@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+)
+
+func newStreamCommand() *cobra.Command {
+	var since time.Duration
+
+	cmd := &cobra.Command{
+		Use:     "stream <url> <pair>",
+		Short:   "Connect to a running server and pretty-print the price updates of a pair",
+		Example: "  crypto-pricing-api stream http://localhost:8080 BTCUSD --since 10m",
+		Args:    cobra.ExactArgs(2), //nolint:mnd // url and pair
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pair, err := domain.NewPairFromString(args[1])
+			if err != nil {
+				return errors.Wrapf(err, "invalid pair %q", args[1])
+			}
+
+			ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+
+			streamURL := strings.TrimSuffix(args[0], "/") + "/prices/" + pair.String() + "/stream"
+			if since > 0 {
+				streamURL += "?since=" + strconv.FormatInt(time.Now().Add(-since).Unix(), 10)
+			}
+
+			return stream(ctx, streamURL, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().DurationVar(&since, "since", 0, "replay the history of the given period before streaming, e.g. 10m")
+
+	return cmd
+}
+
+func stream(ctx context.Context, streamURL string, out io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil { // interrupted by the user
+			return nil
+		}
+		return errors.Wrap(err, "failed to connect to stream")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024)) //nolint:mnd // only the start of the error body is useful
+		return errors.Errorf("unexpected status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	_, _ = fmt.Fprintf(out, "Connected to %s\n", streamURL)
+
+	var previous decimal.Decimal
+
+	err = sse.ReadEvents(resp.Body, func(data []byte) error {
+		var update sse.PriceStreamResponse
+		if err := json.Unmarshal(data, &update); err != nil {
+			_, _ = fmt.Fprintf(out, "malformed event: %s\n", data)
+			return nil
+		}
+
+		_, err := fmt.Fprintln(out, formatUpdate(update, previous))
+
+		previous, _ = decimal.NewFromString(update.Price)
+
+		return err
+	})
+
+	if ctx.Err() != nil { // interrupted by the user
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return errors.New("stream closed by the server")
+}
+
+// formatUpdate renders an update with its variation from the previous price, e.g.
+// 2025-01-01T10:00:00Z  BTCUSD  50010.5  ▲ +10.5 (+0.02%)
+func formatUpdate(update sse.PriceStreamResponse, previous decimal.Decimal) string {
+	line := fmt.Sprintf("%s  %s  %s", update.ReceivedAt, update.Pair, update.Price)
+
+	price, err := decimal.NewFromString(update.Price)
+	if err != nil || previous.IsZero() {
+		return line
+	}
+
+	var (
+		change  = price.Sub(previous)
+		percent = change.Div(previous).Mul(decimal.NewFromInt(100)) //nolint:mnd // percentage
+		arrow   = "="
+		sign    = ""
+	)
+
+	switch change.Sign() {
+	case 1:
+		arrow, sign = "▲", "+"
+	case -1:
+		arrow = "▼"
+	}
+
+	return fmt.Sprintf("%s  %s %s%s (%s%s%%)", line, arrow, sign, change.String(), sign, percent.StringFixed(2)) //nolint:mnd // decimals
+}
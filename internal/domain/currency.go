@@ -1,9 +1,125 @@
 package domain
 
+import (
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
 type Currency string
 
 const (
-	USD Currency = "USD"
-	BTC Currency = "BTC"
-	ETH Currency = "ETH"
+	USD  Currency = "USD"
+	EUR  Currency = "EUR"
+	BTC  Currency = "BTC"
+	ETH  Currency = "ETH"
+	USDT Currency = "USDT"
 )
+
+type CurrencyKind string
+
+const (
+	Fiat   CurrencyKind = "fiat"
+	Crypto CurrencyKind = "crypto"
+)
+
+// CurrencyInfo describes a currency. Decimals is the number of decimals its amounts are expressed with: the minor
+// unit of a fiat currency, or the smallest unit of a crypto currency.
+type CurrencyInfo struct {
+	Code     Currency
+	Name     string
+	Decimals int32
+	Kind     CurrencyKind
+}
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z0-9]{2,10}$`)
+
+// CurrencyRegistry holds the known currencies, against which pairs are parsed and validated.
+type CurrencyRegistry struct {
+	currencies map[Currency]CurrencyInfo
+}
+
+func NewCurrencyRegistry(currencies ...CurrencyInfo) (*CurrencyRegistry, error) {
+	registry := &CurrencyRegistry{currencies: make(map[Currency]CurrencyInfo, len(currencies))}
+
+	for _, currency := range currencies {
+		if !currencyCodePattern.MatchString(string(currency.Code)) {
+			return nil, errors.Errorf("invalid currency code %q, expected 2 to 10 uppercase letters or digits", currency.Code)
+		}
+		if currency.Kind != Fiat && currency.Kind != Crypto {
+			return nil, errors.Errorf("invalid kind %q of currency %s", currency.Kind, currency.Code)
+		}
+		if currency.Decimals < 0 {
+			return nil, errors.Errorf("invalid decimals %d of currency %s", currency.Decimals, currency.Code)
+		}
+		if _, exists := registry.currencies[currency.Code]; exists {
+			return nil, errors.Errorf("currency %s registered twice", currency.Code)
+		}
+
+		registry.currencies[currency.Code] = currency
+	}
+
+	return registry, nil
+}
+
+// DefaultCurrencies is the registry of the currencies supported by the application.
+var DefaultCurrencies = mustNewCurrencyRegistry(
+	CurrencyInfo{Code: USD, Name: "US Dollar", Decimals: 2, Kind: Fiat},
+	CurrencyInfo{Code: EUR, Name: "Euro", Decimals: 2, Kind: Fiat},
+	CurrencyInfo{Code: "GBP", Name: "Pound Sterling", Decimals: 2, Kind: Fiat},
+	CurrencyInfo{Code: "JPY", Name: "Japanese Yen", Decimals: 0, Kind: Fiat},
+	CurrencyInfo{Code: "CHF", Name: "Swiss Franc", Decimals: 2, Kind: Fiat},
+	CurrencyInfo{Code: "CAD", Name: "Canadian Dollar", Decimals: 2, Kind: Fiat},
+	CurrencyInfo{Code: "AUD", Name: "Australian Dollar", Decimals: 2, Kind: Fiat},
+	CurrencyInfo{Code: "BRL", Name: "Brazilian Real", Decimals: 2, Kind: Fiat},
+	CurrencyInfo{Code: "CNY", Name: "Chinese Yuan", Decimals: 2, Kind: Fiat},
+	CurrencyInfo{Code: "KRW", Name: "South Korean Won", Decimals: 0, Kind: Fiat},
+	CurrencyInfo{Code: BTC, Name: "Bitcoin", Decimals: 8, Kind: Crypto},
+	CurrencyInfo{Code: ETH, Name: "Ethereum", Decimals: 18, Kind: Crypto},
+	CurrencyInfo{Code: USDT, Name: "Tether", Decimals: 6, Kind: Crypto},
+	CurrencyInfo{Code: "USDC", Name: "USD Coin", Decimals: 6, Kind: Crypto},
+	CurrencyInfo{Code: "DAI", Name: "Dai", Decimals: 18, Kind: Crypto},
+	CurrencyInfo{Code: "BNB", Name: "BNB", Decimals: 18, Kind: Crypto},
+	CurrencyInfo{Code: "SOL", Name: "Solana", Decimals: 9, Kind: Crypto},
+	CurrencyInfo{Code: "XRP", Name: "XRP", Decimals: 6, Kind: Crypto},
+	CurrencyInfo{Code: "ADA", Name: "Cardano", Decimals: 6, Kind: Crypto},
+	CurrencyInfo{Code: "DOGE", Name: "Dogecoin", Decimals: 8, Kind: Crypto},
+	CurrencyInfo{Code: "DOT", Name: "Polkadot", Decimals: 10, Kind: Crypto},
+	CurrencyInfo{Code: "TRX", Name: "TRON", Decimals: 6, Kind: Crypto},
+	CurrencyInfo{Code: "MATIC", Name: "Polygon", Decimals: 18, Kind: Crypto},
+	CurrencyInfo{Code: "AVAX", Name: "Avalanche", Decimals: 18, Kind: Crypto},
+	CurrencyInfo{Code: "LTC", Name: "Litecoin", Decimals: 8, Kind: Crypto},
+	CurrencyInfo{Code: "LINK", Name: "Chainlink", Decimals: 18, Kind: Crypto},
+	CurrencyInfo{Code: "UNI", Name: "Uniswap", Decimals: 18, Kind: Crypto},
+	CurrencyInfo{Code: "XLM", Name: "Stellar", Decimals: 7, Kind: Crypto},
+	CurrencyInfo{Code: "SHIB", Name: "Shiba Inu", Decimals: 18, Kind: Crypto},
+	CurrencyInfo{Code: "1INCH", Name: "1inch", Decimals: 18, Kind: Crypto},
+)
+
+func mustNewCurrencyRegistry(currencies ...CurrencyInfo) *CurrencyRegistry {
+	registry, err := NewCurrencyRegistry(currencies...)
+	if err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+func (r *CurrencyRegistry) Lookup(code Currency) (CurrencyInfo, bool) {
+	currency, ok := r.currencies[code]
+	return currency, ok
+}
+
+// All returns the registered currencies sorted by code.
+func (r *CurrencyRegistry) All() []CurrencyInfo {
+	currencies := make([]CurrencyInfo, 0, len(r.currencies))
+	for _, currency := range r.currencies {
+		currencies = append(currencies, currency)
+	}
+
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].Code < currencies[j].Code
+	})
+
+	return currencies
+}
@@ -0,0 +1,23 @@
+// Package domaintest provides fixtures shared by the tests of the domain consumers.
+package domaintest
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// NewPriceUpdates returns n updates of the pair received every interval from start, with increasing prices.
+func NewPriceUpdates(pair domain.Pair, n int, start time.Time, interval time.Duration) []domain.PriceUpdate {
+	updates := make([]domain.PriceUpdate, n)
+	for i := range updates {
+		updates[i] = domain.PriceUpdate{
+			Pair:       pair,
+			Price:      decimal.NewFromFloat(50000.5 + float64(i)),
+			ReceivedAt: start.Add(time.Duration(i) * interval).UTC(),
+		}
+	}
+	return updates
+}
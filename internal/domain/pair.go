@@ -1,26 +1,22 @@
 package domain
 
 import (
-	"github.com/pkg/errors"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
+// pairSeparators are accepted between the currencies of a pair, e.g. BTC-USDT or BTC/USDT.
+const pairSeparators = "-/_"
+
 type Pair struct {
 	From Currency
 	To   Currency
 }
 
+// NewPairFromString parses a pair of currencies of the DefaultCurrencies registry, e.g. BTCUSD, BTC-USDT or btc/usdt.
 func NewPairFromString(v string) (Pair, error) {
-	const wantLength = 6
-
-	if len(v) != wantLength {
-		return Pair{}, errors.New("invalid pair length, expected 6 characters")
-	}
-
-	return NewPair(
-		Currency(v[:3]),
-		Currency(v[3:]),
-	), nil
+	return DefaultCurrencies.ParsePair(v)
 }
 
 func NewPair(from, to Currency) Pair {
@@ -33,3 +29,50 @@ func NewPair(from, to Currency) Pair {
 func (p Pair) String() string {
 	return strings.ToUpper(string(p.From + p.To))
 }
+
+// ParsePair parses a pair of registered currencies, case-insensitively. The currencies are either separated by one
+// of -, / or _, or concatenated, in which case they are split where both sides are registered currencies.
+func (r *CurrencyRegistry) ParsePair(v string) (Pair, error) {
+	v = strings.ToUpper(strings.TrimSpace(v))
+
+	if i := strings.IndexAny(v, pairSeparators); i >= 0 {
+		from, to := v[:i], v[i+1:]
+		if from == "" || to == "" || strings.ContainsAny(to, pairSeparators) {
+			return Pair{}, errors.Errorf("invalid pair %q, expected two currencies", v)
+		}
+
+		for _, code := range []string{from, to} {
+			if _, ok := r.Lookup(Currency(code)); !ok {
+				return Pair{}, errors.Errorf("unknown currency %q in pair %q", code, v)
+			}
+		}
+
+		return r.newPair(Currency(from), Currency(to))
+	}
+
+	var candidates []Pair
+	for i := 1; i < len(v); i++ {
+		_, fromKnown := r.Lookup(Currency(v[:i]))
+		_, toKnown := r.Lookup(Currency(v[i:]))
+		if fromKnown && toKnown {
+			candidates = append(candidates, NewPair(Currency(v[:i]), Currency(v[i:])))
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return Pair{}, errors.Errorf("invalid pair %q, expected two known currencies", v)
+	case 1:
+		return r.newPair(candidates[0].From, candidates[0].To)
+	default:
+		return Pair{}, errors.Errorf("ambiguous pair %q, separate its currencies with a dash, e.g. %s-%s",
+			v, candidates[0].From, candidates[0].To)
+	}
+}
+
+func (r *CurrencyRegistry) newPair(from, to Currency) (Pair, error) {
+	if from == to {
+		return Pair{}, errors.Errorf("invalid pair %s%s, expected two different currencies", from, to)
+	}
+	return NewPair(from, to), nil
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPairFromString(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Pair
+		wantErr string
+	}{
+		{input: "BTCUSD", want: NewPair(BTC, USD)},
+		{input: "btcusd", want: NewPair(BTC, USD)},
+		{input: "BTCUSDT", want: NewPair(BTC, USDT)},
+		{input: "DOGEUSDT", want: NewPair("DOGE", USDT)},
+		{input: "MATICEUR", want: NewPair("MATIC", EUR)},
+		{input: "1INCHUSD", want: NewPair("1INCH", USD)},
+		{input: "BTC-USDT", want: NewPair(BTC, USDT)},
+		{input: "eth/btc", want: NewPair(ETH, BTC)},
+		{input: "ETH_EUR", want: NewPair(ETH, EUR)},
+		{input: "", wantErr: "expected two known currencies"},
+		{input: "BTC", wantErr: "expected two known currencies"},
+		{input: "XXXYYY", wantErr: "expected two known currencies"},
+		{input: "BTC-XXX", wantErr: `unknown currency "XXX"`},
+		{input: "BTC-", wantErr: "expected two currencies"},
+		{input: "BTC-USD-EUR", wantErr: "expected two currencies"},
+		{input: "BTCBTC", wantErr: "expected two different currencies"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			pair, err := NewPairFromString(tt.input)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, pair)
+		})
+	}
+}
+
+func TestCurrencyRegistry_ParsePair(t *testing.T) {
+	t.Run("Rejects concatenated pairs that split in more than one way", func(t *testing.T) {
+		registry, err := NewCurrencyRegistry(
+			CurrencyInfo{Code: USD, Name: "US Dollar", Decimals: 2, Kind: Fiat},
+			CurrencyInfo{Code: USDT, Name: "Tether", Decimals: 6, Kind: Crypto},
+			CurrencyInfo{Code: "TUSD", Name: "TrueUSD", Decimals: 18, Kind: Crypto},
+		)
+		require.NoError(t, err)
+
+		_, err = registry.ParsePair("USDTUSD")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ambiguous pair")
+
+		pair, err := registry.ParsePair("USD-TUSD")
+		require.NoError(t, err)
+		assert.Equal(t, NewPair(USD, "TUSD"), pair)
+	})
+}
+
+func TestNewCurrencyRegistry(t *testing.T) {
+	valid := CurrencyInfo{Code: BTC, Name: "Bitcoin", Decimals: 8, Kind: Crypto}
+
+	t.Run("Lists the currencies by code", func(t *testing.T) {
+		registry, err := NewCurrencyRegistry(valid, CurrencyInfo{Code: "ADA", Name: "Cardano", Decimals: 6, Kind: Crypto})
+		require.NoError(t, err)
+
+		all := registry.All()
+		require.Len(t, all, 2)
+		assert.Equal(t, Currency("ADA"), all[0].Code)
+		assert.Equal(t, BTC, all[1].Code)
+
+		currency, ok := registry.Lookup(BTC)
+		assert.True(t, ok)
+		assert.Equal(t, valid, currency)
+	})
+
+	t.Run("Rejects invalid currencies", func(t *testing.T) {
+		invalid := []CurrencyInfo{
+			{Code: "btc", Name: "Bitcoin", Decimals: 8, Kind: Crypto},
+			{Code: "B", Name: "Bitcoin", Decimals: 8, Kind: Crypto},
+			{Code: "BTC", Name: "Bitcoin", Decimals: 8, Kind: "token"},
+			{Code: "BTC", Name: "Bitcoin", Decimals: -1, Kind: Crypto},
+		}
+
+		for _, currency := range invalid {
+			_, err := NewCurrencyRegistry(currency)
+			assert.Error(t, err, "currency %+v", currency)
+		}
+
+		_, err := NewCurrencyRegistry(valid, valid)
+		assert.Error(t, err, "duplicated currency")
+	})
+
+	t.Run("Has no ambiguous default pairs", func(t *testing.T) {
+		for _, from := range DefaultCurrencies.All() {
+			for _, to := range DefaultCurrencies.All() {
+				if from.Code == to.Code {
+					continue
+				}
+
+				pair, err := DefaultCurrencies.ParsePair(NewPair(from.Code, to.Code).String())
+				require.NoError(t, err)
+				assert.Equal(t, NewPair(from.Code, to.Code), pair)
+			}
+		}
+	})
+}
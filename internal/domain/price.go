@@ -10,4 +10,15 @@ type PriceUpdate struct {
 	Pair       Pair
 	Price      decimal.Decimal
 	ReceivedAt time.Time
+	// Backfilled flags the prices loaded from the upstream history on startup, instead of received live
+	Backfilled bool
+	// Legs are the quoted pairs a synthetic price is derived from, in order; empty for the prices quoted upstream
+	Legs []Pair
+	// Quote is the market data reported along with the price, when the provider reports it
+	Quote *Quote
+}
+
+// Synthetic tells whether the price is derived from the prices of other pairs, instead of quoted upstream.
+func (u PriceUpdate) Synthetic() bool {
+	return len(u.Legs) > 0
 }
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// vwapPrecision is the number of decimals the volume weighted average prices are divided with
+const vwapPrecision = 18
+
+// Quote holds the market data reported along with a price, for trading use cases. Each value is only valid when the
+// upstream reports it, e.g. not every market publishes its bid and ask.
+type Quote struct {
+	Bid            decimal.NullDecimal
+	Ask            decimal.NullDecimal
+	Volume24h      decimal.NullDecimal // traded over the last 24 hours, in the base currency
+	QuoteVolume24h decimal.NullDecimal // traded over the last 24 hours, in the quote currency
+	High24h        decimal.NullDecimal
+	Low24h         decimal.NullDecimal
+	ChangePct24h   decimal.NullDecimal // change of the price over the last 24 hours, in percent
+}
+
+// Spread returns the difference between the ask and the bid, valid only when both are.
+func (q Quote) Spread() decimal.NullDecimal {
+	if !q.Bid.Valid || !q.Ask.Valid {
+		return decimal.NullDecimal{}
+	}
+	return decimal.NewNullDecimal(q.Ask.Decimal.Sub(q.Bid.Decimal))
+}
+
+// VWAP24h returns the volume weighted average price over the last 24 hours, the volume traded in the quote currency
+// over the one traded in the base currency, valid only when both are and some volume was traded.
+func (q Quote) VWAP24h() decimal.NullDecimal {
+	if !q.QuoteVolume24h.Valid || !q.Volume24h.Valid || !q.Volume24h.Decimal.IsPositive() {
+		return decimal.NullDecimal{}
+	}
+	return decimal.NewNullDecimal(q.QuoteVolume24h.Decimal.DivRound(q.Volume24h.Decimal, vwapPrecision))
+}
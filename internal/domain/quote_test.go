@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuote_Spread(t *testing.T) {
+	quote := Quote{
+		Bid: decimal.NewNullDecimal(decimal.RequireFromString("49999.5")),
+		Ask: decimal.NewNullDecimal(decimal.RequireFromString("50000.25")),
+	}
+
+	spread := quote.Spread()
+	assert.True(t, spread.Valid)
+	assert.Equal(t, "0.75", spread.Decimal.String())
+
+	quote.Bid = decimal.NullDecimal{}
+	assert.False(t, quote.Spread().Valid, "Expected no spread without a bid")
+}
+
+func TestQuote_VWAP24h(t *testing.T) {
+	quote := Quote{
+		Volume24h:      decimal.NewNullDecimal(decimal.RequireFromString("2")),
+		QuoteVolume24h: decimal.NewNullDecimal(decimal.RequireFromString("101000")),
+	}
+
+	vwap := quote.VWAP24h()
+	assert.True(t, vwap.Valid)
+	assert.Equal(t, "50500", vwap.Decimal.String())
+
+	quote.Volume24h = decimal.NewNullDecimal(decimal.Zero)
+	assert.False(t, quote.VWAP24h().Valid, "Expected no VWAP without volume")
+
+	quote.Volume24h = decimal.NullDecimal{}
+	assert.False(t, quote.VWAP24h().Valid)
+}
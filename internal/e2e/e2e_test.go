@@ -0,0 +1,243 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/api/http_handlers"
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/fake_upstream"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+)
+
+// receive returns the next event of the stream, failing the test if none arrives in time.
+func receive[T any](t *testing.T, events <-chan T) T {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "Expected the stream to be open")
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected an event to be streamed")
+		var zero T
+		return zero
+	}
+}
+
+func TestApplication_StreamsUpstreamPrices(t *testing.T) {
+	h := Start(t, func(cfg *config.Config) {
+		cfg.PairPriceToMonitor = "BTCUSD,ETHUSD"
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	btc := h.Stream(ctx, "BTCUSD", "")
+	eth := h.Stream(ctx, "ETHUSD", "")
+
+	event := receive(t, btc)
+	assert.Equal(t, "BTCUSD", event.Pair)
+	assert.Equal(t, "50000", event.Price)
+
+	event = receive(t, eth)
+	assert.Equal(t, "ETHUSD", event.Pair)
+	assert.Equal(t, "3000", event.Price)
+
+	h.Upstream.SetPrice(domain.NewPair(domain.BTC, domain.USD), decimal.RequireFromString("50123.45"))
+
+	for event = receive(t, btc); event.Price == "50000"; event = receive(t, btc) {
+	}
+	assert.Equal(t, "50123.45", event.Price)
+}
+
+func TestApplication_DerivesSyntheticPairs(t *testing.T) {
+	h := Start(t, func(cfg *config.Config) {
+		cfg.PairPriceToMonitor = "BTCUSD,ETHUSD"
+		cfg.SyntheticPairs = "ETHBTC,USDBTC"
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	event := receive(t, h.Stream(ctx, "ETH-BTC", ""))
+	assert.Equal(t, "ETHBTC", event.Pair)
+	assert.Equal(t, "0.06", event.Price)
+	assert.True(t, event.Synthetic)
+	assert.Equal(t, []string{"ETHUSD", "BTCUSD"}, event.Legs)
+
+	event = receive(t, h.Stream(ctx, "USDBTC", ""))
+	assert.Equal(t, "0.00002", event.Price)
+	assert.Equal(t, []string{"BTCUSD"}, event.Legs)
+}
+
+func TestApplication_StreamsFullQuotes(t *testing.T) {
+	h := Start(t, func(cfg *config.Config) {
+		cfg.PricesFullQuotes = true
+	})
+	h.Upstream.SetQuote(domain.NewPair(domain.BTC, domain.USD), domain.Quote{
+		Bid:       decimal.NewNullDecimal(decimal.RequireFromString("49999.5")),
+		Ask:       decimal.NewNullDecimal(decimal.RequireFromString("50000.5")),
+		Volume24h: decimal.NewNullDecimal(decimal.RequireFromString("1234.5")),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := h.Stream(ctx, "BTCUSD", "fields=full")
+
+	event := receive(t, events)
+	for ; event.QuoteResponse == nil; event = receive(t, events) {
+	}
+	assert.Equal(t, "50000", event.Price)
+	assert.Equal(t, "1", event.Spread)
+	assert.Equal(t, "1234.5", event.Volume24h)
+	assert.Positive(t, h.Upstream.Requests("/data/pricemultifull"))
+
+	event = receive(t, h.Stream(ctx, "BTCUSD", ""))
+	assert.Nil(t, event.QuoteResponse, "Expected the default shape without the fields parameter")
+}
+
+func TestApplication_ConvertsPrices(t *testing.T) {
+	ratesPath := filepath.Join(t.TempDir(), "fx_rates.json")
+	require.NoError(t, os.WriteFile(ratesPath, []byte(
+		`{"base":"USD","updated_at":"2025-01-01T00:00:00Z","rates":{"EUR":"0.92","GBP":"0.8"}}`,
+	), 0o600))
+
+	h := Start(t, func(cfg *config.Config) {
+		cfg.FXEnabled = true
+		cfg.FXSource = config.FXSourceFile
+		cfg.FXFilePath = ratesPath
+		cfg.FXBaseCurrency = "USD"
+		cfg.FXCurrencies = "EUR,GBP"
+		cfg.FXRefreshInterval = time.Hour
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	event := receive(t, h.Stream(ctx, "BTCUSD", "convert=EUR"))
+	assert.Equal(t, "BTCEUR", event.Pair)
+	assert.Equal(t, "46000", event.Price)
+	require.NotNil(t, event.Conversion)
+	assert.Equal(t, sse.ConversionResponse{From: "USD", Rate: "0.92", RateAt: "2025-01-01T00:00:00Z"}, *event.Conversion)
+
+	latest := h.Latest("BTCUSD", "convert=GBP")
+	assert.Equal(t, "BTCGBP", latest.Pair)
+	assert.Equal(t, "40000", latest.Price)
+	assert.Equal(t, "0.8", latest.Conversion.Rate)
+
+	assert.Nil(t, h.Latest("BTCUSD", "").Conversion)
+}
+
+func TestApplication_ComputesIndicators(t *testing.T) {
+	h := Start(t, func(cfg *config.Config) {
+		cfg.AnalyticsEnabled = true
+		cfg.AnalyticsWindows = "3"
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := h.StreamIndicators(ctx, "BTCUSD")
+
+	event := receive(t, events)
+	for ; event.Samples < 3; event = receive(t, events) {
+	}
+	assert.Equal(t, "BTCUSD", event.Pair)
+	require.Len(t, event.Windows, 1)
+	assert.Equal(t, http_handlers.WindowIndicatorsResponse{
+		Size: 3, SMA: "50000", EMA: "50000", StdDev: "0", Volatility: "0",
+	}, event.Windows[0])
+
+	indicators := h.Indicators("BTCUSD")
+	assert.GreaterOrEqual(t, indicators.Samples, 3)
+	assert.Equal(t, "50000", indicators.Price)
+}
+
+func TestApplication_SurvivesUpstreamFailures(t *testing.T) {
+	h := Start(t, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := h.Stream(ctx, "BTCUSD", "")
+	receive(t, events)
+
+	h.Upstream.Enqueue(fake_upstream.RateLimited(), fake_upstream.MalformedJSON())
+	h.Upstream.Enqueue(fake_upstream.ServerErrors(http.StatusServiceUnavailable, 5)...)
+	h.Upstream.Enqueue(fake_upstream.ErrorBody("market does not exist"))
+
+	h.Upstream.SetPrice(domain.NewPair(domain.BTC, domain.USD), decimal.NewFromInt(51000))
+
+	event := receive(t, events)
+	for ; event.Price == "50000"; event = receive(t, events) {
+	}
+	assert.Equal(t, "51000", event.Price)
+	assert.GreaterOrEqual(t, h.Upstream.Requests("/data/price"), 10)
+}
+
+func TestApplication_ReplaysHistory(t *testing.T) {
+	h := Start(t, nil)
+
+	require.Eventually(t, func() bool {
+		return len(h.History("BTCUSD", 0)) >= 5
+	}, 5*time.Second, 20*time.Millisecond)
+
+	history := h.History("BTCUSD", 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := h.Stream(ctx, "BTCUSD", "since=0")
+
+	for i := range history {
+		event := receive(t, events)
+		assert.Equal(t, history[i].ReceivedAt, event.ReceivedAt, "Expected the stored history to be streamed first")
+	}
+}
+
+func TestApplication_Backfill(t *testing.T) {
+	h := Start(t, func(cfg *config.Config) {
+		cfg.BackfillEnabled = true
+		cfg.BackfillPeriod = 10 * time.Minute
+		cfg.BackfillInterval = time.Minute
+		cfg.PricesPullingInterval = time.Hour
+	})
+
+	history := h.History("BTCUSD", 0)
+	require.Len(t, history, 10, "Expected the history to be backfilled before the server starts")
+	assert.True(t, history[0].Backfilled)
+}
+
+func TestApplication_Shutdown(t *testing.T) {
+	h := Start(t, nil)
+
+	events := h.Stream(context.Background(), "BTCUSD", "")
+	receive(t, events)
+
+	started := time.Now()
+	require.NoError(t, h.Stop())
+	assert.Less(t, time.Since(started), 2*time.Second, "Expected connected clients not to delay the shutdown")
+
+	require.Eventually(t, func() bool {
+		select {
+		case _, ok := <-events:
+			return !ok
+		default:
+			return false
+		}
+	}, 2*time.Second, 10*time.Millisecond, "Expected the stream to end")
+
+	_, err := http.Get(h.URL + "/health")
+	assert.Error(t, err, "Expected the server to stop listening")
+}
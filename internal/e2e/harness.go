@@ -0,0 +1,278 @@
+// Package e2e boots the whole application in-process against a fake upstream, to test it end to end.
+package e2e
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/api/http_handlers"
+	"github.com/tonytcb/crypto-pricing-api/internal/app"
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/fake_upstream"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+)
+
+// stopTimeout bounds how long the application may take to stop.
+const stopTimeout = 10 * time.Second
+
+// Harness runs the application on an ephemeral port, polling a fake upstream.
+type Harness struct {
+	t        testing.TB
+	URL      string
+	Upstream *fake_upstream.Server
+	app      *app.Application
+	client   *http.Client
+	cancel   context.CancelFunc
+	done     chan error
+	stopped  bool
+}
+
+// NewConfig returns a valid configuration polling the given upstream every 20ms, with an in-memory store.
+func NewConfig(upstreamURL string) *config.Config {
+	return &config.Config{
+		Environment:               "test",
+		LogLevel:                  "error",
+		RestAPIPort:               "127.0.0.1:0",
+		PairPriceToMonitor:        "BTCUSD",
+		StoreType:                 config.StoreTypeRingBuffer,
+		StoreMaxItems:             1000,
+		SseClientsBufferSize:      100,
+		SSEClientsCleanUpInterval: time.Second,
+		PricesProvider:            config.ProviderCoinDesk,
+		PricesFanout:              config.FanoutLocal,
+		PricesPullingEnabled:      true,
+		PricesPullingInterval:     20 * time.Millisecond,
+		PricesChannelBufferSize:   100,
+		CoinDeskAPIURL:            upstreamURL + "/data/price",
+		CoinDeskHistoryAPIURL:     upstreamURL + "/data/v2",
+		CoinDeskFullAPIURL:        upstreamURL + "/data/pricemultifull",
+		CoinDeskRetryMaxAttempts:  2,
+		CoinDeskClientTimeout:     time.Second,
+		CoinDeskRetryInitialWait:  5 * time.Millisecond,
+		CoinDeskRetryMaxWait:      10 * time.Millisecond,
+	}
+}
+
+// Start boots the application, with the configuration changed by configure, and waits until it is healthy.
+// The upstream answers a price of 50000 for BTCUSD and 3000 for ETHUSD. The application is stopped on cleanup.
+func Start(t testing.TB, configure func(cfg *config.Config), opts ...app.Option) *Harness {
+	t.Helper()
+
+	upstream := fake_upstream.New()
+	upstream.SetPrice(domain.NewPair(domain.BTC, domain.USD), decimal.NewFromInt(50000))
+	upstream.SetPrice(domain.NewPair(domain.ETH, domain.USD), decimal.NewFromInt(3000))
+
+	upstreamServer := &http.Server{Handler: upstream, ReadHeaderTimeout: time.Second}
+	upstreamListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = upstreamServer.Serve(upstreamListener)
+	}()
+	t.Cleanup(func() {
+		_ = upstreamServer.Close()
+	})
+
+	cfg := NewConfig("http://" + upstreamListener.Addr().String())
+	if configure != nil {
+		configure(cfg)
+	}
+	require.NoError(t, app.ValidateConfig(cfg))
+
+	listener, err := net.Listen("tcp", cfg.RestAPIPort)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(cfg.SlogLevel())
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	application, err := app.NewApplication(ctx, cfg, log, logLevel, append(opts, app.WithListener(listener))...)
+	if err != nil {
+		cancel()
+		_ = listener.Close()
+		require.NoError(t, err)
+	}
+
+	h := &Harness{
+		t:        t,
+		URL:      "http://" + listener.Addr().String(),
+		Upstream: upstream,
+		app:      application,
+		client:   &http.Client{Transport: &http.Transport{}},
+		cancel:   cancel,
+		done:     make(chan error, 1),
+	}
+
+	go func() {
+		h.done <- application.Run(ctx)
+	}()
+
+	t.Cleanup(func() {
+		if !h.stopped {
+			_ = h.Stop()
+		}
+	})
+
+	require.Eventually(t, func() bool {
+		resp, err := h.client.Get(h.URL + "/health")
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond, "Expected the application to become healthy")
+
+	return h
+}
+
+// Stop stops the application, returning the error it ran with.
+func (h *Harness) Stop() error {
+	h.t.Helper()
+	h.stopped = true
+
+	// the server waits a while for the connections dialed without a request yet, e.g. spare keep-alive ones
+	h.client.CloseIdleConnections()
+
+	h.app.Stop()
+	h.cancel()
+
+	select {
+	case err := <-h.done:
+		return err
+	case <-time.After(stopTimeout):
+		h.t.Fatalf("The application didn't stop within %s", stopTimeout)
+		return nil
+	}
+}
+
+// History returns the history of a pair since the given unix timestamp.
+func (h *Harness) History(pair string, since int64) []sse.PriceStreamResponse {
+	h.t.Helper()
+
+	resp, err := h.client.Get(h.URL + "/prices/" + pair + "/history?since=" + strconv.FormatInt(since, 10))
+	require.NoError(h.t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	require.Equal(h.t, http.StatusOK, resp.StatusCode)
+
+	var history []sse.PriceStreamResponse
+	require.NoError(h.t, json.NewDecoder(resp.Body).Decode(&history))
+
+	return history
+}
+
+// Latest returns the latest price of a pair. The query, e.g. convert=EUR, is appended to the request.
+func (h *Harness) Latest(pair, query string) sse.PriceStreamResponse {
+	h.t.Helper()
+
+	url := h.URL + "/prices/" + pair + "/latest"
+	if query != "" {
+		url += "?" + query
+	}
+
+	resp, err := h.client.Get(url)
+	require.NoError(h.t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	require.Equal(h.t, http.StatusOK, resp.StatusCode)
+
+	var latest sse.PriceStreamResponse
+	require.NoError(h.t, json.NewDecoder(resp.Body).Decode(&latest))
+
+	return latest
+}
+
+// Indicators returns the rolling indicators of a pair.
+func (h *Harness) Indicators(pair string) http_handlers.IndicatorsResponse {
+	h.t.Helper()
+
+	resp, err := h.client.Get(h.URL + "/prices/" + pair + "/indicators")
+	require.NoError(h.t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	require.Equal(h.t, http.StatusOK, resp.StatusCode)
+
+	var indicators http_handlers.IndicatorsResponse
+	require.NoError(h.t, json.NewDecoder(resp.Body).Decode(&indicators))
+
+	return indicators
+}
+
+// Stream connects an SSE client to the stream of a pair, returning the received events. The query, e.g. since=0,
+// is appended to the request. The channel is closed when the stream ends.
+func (h *Harness) Stream(ctx context.Context, pair, query string) <-chan sse.PriceStreamResponse {
+	h.t.Helper()
+
+	url := h.URL + "/prices/" + pair + "/stream"
+	if query != "" {
+		url += "?" + query
+	}
+
+	return stream[sse.PriceStreamResponse](ctx, h, url)
+}
+
+// StreamIndicators connects an SSE client to the stream of the indicators of a pair, returning the received events.
+func (h *Harness) StreamIndicators(ctx context.Context, pair string) <-chan http_handlers.IndicatorsResponse {
+	h.t.Helper()
+
+	return stream[http_handlers.IndicatorsResponse](ctx, h, h.URL+"/prices/"+pair+"/indicators/stream")
+}
+
+// stream decodes the events of an SSE stream, until it ends.
+func stream[T any](ctx context.Context, h *Harness, url string) <-chan T {
+	h.t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(h.t, err)
+
+	resp, err := h.client.Do(req)
+	require.NoError(h.t, err)
+	require.Equal(h.t, http.StatusOK, resp.StatusCode)
+
+	events := make(chan T, 1000)
+
+	go func() {
+		defer func() {
+			_ = resp.Body.Close()
+			close(events)
+		}()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, found := strings.CutPrefix(scanner.Text(), "data: ")
+			if !found {
+				continue
+			}
+
+			var event T
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				h.t.Errorf("Invalid event %q: %s", data, err)
+				return
+			}
+
+			events <- event
+		}
+	}()
+
+	return events
+}
@@ -0,0 +1,218 @@
+package analytics
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// precision is the number of decimals the averages are rounded to
+const precision = 18
+
+// Indicators are the rolling indicators of a pair, as of its latest price.
+type Indicators struct {
+	Pair  domain.Pair
+	At    time.Time
+	Price decimal.Decimal
+	// Samples is the number of prices the indicators are computed from so far
+	Samples int
+	Windows []WindowIndicators
+	// VWAP24h is the volume weighted average price over the last 24 hours, as reported upstream along with the latest
+	// price. It's not computed from the prices received, and is null when the latest price has no 24h volumes.
+	VWAP24h decimal.NullDecimal
+}
+
+// WindowIndicators are the indicators over the last Size prices, only valid once that many prices are received.
+type WindowIndicators struct {
+	Size int
+	SMA  decimal.NullDecimal
+	EMA  decimal.NullDecimal
+	// StdDev is the standard deviation of the prices
+	StdDev decimal.NullDecimal
+	// Volatility is the standard deviation of the log returns between the prices, i.e. per update
+	Volatility decimal.NullDecimal
+}
+
+// Engine maintains rolling indicators per pair over windows of the latest prices, e.g. the SMA of the last 20 ones.
+type Engine struct {
+	mu      sync.RWMutex
+	windows []int
+	series  map[domain.Pair]*series
+}
+
+// NewEngine computes the indicators over each window, given in number of prices.
+func NewEngine(windows []int) *Engine {
+	windows = slices.Clone(windows)
+	slices.Sort(windows)
+
+	return &Engine{
+		windows: slices.Compact(windows),
+		series:  make(map[domain.Pair]*series),
+	}
+}
+
+// Update adds the price of an update to the series of its pair, returning the updated indicators. The backfilled
+// updates, and the ones older than the latest price of the pair, are ignored.
+func (e *Engine) Update(update domain.PriceUpdate) (Indicators, bool) {
+	if update.Backfilled {
+		return Indicators{}, false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, ok := e.series[update.Pair]
+	if !ok {
+		s = newSeries(e.windows)
+		e.series[update.Pair] = s
+	}
+
+	if !s.add(update) {
+		return Indicators{}, false
+	}
+
+	return s.indicators, true
+}
+
+// Indicators returns the indicators of a pair, as of its latest price.
+func (e *Engine) Indicators(pair domain.Pair) (Indicators, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	s, ok := e.series[pair]
+	if !ok {
+		return Indicators{}, false
+	}
+
+	return s.indicators, true
+}
+
+// series holds the latest prices of a pair, as many as the largest window, and the state of the averages.
+type series struct {
+	windows    []int
+	prices     []decimal.Decimal
+	sums       []decimal.Decimal
+	emas       []decimal.NullDecimal
+	samples    int
+	indicators Indicators
+}
+
+func newSeries(windows []int) *series {
+	return &series{
+		windows: windows,
+		prices:  make([]decimal.Decimal, 0, windows[len(windows)-1]),
+		sums:    make([]decimal.Decimal, len(windows)),
+		emas:    make([]decimal.NullDecimal, len(windows)),
+	}
+}
+
+func (s *series) add(update domain.PriceUpdate) bool {
+	if s.samples > 0 && update.ReceivedAt.Before(s.indicators.At) {
+		return false
+	}
+
+	// the sums of the windows drop the prices leaving them, before the window of the largest one moves on
+	for i, size := range s.windows {
+		if len(s.prices) >= size {
+			s.sums[i] = s.sums[i].Sub(s.prices[len(s.prices)-size])
+		}
+		s.sums[i] = s.sums[i].Add(update.Price)
+	}
+
+	if len(s.prices) == cap(s.prices) {
+		s.prices = append(s.prices[:0], s.prices[1:]...)
+	}
+	s.prices = append(s.prices, update.Price)
+	s.samples++
+
+	indicators := Indicators{
+		Pair:    update.Pair,
+		At:      update.ReceivedAt,
+		Price:   update.Price,
+		Samples: s.samples,
+		Windows: make([]WindowIndicators, len(s.windows)),
+	}
+
+	// the upstream VWAP is only reported as of the price it came with, so that it's not kept once quotes stop
+	if update.Quote != nil {
+		indicators.VWAP24h = update.Quote.VWAP24h()
+	}
+
+	for i, size := range s.windows {
+		indicators.Windows[i] = s.window(i, size, update.Price)
+	}
+
+	s.indicators = indicators
+
+	return true
+}
+
+func (s *series) window(i, size int, price decimal.Decimal) WindowIndicators {
+	indicators := WindowIndicators{Size: size}
+
+	if s.samples < size {
+		return indicators
+	}
+
+	sma := s.sums[i].DivRound(decimal.NewFromInt(int64(size)), precision)
+	indicators.SMA = decimal.NewNullDecimal(sma)
+
+	// the EMA is seeded with the SMA of the first full window
+	if !s.emas[i].Valid {
+		s.emas[i] = decimal.NewNullDecimal(sma)
+	} else {
+		alpha := decimal.NewFromInt(2).DivRound(decimal.NewFromInt(int64(size+1)), precision)
+		ema := s.emas[i].Decimal
+		s.emas[i] = decimal.NewNullDecimal(ema.Add(alpha.Mul(price.Sub(ema))).Round(precision))
+	}
+	indicators.EMA = s.emas[i]
+
+	var (
+		window  = s.prices[len(s.prices)-size:]
+		prices  = make([]float64, len(window))
+		returns = make([]float64, len(window)-1)
+	)
+	for j, p := range window {
+		prices[j] = p.InexactFloat64()
+		if j > 0 {
+			returns[j-1] = math.Log(prices[j] / prices[j-1])
+		}
+	}
+
+	indicators.StdDev = toNullDecimal(stdDev(prices))
+	indicators.Volatility = toNullDecimal(stdDev(returns))
+
+	return indicators
+}
+
+// stdDev returns the population standard deviation of the values, or NaN when there are none.
+func stdDev(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+
+	return math.Sqrt(squares / float64(len(values)))
+}
+
+func toNullDecimal(value float64) decimal.NullDecimal {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return decimal.NullDecimal{}
+	}
+	return decimal.NewNullDecimal(decimal.NewFromFloat(value))
+}
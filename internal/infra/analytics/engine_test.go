@@ -0,0 +1,150 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+var (
+	btcUsd = domain.NewPair(domain.BTC, domain.USD)
+	ethUsd = domain.NewPair(domain.ETH, domain.USD)
+	start  = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+)
+
+func quote(pair domain.Pair, price string, at time.Time) domain.PriceUpdate {
+	return domain.PriceUpdate{Pair: pair, Price: decimal.RequireFromString(price), ReceivedAt: at}
+}
+
+func feed(engine *Engine, pair domain.Pair, prices ...string) Indicators {
+	var indicators Indicators
+	for i, price := range prices {
+		indicators, _ = engine.Update(quote(pair, price, start.Add(time.Duration(i)*time.Second)))
+	}
+	return indicators
+}
+
+func TestEngine_Update(t *testing.T) {
+	t.Run("Leaves a window invalid until it is full", func(t *testing.T) {
+		engine := NewEngine([]int{3})
+
+		indicators := feed(engine, btcUsd, "100", "110")
+		assert.Equal(t, 2, indicators.Samples)
+		require.Len(t, indicators.Windows, 1)
+		assert.Equal(t, 3, indicators.Windows[0].Size)
+		assert.False(t, indicators.Windows[0].SMA.Valid)
+		assert.False(t, indicators.Windows[0].EMA.Valid)
+		assert.False(t, indicators.Windows[0].StdDev.Valid)
+		assert.False(t, indicators.Windows[0].Volatility.Valid)
+	})
+
+	t.Run("Computes the moving averages over the latest prices", func(t *testing.T) {
+		engine := NewEngine([]int{4, 2})
+
+		indicators := feed(engine, btcUsd, "100", "110", "120", "130")
+		require.Len(t, indicators.Windows, 2)
+		assert.Equal(t, 2, indicators.Windows[0].Size, "Expected the windows sorted by size")
+
+		assert.Equal(t, "125", indicators.Windows[0].SMA.Decimal.String())
+		assert.Equal(t, "115", indicators.Windows[1].SMA.Decimal.String())
+		assert.Equal(t, "115", indicators.Windows[1].EMA.Decimal.String(), "Expected the EMA seeded with the SMA")
+
+		indicators = feed(NewEngine([]int{4, 2}), btcUsd, "100", "110", "120", "130", "160")
+		assert.Equal(t, "145", indicators.Windows[0].SMA.Decimal.String())
+		assert.Equal(t, "130", indicators.Windows[1].SMA.Decimal.String())
+		// 115 + 2/5 * (160 - 115)
+		assert.Equal(t, "133", indicators.Windows[1].EMA.Decimal.String())
+	})
+
+	t.Run("Computes the standard deviation and the volatility", func(t *testing.T) {
+		engine := NewEngine([]int{3})
+
+		indicators := feed(engine, btcUsd, "100", "100", "100")
+		assert.True(t, indicators.Windows[0].StdDev.Decimal.IsZero())
+		assert.True(t, indicators.Windows[0].Volatility.Decimal.IsZero())
+
+		indicators = feed(NewEngine([]int{3}), btcUsd, "90", "100", "110")
+		assert.InDelta(t, 8.1650, indicators.Windows[0].StdDev.Decimal.InexactFloat64(), 0.0001)
+		assert.InDelta(t, 0.0050, indicators.Windows[0].Volatility.Decimal.InexactFloat64(), 0.0001)
+	})
+
+	t.Run("Reports the upstream 24 hours VWAP of the latest price", func(t *testing.T) {
+		engine := NewEngine([]int{2})
+
+		update := quote(btcUsd, "100", start)
+		update.Quote = &domain.Quote{
+			Volume24h:      decimal.NewNullDecimal(decimal.RequireFromString("10")),
+			QuoteVolume24h: decimal.NewNullDecimal(decimal.RequireFromString("990")),
+		}
+
+		indicators, ok := engine.Update(update)
+		require.True(t, ok)
+		assert.Equal(t, "99", indicators.VWAP24h.Decimal.String())
+
+		indicators, ok = engine.Update(quote(btcUsd, "101", start.Add(time.Second)))
+		require.True(t, ok)
+		assert.False(t, indicators.VWAP24h.Valid, "Expected the VWAP cleared once the prices have no quotes")
+	})
+
+	t.Run("Ignores backfilled and stale updates", func(t *testing.T) {
+		engine := NewEngine([]int{2})
+		feed(engine, btcUsd, "100")
+
+		backfilled := quote(btcUsd, "50", start.Add(time.Second))
+		backfilled.Backfilled = true
+		_, ok := engine.Update(backfilled)
+		assert.False(t, ok)
+
+		_, ok = engine.Update(quote(btcUsd, "50", start.Add(-time.Second)))
+		assert.False(t, ok)
+
+		indicators, ok := engine.Indicators(btcUsd)
+		require.True(t, ok)
+		assert.Equal(t, 1, indicators.Samples)
+		assert.Equal(t, "100", indicators.Price.String())
+	})
+
+	t.Run("Keeps the pairs apart", func(t *testing.T) {
+		engine := NewEngine([]int{2})
+		feed(engine, btcUsd, "100", "110")
+
+		_, ok := engine.Indicators(ethUsd)
+		assert.False(t, ok)
+
+		indicators := feed(engine, ethUsd, "10", "20")
+		assert.Equal(t, ethUsd, indicators.Pair)
+		assert.Equal(t, "15", indicators.Windows[0].SMA.Decimal.String())
+	})
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Broadcast(update domain.PriceUpdate) {
+	m.Called(update)
+}
+
+func TestIndicatorsNotifier_Broadcast(t *testing.T) {
+	var (
+		notifier = new(MockNotifier)
+		engine   = NewEngine([]int{2})
+		update   = quote(btcUsd, "100", start)
+	)
+
+	notifier.On("Broadcast", update).Run(func(mock.Arguments) {
+		indicators, ok := engine.Indicators(btcUsd)
+		require.True(t, ok, "Expected the indicators updated before the broadcast")
+		assert.Equal(t, update.Price, indicators.Price)
+	}).Return().Once()
+
+	NewIndicatorsNotifier(notifier, engine).Broadcast(update)
+
+	notifier.AssertExpectations(t)
+}
@@ -0,0 +1,28 @@
+package analytics
+
+import (
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+type Notifier interface {
+	Broadcast(update domain.PriceUpdate)
+}
+
+// IndicatorsNotifier feeds the engine with the updates before broadcasting them, so the indicators are up to date by
+// the time the subscribers are notified.
+type IndicatorsNotifier struct {
+	notifier Notifier
+	engine   *Engine
+}
+
+func NewIndicatorsNotifier(notifier Notifier, engine *Engine) *IndicatorsNotifier {
+	return &IndicatorsNotifier{
+		notifier: notifier,
+		engine:   engine,
+	}
+}
+
+func (n *IndicatorsNotifier) Broadcast(update domain.PriceUpdate) {
+	n.engine.Update(update)
+	n.notifier.Broadcast(update)
+}
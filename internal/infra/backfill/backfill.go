@@ -0,0 +1,91 @@
+package backfill
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+)
+
+type HistoryProvider interface {
+	GetHistory(ctx context.Context, pair domain.Pair, interval time.Duration, limit int) ([]domain.PriceUpdate, error)
+}
+
+type PricesRepository interface {
+	Store(priceUpdate domain.PriceUpdate)
+	GetLatest(pair domain.Pair) (domain.PriceUpdate, bool)
+}
+
+// Backfiller seeds a repository with the recent upstream history of the monitored pairs, so that history queries
+// are answered right after a cold start, before the first live prices are received.
+type Backfiller struct {
+	log      *slog.Logger
+	provider HistoryProvider
+	repo     PricesRepository
+	period   time.Duration
+	interval time.Duration
+	clock    clock.Clock
+}
+
+func NewBackfiller(provider HistoryProvider, repo PricesRepository, period, interval time.Duration, clk clock.Clock) *Backfiller {
+	return &Backfiller{
+		log:      slog.Default(),
+		provider: provider,
+		repo:     repo,
+		period:   period,
+		interval: interval,
+		clock:    clk,
+	}
+}
+
+// Run stores the history of each pair over the configured period, returning the number of prices stored. Prices
+// at or before the latest one already stored, e.g. by a persistent store, are skipped. A pair whose history can't be
+// fetched is logged and skipped, as the live prices fill the history anyway.
+func (b *Backfiller) Run(ctx context.Context, pairs []domain.Pair) int {
+	var stored int
+
+	for _, pair := range pairs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		count, err := b.backfill(ctx, pair)
+		if err != nil {
+			b.log.Warn("Failed to backfill pair history", "pair", pair.String(), "error", err.Error())
+			continue
+		}
+
+		b.log.Info("Pair history backfilled", "pair", pair.String(), "prices", count)
+		stored += count
+	}
+
+	return stored
+}
+
+func (b *Backfiller) backfill(ctx context.Context, pair domain.Pair) (int, error) {
+	from := b.clock.Now().Add(-b.period)
+
+	history, err := b.provider.GetHistory(ctx, pair, b.interval, int(b.period/b.interval))
+	if err != nil {
+		return 0, err
+	}
+
+	var latest time.Time
+	if existing, ok := b.repo.GetLatest(pair); ok {
+		latest = existing.ReceivedAt
+	}
+
+	var stored int
+	for _, update := range history {
+		if update.ReceivedAt.Before(from) || !update.ReceivedAt.After(latest) {
+			continue
+		}
+
+		b.repo.Store(update)
+		stored++
+	}
+
+	return stored, nil
+}
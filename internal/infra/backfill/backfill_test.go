@@ -0,0 +1,89 @@
+package backfill
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/in_memory"
+)
+
+type MockHistoryProvider struct {
+	mock.Mock
+}
+
+func (m *MockHistoryProvider) GetHistory(ctx context.Context, pair domain.Pair, interval time.Duration, limit int) ([]domain.PriceUpdate, error) {
+	args := m.Called(ctx, pair, interval, limit)
+	return args.Get(0).([]domain.PriceUpdate), args.Error(1)
+}
+
+func newHistory(pair domain.Pair, count int, end time.Time) []domain.PriceUpdate {
+	history := make([]domain.PriceUpdate, 0, count)
+	for i := count - 1; i >= 0; i-- {
+		history = append(history, domain.PriceUpdate{
+			Pair:       pair,
+			Price:      decimal.NewFromInt(int64(50000 + i)),
+			ReceivedAt: end.Add(-time.Duration(i) * time.Minute),
+			Backfilled: true,
+		})
+	}
+	return history
+}
+
+func TestBackfiller_Run(t *testing.T) {
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		ethUsd = domain.NewPair(domain.ETH, domain.USD)
+		now    = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		clk    = clock.NewFake(now)
+	)
+
+	t.Run("Seeds the history of every pair", func(t *testing.T) {
+		provider := new(MockHistoryProvider)
+		provider.On("GetHistory", mock.Anything, btcUsd, time.Minute, 60).Return(newHistory(btcUsd, 60, now), nil)
+		provider.On("GetHistory", mock.Anything, ethUsd, time.Minute, 60).Return(newHistory(ethUsd, 10, now), nil)
+
+		repo := in_memory.NewPricesByRingBuffer(100)
+
+		stored := NewBackfiller(provider, repo, time.Hour, time.Minute, clk).Run(context.Background(), []domain.Pair{btcUsd, ethUsd})
+		assert.Equal(t, 70, stored)
+
+		history := repo.GetSince(btcUsd, time.Time{})
+		require.Len(t, history, 60)
+		assert.True(t, history[0].Backfilled)
+
+		provider.AssertExpectations(t)
+	})
+
+	t.Run("Skips the prices already stored", func(t *testing.T) {
+		provider := new(MockHistoryProvider)
+		provider.On("GetHistory", mock.Anything, btcUsd, time.Minute, 60).Return(newHistory(btcUsd, 10, now), nil)
+
+		repo := in_memory.NewPricesByRingBuffer(100)
+		repo.Store(domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(1), ReceivedAt: now.Add(-3 * time.Minute)})
+
+		stored := NewBackfiller(provider, repo, time.Hour, time.Minute, clk).Run(context.Background(), []domain.Pair{btcUsd})
+		assert.Equal(t, 3, stored)
+		assert.Len(t, repo.GetSince(btcUsd, time.Time{}), 4)
+	})
+
+	t.Run("Keeps going when a pair fails", func(t *testing.T) {
+		provider := new(MockHistoryProvider)
+		provider.On("GetHistory", mock.Anything, btcUsd, time.Minute, 60).Return([]domain.PriceUpdate(nil), errors.New("upstream down"))
+		provider.On("GetHistory", mock.Anything, ethUsd, time.Minute, 60).Return(newHistory(ethUsd, 5, now), nil)
+
+		repo := in_memory.NewPricesByRingBuffer(100)
+
+		stored := NewBackfiller(provider, repo, time.Hour, time.Minute, clk).Run(context.Background(), []domain.Pair{btcUsd, ethUsd})
+		assert.Equal(t, 5, stored)
+		assert.Empty(t, repo.GetSince(btcUsd, time.Time{}))
+	})
+}
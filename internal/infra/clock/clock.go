@@ -0,0 +1,58 @@
+package clock
+
+import "time"
+
+// Clock is the source of the current time, tickers and timers, so time-dependent components can be driven by a Fake in tests.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	NewTimer(d time.Duration) Timer
+}
+
+// Ticker mirrors time.Ticker, with the channel behind a method.
+type Ticker interface {
+	C() <-chan time.Time
+	Reset(d time.Duration)
+	Stop()
+}
+
+// Timer mirrors time.Timer, with the channel behind a method.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+type Real struct{}
+
+// New returns the wall clock.
+func New() Real {
+	return Real{}
+}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+func (Real) NewTicker(d time.Duration) Ticker {
+	return realTicker{Ticker: time.NewTicker(d)}
+}
+
+func (Real) NewTimer(d time.Duration) Timer {
+	return realTimer{Timer: time.NewTimer(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
@@ -0,0 +1,171 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only moves when advanced, firing the due tickers and timers in order.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters map[*fakeWaiter]struct{}
+	changed chan struct{}
+}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{
+		now:     now,
+		waiters: make(map[*fakeWaiter]struct{}),
+		changed: make(chan struct{}),
+	}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return fakeTicker{fakeWaiter: f.addWaiter(d, d)}
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return fakeTimer{fakeWaiter: f.addWaiter(d, 0)}
+}
+
+// Advance moves the time forward by d, firing every ticker and timer due in between.
+// As with the time package, a tick is dropped when the previous one was not received yet.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	target := f.now.Add(d)
+
+	for {
+		next := f.nextDue(target)
+		if next == nil {
+			break
+		}
+
+		f.now = next.deadline
+		select {
+		case next.c <- f.now:
+		default:
+		}
+
+		if next.period > 0 {
+			next.deadline = next.deadline.Add(next.period)
+		} else {
+			delete(f.waiters, next)
+		}
+	}
+
+	f.now = target
+	f.notify()
+}
+
+// BlockUntilDue blocks until a ticker or timer is due within d, i.e. until advancing the clock by d fires it.
+// It lets tests wait for a goroutine to set up or reset its ticker before moving the time.
+func (f *Fake) BlockUntilDue(d time.Duration) {
+	for {
+		f.mu.Lock()
+		due, changed := f.nextDue(f.now.Add(d)) != nil, f.changed
+		f.mu.Unlock()
+
+		if due {
+			return
+		}
+		<-changed
+	}
+}
+
+func (f *Fake) addWaiter(d, period time.Duration) *fakeWaiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &fakeWaiter{
+		clock:    f,
+		c:        make(chan time.Time, 1),
+		deadline: f.now.Add(d),
+		period:   period,
+	}
+	f.waiters[w] = struct{}{}
+	f.notify()
+
+	return w
+}
+
+// nextDue returns the waiter due first at or before the deadline, if any.
+func (f *Fake) nextDue(deadline time.Time) *fakeWaiter {
+	var next *fakeWaiter
+	for w := range f.waiters {
+		if w.deadline.After(deadline) {
+			continue
+		}
+		if next == nil || w.deadline.Before(next.deadline) {
+			next = w
+		}
+	}
+	return next
+}
+
+// notify wakes up the BlockUntilDue calls; it must be called with the lock held.
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+type fakeWaiter struct {
+	clock    *Fake
+	c        chan time.Time
+	deadline time.Time
+	period   time.Duration
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.c
+}
+
+func (w *fakeWaiter) stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+
+	_, active := w.clock.waiters[w]
+	delete(w.clock.waiters, w)
+	w.clock.notify()
+
+	return active
+}
+
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+func (t fakeTicker) Reset(d time.Duration) {
+	w := t.fakeWaiter
+
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+
+	w.deadline = w.clock.now.Add(d)
+	w.period = d
+	w.clock.waiters[w] = struct{}{}
+	w.clock.notify()
+}
+
+func (t fakeTicker) Stop() {
+	t.stop()
+}
+
+type fakeTimer struct {
+	*fakeWaiter
+}
+
+func (t fakeTimer) Stop() bool {
+	return t.stop()
+}
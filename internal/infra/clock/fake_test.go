@@ -0,0 +1,118 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func received(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case at := <-c:
+		return at, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFake_Advance(t *testing.T) {
+	t.Run("Should move the time forward", func(t *testing.T) {
+		clk := NewFake(start)
+
+		clk.Advance(time.Minute)
+
+		assert.Equal(t, start.Add(time.Minute), clk.Now())
+	})
+
+	t.Run("Should fire a timer once it is due", func(t *testing.T) {
+		clk := NewFake(start)
+		timer := clk.NewTimer(time.Second)
+
+		clk.Advance(999 * time.Millisecond)
+		_, fired := received(timer.C())
+		assert.False(t, fired)
+
+		clk.Advance(time.Millisecond)
+		at, fired := received(timer.C())
+		assert.True(t, fired)
+		assert.Equal(t, start.Add(time.Second), at)
+
+		clk.Advance(time.Hour)
+		_, fired = received(timer.C())
+		assert.False(t, fired, "a timer fires only once")
+	})
+
+	t.Run("Should fire a ticker on every period, dropping the ticks not received", func(t *testing.T) {
+		clk := NewFake(start)
+		ticker := clk.NewTicker(time.Second)
+
+		clk.Advance(time.Second)
+		at, fired := received(ticker.C())
+		assert.True(t, fired)
+		assert.Equal(t, start.Add(time.Second), at)
+
+		clk.Advance(3 * time.Second)
+		at, fired = received(ticker.C())
+		assert.True(t, fired)
+		assert.Equal(t, start.Add(2*time.Second), at, "the first pending tick is kept")
+		_, fired = received(ticker.C())
+		assert.False(t, fired)
+	})
+
+	t.Run("Should not fire stopped tickers and timers", func(t *testing.T) {
+		clk := NewFake(start)
+		ticker := clk.NewTicker(time.Second)
+		timer := clk.NewTimer(time.Second)
+
+		ticker.Stop()
+		assert.True(t, timer.Stop())
+		assert.False(t, timer.Stop())
+
+		clk.Advance(time.Minute)
+		_, tickerFired := received(ticker.C())
+		_, timerFired := received(timer.C())
+		assert.False(t, tickerFired)
+		assert.False(t, timerFired)
+	})
+
+	t.Run("Should fire a reset ticker with the new period", func(t *testing.T) {
+		clk := NewFake(start)
+		ticker := clk.NewTicker(time.Hour)
+
+		ticker.Reset(time.Second)
+		clk.Advance(time.Second)
+
+		_, fired := received(ticker.C())
+		assert.True(t, fired)
+	})
+}
+
+func TestFake_BlockUntilDue(t *testing.T) {
+	t.Run("Should return once a ticker is due within the duration", func(t *testing.T) {
+		clk := NewFake(start)
+		ticker := clk.NewTicker(time.Hour)
+
+		done := make(chan struct{})
+		go func() {
+			clk.BlockUntilDue(time.Second)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("No ticker is due within a second yet")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		ticker.Reset(time.Second)
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Expected BlockUntilDue to return after the reset")
+		}
+	})
+}
@@ -0,0 +1,103 @@
+// Package codec encodes the optional fields of the price updates for the stores and files persisting them.
+package codec
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// Extras holds the fields of a price update other than its pair, price and reception time, which are only set for
+// some updates: the backfilled ones, the synthetic ones and the ones quoted with market data.
+type Extras struct {
+	Backfilled bool   `json:"backfilled,omitempty"`
+	Legs       []Leg  `json:"legs,omitempty"`
+	Quote      *Quote `json:"quote,omitempty"`
+}
+
+type Leg struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type Quote struct {
+	Bid            decimal.NullDecimal `json:"bid"`
+	Ask            decimal.NullDecimal `json:"ask"`
+	Volume24h      decimal.NullDecimal `json:"volume_24h"`
+	QuoteVolume24h decimal.NullDecimal `json:"quote_volume_24h"`
+	High24h        decimal.NullDecimal `json:"high_24h"`
+	Low24h         decimal.NullDecimal `json:"low_24h"`
+	ChangePct24h   decimal.NullDecimal `json:"change_pct_24h"`
+}
+
+// NewExtras returns the extras of an update, or nil when it has none.
+func NewExtras(update domain.PriceUpdate) *Extras {
+	if !update.Backfilled && len(update.Legs) == 0 && update.Quote == nil {
+		return nil
+	}
+
+	extras := &Extras{Backfilled: update.Backfilled}
+
+	for _, leg := range update.Legs {
+		extras.Legs = append(extras.Legs, Leg{From: string(leg.From), To: string(leg.To)})
+	}
+
+	if update.Quote != nil {
+		quote := Quote(*update.Quote)
+		extras.Quote = &quote
+	}
+
+	return extras
+}
+
+// Apply sets the extras on the update. A nil Extras leaves it unchanged.
+func (e *Extras) Apply(update *domain.PriceUpdate) {
+	if e == nil {
+		return
+	}
+
+	update.Backfilled = e.Backfilled
+
+	for _, leg := range e.Legs {
+		update.Legs = append(update.Legs, domain.NewPair(domain.Currency(leg.From), domain.Currency(leg.To)))
+	}
+
+	if e.Quote != nil {
+		quote := domain.Quote(*e.Quote)
+		update.Quote = &quote
+	}
+}
+
+// EncodeExtras returns the JSON encoded extras of an update, or nil when it has none.
+func EncodeExtras(update domain.PriceUpdate) ([]byte, error) {
+	extras := NewExtras(update)
+	if extras == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(extras)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode price update extras")
+	}
+
+	return data, nil
+}
+
+// DecodeExtras sets the JSON encoded extras on the update. Empty data leaves it unchanged.
+func DecodeExtras(data []byte, update *domain.PriceUpdate) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var extras Extras
+	if err := json.Unmarshal(data, &extras); err != nil {
+		return errors.Wrap(err, "failed to decode price update extras")
+	}
+
+	extras.Apply(update)
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package codec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+func TestEncodeExtras(t *testing.T) {
+	base := domain.PriceUpdate{
+		Pair:       domain.NewPair(domain.ETH, domain.BTC),
+		Price:      decimal.RequireFromString("0.05"),
+		ReceivedAt: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("Encodes nothing for the plain updates", func(t *testing.T) {
+		data, err := EncodeExtras(base)
+		require.NoError(t, err)
+		assert.Nil(t, data)
+
+		decoded := base
+		require.NoError(t, DecodeExtras(data, &decoded))
+		assert.Equal(t, base, decoded)
+	})
+
+	t.Run("Round trips the extras", func(t *testing.T) {
+		update := base
+		update.Backfilled = true
+		update.Legs = []domain.Pair{domain.NewPair(domain.ETH, domain.USD), domain.NewPair(domain.BTC, domain.USD)}
+		update.Quote = &domain.Quote{
+			Bid:          decimal.NewNullDecimal(decimal.RequireFromString("0.0499")),
+			Ask:          decimal.NewNullDecimal(decimal.RequireFromString("0.0501")),
+			ChangePct24h: decimal.NewNullDecimal(decimal.RequireFromString("-1.25")),
+		}
+
+		data, err := EncodeExtras(update)
+		require.NoError(t, err)
+
+		decoded := base
+		require.NoError(t, DecodeExtras(data, &decoded))
+		assert.Equal(t, update, decoded)
+		assert.False(t, decoded.Quote.Volume24h.Valid)
+	})
+
+	t.Run("Rejects invalid extras", func(t *testing.T) {
+		decoded := base
+		assert.Error(t, DecodeExtras([]byte(`{"quote":{"bid":"abc"}}`), &decoded))
+	})
+}
@@ -0,0 +1,140 @@
+package coindesk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// MaxHistoryLimit is the maximum number of candles returned by a history request.
+const MaxHistoryLimit = 2000
+
+type historyResponse struct {
+	Response string `json:"Response"`
+	Message  string `json:"Message"`
+	Data     struct {
+		Data []struct {
+			Time  int64       `json:"time"`
+			Close json.Number `json:"close"`
+		} `json:"Data"`
+	} `json:"Data"`
+}
+
+// historyEndpoint maps a candle interval to the history endpoint serving it.
+func historyEndpoint(interval time.Duration) (string, error) {
+	switch interval {
+	case time.Minute:
+		return "histominute", nil
+	case time.Hour:
+		return "histohour", nil
+	default:
+		return "", errors.Errorf("unsupported history interval %s, expected 1m or 1h", interval)
+	}
+}
+
+// GetHistory fetches the last limit candles of the given interval (1m or 1h) of a pair, returning their close prices
+// as backfilled updates received at the candle end, in ascending order. The candle in progress is left out.
+// API documentation: https://developers.coindesk.com/documentation/legacy/Historical/dataHistominute
+func (a PriceAPI) GetHistory(ctx context.Context, pair domain.Pair, interval time.Duration, limit int) ([]domain.PriceUpdate, error) {
+	endpoint, err := historyEndpoint(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []domain.PriceUpdate
+
+	err = a.withRetry(ctx, func() error {
+		var err error
+		updates, err = a.fetchHistory(ctx, pair, endpoint, interval, min(limit, MaxHistoryLimit))
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch history")
+	}
+
+	return updates, nil
+}
+
+func (a PriceAPI) fetchHistory(
+	ctx context.Context,
+	pair domain.Pair,
+	endpoint string,
+	interval time.Duration,
+	limit int,
+) ([]domain.PriceUpdate, error) {
+	url := fmt.Sprintf("%s/%s?fsym=%s&tsym=%s&limit=%d",
+		strings.TrimSuffix(a.config.CoinDeskHistoryAPIURL, "/"),
+		endpoint,
+		pair.From,
+		pair.To,
+		limit)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+
+	if a.config.CoinDeskAPIKey != "" {
+		req.Header.Set("Authorization", "Apikey "+a.config.CoinDeskAPIKey)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute request")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var response historyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response")
+	}
+
+	if response.Response != "Success" {
+		return nil, errors.Errorf("history request failed: %s", response.Message)
+	}
+
+	var (
+		now     = a.clock.Now()
+		updates = make([]domain.PriceUpdate, 0, len(response.Data.Data))
+	)
+
+	for _, candle := range response.Data.Data {
+		closedAt := time.Unix(candle.Time, 0).UTC().Add(interval)
+		if closedAt.After(now) {
+			continue
+		}
+
+		price, err := decimal.NewFromString(candle.Close.String())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to convert price to decimal")
+		}
+
+		// candles without trades, e.g. before the pair was listed, are reported with a zero price
+		if price.IsZero() {
+			continue
+		}
+
+		updates = append(updates, domain.PriceUpdate{
+			Pair:       pair,
+			Price:      price,
+			ReceivedAt: closedAt,
+			Backfilled: true,
+		})
+	}
+
+	return updates, nil
+}
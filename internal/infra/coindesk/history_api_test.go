@@ -0,0 +1,89 @@
+package coindesk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+)
+
+func TestPricingAPI_GetHistory(t *testing.T) {
+	var (
+		btcUsd  = domain.NewPair(domain.BTC, domain.USD)
+		current = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		clk     = clock.NewFake(current.Add(30 * time.Second))
+	)
+
+	newAPI := func(t *testing.T, handler http.HandlerFunc) *PriceAPI {
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+
+		return NewPricingAPI(server.Client(), &config.Config{
+			CoinDeskHistoryAPIURL:    server.URL + "/data/v2",
+			CoinDeskRetryMaxAttempts: 1,
+			CoinDeskRetryInitialWait: 10 * time.Millisecond,
+			CoinDeskRetryMaxWait:     50 * time.Millisecond,
+		}, clk)
+	}
+
+	t.Run("Returns the closed candles as backfilled prices", func(t *testing.T) {
+		api := newAPI(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/data/v2/histominute", r.URL.Path)
+			assert.Equal(t, "BTC", r.URL.Query().Get("fsym"))
+			assert.Equal(t, "USD", r.URL.Query().Get("tsym"))
+			assert.Equal(t, "3", r.URL.Query().Get("limit"))
+
+			_, _ = fmt.Fprintf(w, `{"Response":"Success","Data":{"Data":[
+				{"time":%d,"close":0},
+				{"time":%d,"close":50000.12345678},
+				{"time":%d,"close":50100.5}
+			]}}`, current.Add(-2*time.Minute).Unix(), current.Add(-time.Minute).Unix(), current.Unix())
+		})
+
+		history, err := api.GetHistory(context.Background(), btcUsd, time.Minute, 3)
+		require.NoError(t, err)
+		require.Len(t, history, 1, "Expected candles without trades and in progress to be left out")
+
+		assert.Equal(t, "50000.12345678", history[0].Price.String())
+		assert.True(t, history[0].ReceivedAt.Equal(current))
+		assert.Equal(t, time.UTC, history[0].ReceivedAt.Location(), "Expected the candles closed in UTC")
+		assert.True(t, history[0].Backfilled)
+		assert.Equal(t, btcUsd, history[0].Pair)
+	})
+
+	t.Run("Uses the hourly endpoint", func(t *testing.T) {
+		api := newAPI(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/data/v2/histohour", r.URL.Path)
+			assert.Equal(t, "2000", r.URL.Query().Get("limit"))
+			_, _ = w.Write([]byte(`{"Response":"Success","Data":{"Data":[]}}`))
+		})
+
+		history, err := api.GetHistory(context.Background(), btcUsd, time.Hour, 5000)
+		require.NoError(t, err)
+		assert.Empty(t, history)
+	})
+
+	t.Run("Reports upstream errors", func(t *testing.T) {
+		api := newAPI(t, func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"Response":"Error","Message":"fsym param is invalid"}`))
+		})
+
+		_, err := api.GetHistory(context.Background(), btcUsd, time.Minute, 10)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fsym param is invalid")
+	})
+
+	t.Run("Rejects unsupported intervals", func(t *testing.T) {
+		_, err := NewPricingAPI(http.DefaultClient, &config.Config{}, clk).GetHistory(context.Background(), btcUsd, time.Second, 10)
+		assert.Error(t, err)
+	})
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
 	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
 )
 
 type HTTPClient interface {
@@ -21,12 +22,14 @@ type HTTPClient interface {
 type PriceAPI struct {
 	client HTTPClient
 	config *config.Config
+	clock  clock.Clock
 }
 
-func NewPricingAPI(client HTTPClient, config *config.Config) *PriceAPI {
+func NewPricingAPI(client HTTPClient, config *config.Config, clk clock.Clock) *PriceAPI {
 	return &PriceAPI{
 		client: client,
 		config: config,
+		clock:  clk,
 	}
 }
 
@@ -34,32 +37,45 @@ func NewPricingAPI(client HTTPClient, config *config.Config) *PriceAPI {
 // API documentation: https://developers.coindesk.com/documentation/legacy/Price/SingleSymbolPriceEndpoint/
 func (a PriceAPI) GetPrice(ctx context.Context, pair domain.Pair) (decimal.Decimal, error) {
 	var price decimal.Decimal
+
+	err := a.withRetry(ctx, func() error {
+		var err error
+		price, err = a.fetchPrice(ctx, pair)
+		return err
+	})
+	if err != nil {
+		return decimal.Zero, errors.Wrap(err, "failed to fetch price")
+	}
+
+	return price, nil
+}
+
+// withRetry calls fn until it succeeds, up to the configured max attempts, with exponential backoff between attempts.
+func (a PriceAPI) withRetry(ctx context.Context, fn func() error) error {
 	var err error
 
-	// Retry mechanism with exponential backoff
 	for attempt := 0; attempt < a.config.CoinDeskRetryMaxAttempts; attempt++ {
-		price, err = a.fetchPrice(ctx, pair)
-		if err == nil {
-			return price, nil
+		if err = fn(); err == nil {
+			return nil
 		}
 
 		if attempt == a.config.CoinDeskRetryMaxAttempts-1 {
-			return decimal.Zero, errors.Wrap(err, "failed to fetch price after max retry attempts")
+			return errors.Wrap(err, "max retry attempts reached")
 		}
 
 		backoffDuration := a.calculateBackoff(attempt)
 
-		timer := time.NewTimer(backoffDuration)
+		timer := a.clock.NewTimer(backoffDuration)
 		select {
 		case <-ctx.Done():
 			timer.Stop()
-			return decimal.Zero, ctx.Err()
-		case <-timer.C:
+			return ctx.Err()
+		case <-timer.C():
 			// Timer expired, continue to next attempt
 		}
 	}
 
-	return decimal.Zero, errors.New("failed to fetch price")
+	return errors.New("no attempt made")
 }
 
 func (a PriceAPI) fetchPrice(ctx context.Context, pair domain.Pair) (decimal.Decimal, error) {
@@ -73,6 +89,10 @@ func (a PriceAPI) fetchPrice(ctx context.Context, pair domain.Pair) (decimal.Dec
 		return decimal.Zero, errors.Wrap(err, "failed to create request")
 	}
 
+	if a.config.CoinDeskAPIKey != "" {
+		req.Header.Set("Authorization", "Apikey "+a.config.CoinDeskAPIKey)
+	}
+
 	resp, err := a.client.Do(req)
 	if err != nil {
 		return decimal.Zero, errors.Wrap(err, "failed to execute request")
@@ -15,6 +15,7 @@ import (
 
 	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
 	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
 )
 
 func TestPriceAPI_Integration(t *testing.T) {
@@ -31,7 +32,7 @@ func TestPriceAPI_Integration(t *testing.T) {
 		Timeout: cfg.CoinDeskClientTimeout,
 	}
 
-	priceAPI := NewPricingAPI(httpClient, cfg)
+	priceAPI := NewPricingAPI(httpClient, cfg, clock.New())
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 
 	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
 	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
 )
 
 func TestPricingAPI_GetPrice(t *testing.T) {
@@ -20,8 +22,9 @@ func TestPricingAPI_GetPrice(t *testing.T) {
 		pair           domain.Pair
 		serverResponse string
 		serverStatus   interface{} // Can be int or []int for retry tests
-		serverDelay    time.Duration
+		cancelRequest  bool
 		maxAttempts    int
+		backoffs       int
 		expectedPrice  string
 		expectError    bool
 	}{
@@ -40,6 +43,7 @@ func TestPricingAPI_GetPrice(t *testing.T) {
 			serverResponse: `{"USD": 50000.25}`,
 			serverStatus:   []int{http.StatusInternalServerError, http.StatusOK},
 			maxAttempts:    3,
+			backoffs:       1,
 			expectedPrice:  "50000.25",
 			expectError:    false,
 		},
@@ -48,6 +52,7 @@ func TestPricingAPI_GetPrice(t *testing.T) {
 			pair:          domain.NewPair(domain.BTC, domain.USD),
 			serverStatus:  http.StatusInternalServerError,
 			maxAttempts:   2,
+			backoffs:      1,
 			expectedPrice: "0",
 			expectError:   true,
 		},
@@ -74,7 +79,7 @@ func TestPricingAPI_GetPrice(t *testing.T) {
 			pair:           domain.NewPair(domain.BTC, domain.USD),
 			serverResponse: `{"USD": 50000.25}`,
 			serverStatus:   http.StatusOK,
-			serverDelay:    100 * time.Millisecond,
+			cancelRequest:  true,
 			maxAttempts:    3,
 			expectedPrice:  "0",
 			expectError:    true,
@@ -83,6 +88,9 @@ func TestPricingAPI_GetPrice(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
 			testAttempt := 0
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				assert.Equal(t, http.MethodGet, r.Method)
@@ -90,8 +98,11 @@ func TestPricingAPI_GetPrice(t *testing.T) {
 				assert.Equal(t, string(tt.pair.From), r.URL.Query().Get("fsym"))
 				assert.Equal(t, string(tt.pair.To), r.URL.Query().Get("tsyms"))
 
-				if tt.serverDelay > 0 {
-					time.Sleep(tt.serverDelay)
+				// the request is cancelled while in flight
+				if tt.cancelRequest {
+					cancel()
+					<-r.Context().Done()
+					return
 				}
 
 				var status int
@@ -120,26 +131,97 @@ func TestPricingAPI_GetPrice(t *testing.T) {
 				CoinDeskRetryMaxWait:     50 * time.Millisecond,
 			}
 
-			api := NewPricingAPI(server.Client(), cfg)
+			clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+			api := NewPricingAPI(server.Client(), cfg, clk)
 
-			ctx := context.Background()
-			if tt.name == "context cancellation" {
-				var cancel context.CancelFunc
-				ctx, cancel = context.WithTimeout(ctx, 50*time.Millisecond)
-				defer cancel()
+			type result struct {
+				price decimal.Decimal
+				err   error
 			}
+			done := make(chan result, 1)
+
+			go func() {
+				price, err := api.GetPrice(ctx, tt.pair)
+				done <- result{price: price, err: err}
+			}()
 
-			price, err := api.GetPrice(ctx, tt.pair)
+			for range tt.backoffs {
+				clk.BlockUntilDue(cfg.CoinDeskRetryMaxWait)
+				clk.Advance(cfg.CoinDeskRetryMaxWait)
+			}
+
+			var res result
+			select {
+			case res = <-done:
+			case <-time.After(time.Second):
+				t.Fatal("Expected the price request to end")
+			}
 
 			if tt.expectError {
-				assert.Error(t, err)
+				assert.Error(t, res.err)
 			} else {
-				assert.NoError(t, err)
+				assert.NoError(t, res.err)
 			}
 
+			price := res.price
 			expectedPrice, err := decimal.NewFromString(tt.expectedPrice)
 			assert.NoError(t, err)
 			assert.True(t, expectedPrice.Equal(price), "Expected price %s, got %s", expectedPrice, price)
 		})
 	}
 }
+
+func TestPricingAPI_Backoff(t *testing.T) {
+	var (
+		pair     = domain.NewPair(domain.BTC, domain.USD)
+		clk      = clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		attempts atomic.Int32
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"USD": 50000.25}`))
+	}))
+	defer server.Close()
+
+	api := NewPricingAPI(server.Client(), &config.Config{
+		CoinDeskAPIURL:           server.URL + "/data/price",
+		CoinDeskRetryMaxAttempts: 3,
+		CoinDeskRetryInitialWait: time.Second,
+		CoinDeskRetryMaxWait:     time.Minute,
+	}, clk)
+
+	type result struct {
+		price decimal.Decimal
+		err   error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		price, err := api.GetPrice(context.Background(), pair)
+		done <- result{price: price, err: err}
+	}()
+
+	// the first retry waits for the initial wait
+	clk.BlockUntilDue(time.Second)
+	assert.Equal(t, int32(1), attempts.Load())
+
+	// the second one for twice as long
+	clk.Advance(time.Second)
+	clk.BlockUntilDue(2 * time.Second)
+	assert.Equal(t, int32(2), attempts.Load())
+
+	clk.Advance(2 * time.Second)
+
+	select {
+	case res := <-done:
+		assert.NoError(t, res.err)
+		assert.Equal(t, "50000.25", res.price.String())
+		assert.Equal(t, int32(3), attempts.Load())
+	case <-time.After(time.Second):
+		t.Fatal("Expected the price after the retries")
+	}
+}
@@ -0,0 +1,76 @@
+package coindesk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/fake_upstream"
+)
+
+// TestPriceAPI_FakeUpstream exercises the adapter and its retries against the upstream behaviours, offline.
+func TestPriceAPI_FakeUpstream(t *testing.T) {
+	var (
+		btcUsd   = domain.NewPair(domain.BTC, domain.USD)
+		upstream = fake_upstream.New()
+		server   = httptest.NewServer(upstream)
+	)
+	t.Cleanup(server.Close)
+
+	upstream.SetPrice(btcUsd, decimal.RequireFromString("50000.12345678"))
+
+	cfg := &config.Config{
+		CoinDeskAPIURL:           server.URL + "/data/price",
+		CoinDeskHistoryAPIURL:    server.URL + "/data/v2",
+		CoinDeskRetryMaxAttempts: 3,
+		CoinDeskClientTimeout:    100 * time.Millisecond,
+		CoinDeskRetryInitialWait: time.Millisecond,
+		CoinDeskRetryMaxWait:     5 * time.Millisecond,
+	}
+	api := NewPricingAPI(&http.Client{Timeout: cfg.CoinDeskClientTimeout}, cfg, clock.New())
+
+	tests := []struct {
+		name        string
+		behaviours  []fake_upstream.Behaviour
+		expectError bool
+	}{
+		{name: "normal answer"},
+		{name: "rate limited once", behaviours: []fake_upstream.Behaviour{fake_upstream.RateLimited()}},
+		{name: "server errors burst", behaviours: fake_upstream.ServerErrors(http.StatusServiceUnavailable, 2)},
+		{name: "malformed json", behaviours: []fake_upstream.Behaviour{fake_upstream.MalformedJSON()}},
+		{name: "error body with status 200", behaviours: []fake_upstream.Behaviour{fake_upstream.ErrorBody("rate limit")}},
+		{name: "timeout", behaviours: []fake_upstream.Behaviour{fake_upstream.Slow(time.Second)}},
+		{name: "server errors beyond the retries", behaviours: fake_upstream.ServerErrors(http.StatusBadGateway, 3), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream.Enqueue(tt.behaviours...)
+
+			price, err := api.GetPrice(context.Background(), btcUsd)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "50000.12345678", price.String())
+		})
+	}
+
+	t.Run("history", func(t *testing.T) {
+		history, err := api.GetHistory(context.Background(), btcUsd, time.Minute, 10)
+		require.NoError(t, err)
+		require.Len(t, history, 10)
+		assert.True(t, history[9].Backfilled)
+	})
+}
@@ -0,0 +1,130 @@
+package coindesk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// quoteFields maps the fields of the full price endpoint to the quote values they populate.
+var quoteFields = map[string]func(quote *domain.Quote) *decimal.NullDecimal{
+	"BID":             func(q *domain.Quote) *decimal.NullDecimal { return &q.Bid },
+	"ASK":             func(q *domain.Quote) *decimal.NullDecimal { return &q.Ask },
+	"VOLUME24HOUR":    func(q *domain.Quote) *decimal.NullDecimal { return &q.Volume24h },
+	"VOLUME24HOURTO":  func(q *domain.Quote) *decimal.NullDecimal { return &q.QuoteVolume24h },
+	"HIGH24HOUR":      func(q *domain.Quote) *decimal.NullDecimal { return &q.High24h },
+	"LOW24HOUR":       func(q *domain.Quote) *decimal.NullDecimal { return &q.Low24h },
+	"CHANGEPCT24HOUR": func(q *domain.Quote) *decimal.NullDecimal { return &q.ChangePct24h },
+}
+
+type fullPriceResponse struct {
+	Response string                                           `json:"Response"`
+	Message  string                                           `json:"Message"`
+	Raw      map[string]map[string]map[string]json.RawMessage `json:"RAW"`
+}
+
+// GetQuote fetches the price of a given currency pair from the CoinDesk API, along with its market data.
+// The fields the upstream does not report for the pair, e.g. the bid and ask of most aggregated markets, are left
+// invalid in the quote.
+// API documentation: https://developers.coindesk.com/documentation/legacy/Price/multipleSymbolsFullPriceEndpoint/
+func (a PriceAPI) GetQuote(ctx context.Context, pair domain.Pair) (decimal.Decimal, domain.Quote, error) {
+	var (
+		price decimal.Decimal
+		quote domain.Quote
+	)
+
+	err := a.withRetry(ctx, func() error {
+		var err error
+		price, quote, err = a.fetchQuote(ctx, pair)
+		return err
+	})
+	if err != nil {
+		return decimal.Zero, domain.Quote{}, errors.Wrap(err, "failed to fetch quote")
+	}
+
+	return price, quote, nil
+}
+
+func (a PriceAPI) fetchQuote(ctx context.Context, pair domain.Pair) (decimal.Decimal, domain.Quote, error) {
+	url := fmt.Sprintf("%s?fsyms=%s&tsyms=%s",
+		a.config.CoinDeskFullAPIURL,
+		pair.From,
+		pair.To)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return decimal.Zero, domain.Quote{}, errors.Wrap(err, "failed to create request")
+	}
+
+	if a.config.CoinDeskAPIKey != "" {
+		req.Header.Set("Authorization", "Apikey "+a.config.CoinDeskAPIKey)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return decimal.Zero, domain.Quote{}, errors.Wrap(err, "failed to execute request")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return decimal.Zero, domain.Quote{}, errors.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var response fullPriceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return decimal.Zero, domain.Quote{}, errors.Wrap(err, "failed to decode response")
+	}
+
+	if response.Response == "Error" {
+		return decimal.Zero, domain.Quote{}, errors.Errorf("upstream error: %s", response.Message)
+	}
+
+	fields, ok := response.Raw[string(pair.From)][string(pair.To)]
+	if !ok {
+		return decimal.Zero, domain.Quote{}, errors.Errorf("quote for %s not found in response", pair)
+	}
+
+	price, ok, err := decimalField(fields, "PRICE")
+	if err != nil {
+		return decimal.Zero, domain.Quote{}, err
+	}
+	if !ok {
+		return decimal.Zero, domain.Quote{}, errors.Errorf("price for %s not found in response", pair)
+	}
+
+	var quote domain.Quote
+	for name, field := range quoteFields {
+		value, ok, err := decimalField(fields, name)
+		if err != nil {
+			return decimal.Zero, domain.Quote{}, err
+		}
+		if ok {
+			*field(&quote) = decimal.NewNullDecimal(value)
+		}
+	}
+
+	return price, quote, nil
+}
+
+// decimalField returns the numeric field of a quote, and whether the upstream reported it.
+func decimalField(fields map[string]json.RawMessage, name string) (decimal.Decimal, bool, error) {
+	raw, ok := fields[name]
+	if !ok || string(raw) == "null" {
+		return decimal.Zero, false, nil
+	}
+
+	var value decimal.Decimal
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return decimal.Zero, false, errors.Wrapf(err, "failed to convert %s to decimal", name)
+	}
+
+	return value, true, nil
+}
@@ -0,0 +1,87 @@
+package coindesk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/app/config"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+)
+
+func TestPricingAPI_GetQuote(t *testing.T) {
+	pair := domain.NewPair(domain.BTC, domain.USD)
+
+	newAPI := func(t *testing.T, response string) *PriceAPI {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/data/pricemultifull", r.URL.Path)
+			assert.Equal(t, "BTC", r.URL.Query().Get("fsyms"))
+			assert.Equal(t, "USD", r.URL.Query().Get("tsyms"))
+
+			_, _ = w.Write([]byte(response))
+		}))
+		t.Cleanup(server.Close)
+
+		return NewPricingAPI(server.Client(), &config.Config{
+			CoinDeskFullAPIURL:       server.URL + "/data/pricemultifull",
+			CoinDeskRetryMaxAttempts: 1,
+			CoinDeskRetryInitialWait: 10 * time.Millisecond,
+			CoinDeskRetryMaxWait:     10 * time.Millisecond,
+		}, clock.New())
+	}
+
+	t.Run("Returns the price along with its quote", func(t *testing.T) {
+		api := newAPI(t, `{"RAW":{"BTC":{"USD":{
+			"MARKET":"CCCAGG",
+			"PRICE":50000.25,
+			"VOLUME24HOUR":1234.5,
+			"VOLUME24HOURTO":61725000,
+			"HIGH24HOUR":51000,
+			"LOW24HOUR":49000.75,
+			"CHANGEPCT24HOUR":-1.25
+		}}},"DISPLAY":{}}`)
+
+		price, quote, err := api.GetQuote(context.Background(), pair)
+		require.NoError(t, err)
+
+		assert.Equal(t, "50000.25", price.String())
+		assert.Equal(t, "1234.5", quote.Volume24h.Decimal.String())
+		assert.Equal(t, "61725000", quote.QuoteVolume24h.Decimal.String())
+		assert.Equal(t, "51000", quote.High24h.Decimal.String())
+		assert.Equal(t, "49000.75", quote.Low24h.Decimal.String())
+		assert.Equal(t, "-1.25", quote.ChangePct24h.Decimal.String())
+		assert.False(t, quote.Bid.Valid, "Expected no bid when not reported")
+		assert.False(t, quote.Ask.Valid, "Expected no ask when not reported")
+	})
+
+	t.Run("Returns the bid and ask when reported", func(t *testing.T) {
+		api := newAPI(t, `{"RAW":{"BTC":{"USD":{"PRICE":50000,"BID":49999.5,"ASK":"50000.5"}}}}`)
+
+		_, quote, err := api.GetQuote(context.Background(), pair)
+		require.NoError(t, err)
+
+		assert.Equal(t, "49999.5", quote.Bid.Decimal.String())
+		assert.Equal(t, "50000.5", quote.Ask.Decimal.String())
+		assert.Equal(t, "1", quote.Spread().Decimal.String())
+	})
+
+	t.Run("Fails on upstream errors and missing prices", func(t *testing.T) {
+		responses := map[string]string{
+			"upstream error": `{"Response":"Error","Message":"cccagg_or_exchange market does not exist for this coin pair"}`,
+			"missing pair":   `{"RAW":{"ETH":{"USD":{"PRICE":3000}}}}`,
+			"missing price":  `{"RAW":{"BTC":{"USD":{"VOLUME24HOUR":1234.5}}}}`,
+			"invalid value":  `{"RAW":{"BTC":{"USD":{"PRICE":50000,"BID":"n/a"}}}}`,
+		}
+
+		for name, response := range responses {
+			_, _, err := newAPI(t, response).GetQuote(context.Background(), pair)
+			assert.Error(t, err, name)
+		}
+	})
+}
@@ -3,35 +3,73 @@ package event_provider
 import (
 	"context"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/shopspring/decimal"
 
 	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
 )
 
 type PriceAPI interface {
 	GetPrice(ctx context.Context, pair domain.Pair) (decimal.Decimal, error)
 }
 
+// QuoteAPI fetches the prices along with their market data.
+type QuoteAPI interface {
+	GetQuote(ctx context.Context, pair domain.Pair) (decimal.Decimal, domain.Quote, error)
+}
+
+// fetchFunc fetches the price of a pair, and its quote when the API reports it.
+type fetchFunc func(ctx context.Context, pair domain.Pair) (decimal.Decimal, *domain.Quote, error)
+
 type HTTPPulling struct {
-	log          *slog.Logger
-	priceAPI     PriceAPI
-	pullInterval time.Duration
-	bufferSize   int
+	mu              sync.RWMutex
+	log             *slog.Logger
+	clock           clock.Clock
+	fetch           fetchFunc
+	pullInterval    time.Duration
+	intervalChanged chan struct{}
+	bufferSize      int
+}
+
+func NewHTTPPulling(priceAPI PriceAPI, pullInterval time.Duration, bufferSize int, clk clock.Clock) *HTTPPulling {
+	fetch := func(ctx context.Context, pair domain.Pair) (decimal.Decimal, *domain.Quote, error) {
+		price, err := priceAPI.GetPrice(ctx, pair)
+		return price, nil, err
+	}
+
+	return newHTTPPulling(fetch, pullInterval, bufferSize, clk)
+}
+
+// NewHTTPQuotePulling pulls the prices along with their quotes, at a higher cost per request than the prices only.
+func NewHTTPQuotePulling(quoteAPI QuoteAPI, pullInterval time.Duration, bufferSize int, clk clock.Clock) *HTTPPulling {
+	fetch := func(ctx context.Context, pair domain.Pair) (decimal.Decimal, *domain.Quote, error) {
+		price, quote, err := quoteAPI.GetQuote(ctx, pair)
+		if err != nil {
+			return decimal.Zero, nil, err
+		}
+		return price, &quote, nil
+	}
+
+	return newHTTPPulling(fetch, pullInterval, bufferSize, clk)
 }
 
-func NewHTTPPulling(priceAPI PriceAPI, pullInterval time.Duration, bufferSize int) *HTTPPulling {
+func newHTTPPulling(fetch fetchFunc, pullInterval time.Duration, bufferSize int, clk clock.Clock) *HTTPPulling {
 	return &HTTPPulling{
-		log:          slog.Default(),
-		priceAPI:     priceAPI,
-		pullInterval: pullInterval,
-		bufferSize:   bufferSize,
+		log:             slog.Default(),
+		clock:           clk,
+		fetch:           fetch,
+		pullInterval:    pullInterval,
+		intervalChanged: make(chan struct{}),
+		bufferSize:      bufferSize,
 	}
 }
 
 func (p *HTTPPulling) Start(ctx context.Context, pair domain.Pair) (<-chan domain.PriceUpdate, error) {
-	ticker := time.NewTicker(p.pullInterval)
+	interval, intervalChanged := p.interval()
+	ticker := p.clock.NewTicker(interval)
 
 	ch := make(chan domain.PriceUpdate, p.bufferSize)
 
@@ -46,17 +84,26 @@ func (p *HTTPPulling) Start(ctx context.Context, pair domain.Pair) (<-chan domai
 			case <-ctx.Done():
 				return
 
-			case <-ticker.C:
-				price, err := p.priceAPI.GetPrice(ctx, pair)
+			case <-intervalChanged:
+				interval, intervalChanged = p.interval()
+				ticker.Reset(interval)
+
+			case <-ticker.C():
+				price, quote, err := p.fetch(ctx, pair)
 				if err != nil {
 					p.log.Error("Error getting price", "error", err.Error())
 					continue
 				}
 
-				ch <- domain.PriceUpdate{
+				select {
+				case ch <- domain.PriceUpdate{
 					Pair:       pair,
 					Price:      price,
-					ReceivedAt: time.Now().UTC(),
+					ReceivedAt: p.clock.Now().UTC(),
+					Quote:      quote,
+				}:
+				case <-ctx.Done():
+					return
 				}
 			}
 		}
@@ -64,3 +111,22 @@ func (p *HTTPPulling) Start(ctx context.Context, pair domain.Pair) (<-chan domai
 
 	return ch, nil
 }
+
+// SetInterval changes the pulling interval of every running pair, taking effect immediately.
+func (p *HTTPPulling) SetInterval(interval time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pullInterval = interval
+
+	// closing the channel notifies every running loop; a new one is created for the next change
+	close(p.intervalChanged)
+	p.intervalChanged = make(chan struct{})
+}
+
+func (p *HTTPPulling) interval() (time.Duration, <-chan struct{}) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.pullInterval, p.intervalChanged
+}
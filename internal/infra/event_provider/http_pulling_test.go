@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
 )
 
 type MockPriceAPI struct {
@@ -21,16 +22,26 @@ func (m *MockPriceAPI) GetPrice(ctx context.Context, pair domain.Pair) (decimal.
 	return args.Get(0).(decimal.Decimal), args.Error(1)
 }
 
+type MockQuoteAPI struct {
+	mock.Mock
+}
+
+func (m *MockQuoteAPI) GetQuote(ctx context.Context, pair domain.Pair) (decimal.Decimal, domain.Quote, error) {
+	args := m.Called(ctx, pair)
+	return args.Get(0).(decimal.Decimal), args.Get(1).(domain.Quote), args.Error(2)
+}
+
 func TestHTTPPulling_Start(t *testing.T) {
 	t.Run("Should pull prices at regular intervals", func(t *testing.T) {
 		var (
 			mockAPI      = new(MockPriceAPI)
+			clk          = clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
 			pullInterval = 50 * time.Millisecond
 			bufferSize   = 10
-			puller       = NewHTTPPulling(mockAPI, pullInterval, bufferSize)
+			puller       = NewHTTPPulling(mockAPI, pullInterval, bufferSize, clk)
 		)
 
-		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
 		pair := domain.NewPair(domain.BTC, domain.USD)
@@ -43,17 +54,67 @@ func TestHTTPPulling_Start(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NotNil(t, ch)
 
-		var updates []domain.PriceUpdate
-		for update := range ch {
-			updates = append(updates, update)
-		}
-		assert.GreaterOrEqual(t, len(updates), 3, "Expected at least 3 price updates")
+		for i := 0; i < 3; i++ {
+			clk.Advance(pullInterval)
 
-		for _, update := range updates {
+			update := <-ch
 			assert.Equal(t, pair, update.Pair)
 			assert.True(t, price.Equal(update.Price))
+			assert.Equal(t, clk.Now(), update.ReceivedAt, "Expected the update to be stamped with the clock time")
 		}
 
-		mockAPI.AssertExpectations(t)
+		mockAPI.AssertNumberOfCalls(t, "GetPrice", 3)
+	})
+
+	t.Run("Should pull the quotes along with the prices", func(t *testing.T) {
+		var (
+			mockAPI = new(MockQuoteAPI)
+			clk     = clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+			puller  = NewHTTPQuotePulling(mockAPI, time.Second, 10, clk)
+			pair    = domain.NewPair(domain.BTC, domain.USD)
+			quote   = domain.Quote{Volume24h: decimal.NewNullDecimal(decimal.NewFromInt(1200))}
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mockAPI.On("GetQuote", mock.Anything, pair).Return(decimal.NewFromInt(50000), quote, nil)
+
+		ch, err := puller.Start(ctx, pair)
+		assert.NoError(t, err)
+
+		clk.Advance(time.Second)
+
+		update := <-ch
+		assert.Equal(t, "50000", update.Price.String())
+		if assert.NotNil(t, update.Quote) {
+			assert.Equal(t, quote, *update.Quote)
+		}
 	})
 }
+
+func TestHTTPPulling_SetInterval(t *testing.T) {
+	var (
+		mockAPI = new(MockPriceAPI)
+		clk     = clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		puller  = NewHTTPPulling(mockAPI, time.Hour, 10, clk)
+		pair    = domain.NewPair(domain.BTC, domain.USD)
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockAPI.On("GetPrice", mock.Anything, pair).Return(decimal.NewFromFloat(50000.0), nil)
+
+	ch, err := puller.Start(ctx, pair)
+	assert.NoError(t, err)
+
+	puller.SetInterval(10 * time.Millisecond)
+
+	// waits for the loop to reset its ticker, far before the initial interval
+	clk.BlockUntilDue(10 * time.Millisecond)
+	clk.Advance(10 * time.Millisecond)
+
+	update := <-ch
+	assert.Equal(t, pair, update.Pair)
+}
@@ -0,0 +1,126 @@
+package event_provider
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// the recordings, and their directories, are restricted to their owner
+const (
+	recordingDirPermission  = 0o750
+	recordingFilePermission = 0o600
+)
+
+type Provider interface {
+	Start(ctx context.Context, pair domain.Pair) (<-chan domain.PriceUpdate, error)
+}
+
+// Recorder tees the updates of a provider to a recording file, to be played back by Replay.
+type Recorder struct {
+	mu       sync.Mutex
+	log      *slog.Logger
+	provider Provider
+	file     *os.File
+	writer   *bufio.Writer
+	encoder  *json.Encoder
+}
+
+// NewRecorder appends the updates of the provider to the recording file at path, creating it when missing.
+func NewRecorder(provider Provider, path string) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), recordingDirPermission); err != nil {
+		return nil, errors.Wrap(err, "failed to create recording directory")
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, recordingFilePermission)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open recording file")
+	}
+
+	writer := bufio.NewWriter(file)
+
+	return &Recorder{
+		log:      slog.Default(),
+		provider: provider,
+		file:     file,
+		writer:   writer,
+		encoder:  json.NewEncoder(writer),
+	}, nil
+}
+
+func (r *Recorder) Start(ctx context.Context, pair domain.Pair) (<-chan domain.PriceUpdate, error) {
+	updates, err := r.provider.Start(ctx, pair)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan domain.PriceUpdate, cap(updates))
+
+	go func() {
+		defer close(ch)
+
+		for update := range updates {
+			if err := r.record(update); err != nil {
+				r.log.Error("Failed to record price update", "pair", pair.String(), "error", err.Error())
+			}
+
+			select {
+			case ch <- update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// SetInterval changes the interval of the recorded provider, when it supports it.
+func (r *Recorder) SetInterval(interval time.Duration) {
+	if provider, ok := r.provider.(interface{ SetInterval(time.Duration) }); ok {
+		provider.SetInterval(interval)
+	}
+}
+
+// Close writes the buffered updates and closes the recording file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.writer.Flush()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	r.file = nil
+
+	return errors.Wrap(err, "failed to close recording file")
+}
+
+// record writes every update through, so that a recording is complete up to a crash.
+func (r *Recorder) record(update domain.PriceUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return errors.New("recorder is closed")
+	}
+
+	if err := r.encoder.Encode(newRecordedUpdate(update)); err != nil {
+		return err
+	}
+
+	return r.writer.Flush()
+}
@@ -0,0 +1,89 @@
+package event_provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// staticProvider emits the given updates of the started pair, then closes the channel.
+type staticProvider []domain.PriceUpdate
+
+func (p staticProvider) Start(_ context.Context, pair domain.Pair) (<-chan domain.PriceUpdate, error) {
+	ch := make(chan domain.PriceUpdate, len(p))
+	for _, update := range p {
+		if update.Pair == pair {
+			ch <- update
+		}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func newRecordedUpdates(start time.Time) []domain.PriceUpdate {
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		ethUsd = domain.NewPair(domain.ETH, domain.USD)
+	)
+
+	return []domain.PriceUpdate{
+		{Pair: btcUsd, Price: decimal.RequireFromString("50000.10"), ReceivedAt: start},
+		{
+			Pair:       ethUsd,
+			Price:      decimal.RequireFromString("3000.5"),
+			ReceivedAt: start.Add(100 * time.Millisecond),
+			Quote:      &domain.Quote{Bid: decimal.NewNullDecimal(decimal.RequireFromString("3000.4"))},
+		},
+		{Pair: btcUsd, Price: decimal.RequireFromString("50001.123456789"), ReceivedAt: start.Add(200 * time.Millisecond), Backfilled: true},
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	var (
+		path    = filepath.Join(t.TempDir(), "recordings", "prices.ndjson")
+		updates = newRecordedUpdates(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC))
+	)
+
+	recorder, err := NewRecorder(staticProvider(updates), path)
+	require.NoError(t, err)
+
+	for _, pair := range []domain.Pair{updates[0].Pair, updates[1].Pair} {
+		ch, err := recorder.Start(context.Background(), pair)
+		require.NoError(t, err)
+
+		for update := range ch {
+			assert.Equal(t, pair, update.Pair, "Expected the updates to be forwarded")
+		}
+	}
+	require.NoError(t, recorder.Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "Expected the recording readable by its owner only")
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() {
+		_ = file.Close()
+	}()
+
+	recorded, err := ReadRecording(file)
+	require.NoError(t, err)
+	require.Len(t, recorded, 3)
+
+	for i, update := range recorded {
+		assert.Equal(t, updates[i].Pair, update.Pair)
+		assert.True(t, updates[i].Price.Equal(update.Price))
+		assert.True(t, updates[i].ReceivedAt.Equal(update.ReceivedAt))
+		assert.Equal(t, updates[i].Backfilled, update.Backfilled)
+		assert.Equal(t, updates[i].Quote != nil, update.Quote != nil, "Expected the quote to be recorded")
+	}
+}
@@ -0,0 +1,79 @@
+package event_provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/codec"
+)
+
+// recordedUpdate is a line of a recording, which holds one JSON encoded price update per line.
+type recordedUpdate struct {
+	Pair       string        `json:"pair"`
+	Price      string        `json:"price"`
+	ReceivedAt time.Time     `json:"received_at"`
+	Extras     *codec.Extras `json:"extras,omitempty"`
+}
+
+func newRecordedUpdate(update domain.PriceUpdate) recordedUpdate {
+	return recordedUpdate{
+		Pair:       update.Pair.String(),
+		Price:      update.Price.String(),
+		ReceivedAt: update.ReceivedAt.UTC(),
+		Extras:     codec.NewExtras(update),
+	}
+}
+
+// ReadRecording decodes the updates of a recording, sorted by reception time.
+func ReadRecording(r io.Reader) ([]domain.PriceUpdate, error) {
+	var (
+		updates []domain.PriceUpdate
+		scanner = bufio.NewScanner(r)
+		line    int
+	)
+
+	for scanner.Scan() {
+		line++
+
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var recorded recordedUpdate
+		if err := json.Unmarshal(scanner.Bytes(), &recorded); err != nil {
+			return nil, errors.Wrapf(err, "invalid recording line %d", line)
+		}
+
+		pair, err := domain.NewPairFromString(recorded.Pair)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pair at recording line %d", line)
+		}
+
+		price, err := decimal.NewFromString(recorded.Price)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid price at recording line %d", line)
+		}
+
+		update := domain.PriceUpdate{Pair: pair, Price: price, ReceivedAt: recorded.ReceivedAt}
+		recorded.Extras.Apply(&update)
+
+		updates = append(updates, update)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read recording")
+	}
+
+	sort.SliceStable(updates, func(i, j int) bool {
+		return updates[i].ReceivedAt.Before(updates[j].ReceivedAt)
+	})
+
+	return updates, nil
+}
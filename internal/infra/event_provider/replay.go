@@ -0,0 +1,193 @@
+package event_provider
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+)
+
+type ReplayOptions struct {
+	// Speed scales the original pace of the recording, e.g. 2 plays it twice as fast. Ignored when stepwise.
+	Speed float64
+	// Stepwise holds every update until released by Step, in the recording order.
+	Stepwise bool
+	// KeepTimestamps emits the updates with their recorded reception times, instead of the time they are replayed.
+	KeepTimestamps bool
+}
+
+// Replay plays a recording back, as an offline replacement of the live provider. The pace of every pair is
+// relative to the first Start, so the pairs are played back in the recorded order. Each pair's channel is closed
+// once its recorded updates are played.
+type Replay struct {
+	mu         sync.Mutex
+	log        *slog.Logger
+	opts       ReplayOptions
+	updates    []domain.PriceUpdate
+	bufferSize int
+	clock      clock.Clock
+	origin     time.Time
+	started    map[domain.Pair]struct{}
+	position   int
+	stepped    chan struct{}
+}
+
+// NewReplayFromFile loads the recording at path, as written by Recorder.
+func NewReplayFromFile(path string, opts ReplayOptions, bufferSize int, clk clock.Clock) (*Replay, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open recording file")
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	updates, err := ReadRecording(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReplay(updates, opts, bufferSize, clk), nil
+}
+
+// NewReplay plays back the given updates, which must be sorted by reception time.
+func NewReplay(updates []domain.PriceUpdate, opts ReplayOptions, bufferSize int, clk clock.Clock) *Replay {
+	if opts.Speed <= 0 {
+		opts.Speed = 1
+	}
+
+	return &Replay{
+		log:        slog.Default(),
+		opts:       opts,
+		updates:    updates,
+		bufferSize: bufferSize,
+		clock:      clk,
+		started:    make(map[domain.Pair]struct{}),
+		stepped:    make(chan struct{}),
+	}
+}
+
+func (r *Replay) Start(ctx context.Context, pair domain.Pair) (<-chan domain.PriceUpdate, error) {
+	origin := r.start(pair)
+
+	ch := make(chan domain.PriceUpdate, r.bufferSize)
+
+	go func() {
+		defer close(ch)
+
+		for i, update := range r.updates {
+			if update.Pair != pair {
+				continue
+			}
+
+			if !r.wait(ctx, i, origin) {
+				return
+			}
+
+			if !r.opts.KeepTimestamps {
+				update.ReceivedAt = r.clock.Now().UTC()
+			}
+
+			select {
+			case ch <- update:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		r.log.Info("Replay of pair finished", "pair", pair.String())
+	}()
+
+	return ch, nil
+}
+
+// Step releases the next n updates of the started pairs in a stepwise replay, returning how many of them are left.
+// The updates of the pairs not started are passed over.
+func (r *Replay) Step(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for released := 0; released < n && r.position < len(r.updates); r.position++ {
+		if _, ok := r.started[r.updates[r.position].Pair]; ok {
+			released++
+		}
+	}
+
+	// closing the channel notifies every waiting pair; a new one is created for the next step
+	close(r.stepped)
+	r.stepped = make(chan struct{})
+
+	left := 0
+	for _, update := range r.updates[r.position:] {
+		if _, ok := r.started[update.Pair]; ok {
+			left++
+		}
+	}
+
+	return left
+}
+
+// Stepwise tells whether the updates are released by Step.
+func (r *Replay) Stepwise() bool {
+	return r.opts.Stepwise
+}
+
+func (r *Replay) start(pair domain.Pair) time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.started[pair] = struct{}{}
+
+	if r.origin.IsZero() {
+		r.origin = r.clock.Now()
+	}
+
+	return r.origin
+}
+
+// wait blocks until the update at index i of the recording is due, returning false if the context is done first.
+func (r *Replay) wait(ctx context.Context, i int, origin time.Time) bool {
+	if r.opts.Stepwise {
+		for {
+			released, stepped := r.released(i)
+			if released {
+				return true
+			}
+
+			select {
+			case <-stepped:
+			case <-ctx.Done():
+				return false
+			}
+		}
+	}
+
+	offset := r.updates[i].ReceivedAt.Sub(r.updates[0].ReceivedAt)
+	delay := origin.Add(time.Duration(float64(offset) / r.opts.Speed)).Sub(r.clock.Now())
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := r.clock.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (r *Replay) released(i int) (bool, <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return i < r.position, r.stepped
+}
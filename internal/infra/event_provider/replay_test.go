@@ -0,0 +1,110 @@
+package event_provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+)
+
+func TestReplay(t *testing.T) {
+	var (
+		start   = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		updates = newRecordedUpdates(start)
+		btcUsd  = updates[0].Pair
+		ethUsd  = updates[1].Pair
+	)
+
+	t.Run("Should play a recording back at its original pace", func(t *testing.T) {
+		clk := clock.NewFake(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+		replay := NewReplay(updates, ReplayOptions{Speed: 1, KeepTimestamps: true}, 10, clk)
+
+		ch, err := replay.Start(context.Background(), btcUsd)
+		require.NoError(t, err)
+
+		assert.True(t, (<-ch).ReceivedAt.Equal(updates[0].ReceivedAt), "Expected the first update to be played at once")
+
+		// the second update of the pair was received 200ms after the first one
+		clk.BlockUntilDue(200 * time.Millisecond)
+		assert.Empty(t, ch)
+		clk.Advance(200 * time.Millisecond)
+
+		assert.True(t, (<-ch).ReceivedAt.Equal(updates[2].ReceivedAt), "Expected the recorded timestamps to be kept")
+
+		_, open := <-ch
+		assert.False(t, open, "Expected the channel to be closed at the end of the recording")
+	})
+
+	t.Run("Should play a recording back faster", func(t *testing.T) {
+		clk := clock.NewFake(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+		replay := NewReplay(updates, ReplayOptions{Speed: 100}, 10, clk)
+
+		ch, err := replay.Start(context.Background(), btcUsd)
+		require.NoError(t, err)
+
+		assert.True(t, (<-ch).ReceivedAt.Equal(clk.Now()), "Expected the updates to be stamped when replayed")
+
+		clk.BlockUntilDue(2 * time.Millisecond)
+		clk.Advance(2 * time.Millisecond)
+
+		assert.True(t, (<-ch).ReceivedAt.Equal(clk.Now()))
+	})
+
+	t.Run("Should release the updates step by step in the recorded order", func(t *testing.T) {
+		replay := NewReplay(updates, ReplayOptions{Stepwise: true}, 10, clock.New())
+
+		btc, err := replay.Start(context.Background(), btcUsd)
+		require.NoError(t, err)
+		eth, err := replay.Start(context.Background(), ethUsd)
+		require.NoError(t, err)
+
+		assert.Empty(t, btc)
+
+		assert.Equal(t, 2, replay.Step(1))
+		assert.True(t, (<-btc).Price.Equal(updates[0].Price))
+
+		assert.Equal(t, 1, replay.Step(1))
+		assert.True(t, (<-eth).Price.Equal(updates[1].Price))
+		assert.Empty(t, btc)
+
+		assert.Equal(t, 0, replay.Step(5))
+		assert.True(t, (<-btc).Price.Equal(updates[2].Price))
+
+		_, open := <-btc
+		assert.False(t, open, "Expected the channel to be closed at the end of the recording")
+	})
+
+	t.Run("Should only count the updates of the started pairs when stepping", func(t *testing.T) {
+		replay := NewReplay(updates, ReplayOptions{Stepwise: true}, 10, clock.New())
+
+		btc, err := replay.Start(context.Background(), btcUsd)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, replay.Step(1))
+		assert.True(t, (<-btc).Price.Equal(updates[0].Price))
+
+		// the update of the pair not started is passed over
+		assert.Equal(t, 0, replay.Step(1))
+		assert.True(t, (<-btc).Price.Equal(updates[2].Price))
+
+		_, open := <-btc
+		assert.False(t, open)
+	})
+
+	t.Run("Should stop when the context is done", func(t *testing.T) {
+		replay := NewReplay(updates, ReplayOptions{Stepwise: true}, 10, clock.New())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ch, err := replay.Start(ctx, btcUsd)
+		require.NoError(t, err)
+
+		cancel()
+
+		_, open := <-ch
+		assert.False(t, open)
+	})
+}
@@ -0,0 +1,174 @@
+package event_provider
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+)
+
+type SimulationModel string
+
+const (
+	// ModelGBM follows a geometric Brownian motion.
+	ModelGBM SimulationModel = "gbm"
+	// ModelJumps follows a geometric Brownian motion with sudden jumps.
+	ModelJumps SimulationModel = "jumps"
+	// ModelFlat keeps the initial price.
+	ModelFlat SimulationModel = "flat"
+	// ModelGaps follows a geometric Brownian motion with periods without updates.
+	ModelGaps SimulationModel = "gaps"
+)
+
+// simulatedPriceDecimals is the precision of the simulated prices.
+const simulatedPriceDecimals = 8
+
+// year is the time unit of the drift and volatility, which are annualized as usual for prices.
+const year = 365 * 24 * time.Hour
+
+func ParseSimulationModel(value string) (SimulationModel, error) {
+	switch model := SimulationModel(value); model {
+	case ModelGBM, ModelJumps, ModelFlat, ModelGaps:
+		return model, nil
+	default:
+		return "", errors.Errorf("invalid simulation model %q, expected gbm, jumps, flat or gaps", value)
+	}
+}
+
+type SimulationOptions struct {
+	Model    SimulationModel
+	Interval time.Duration
+	// Seed makes the simulation reproducible: a pair always gets the same prices for the same seed.
+	Seed         uint64
+	InitialPrice decimal.Decimal
+	// Drift and Volatility are annualized, e.g. 0.8 for a volatility of 80% a year.
+	Drift      float64
+	Volatility float64
+	// JumpProbability is the chance of a jump at each update, moving the price by JumpSize, e.g. 0.05 for 5%, up or down.
+	JumpProbability float64
+	JumpSize        float64
+	// GapProbability is the chance of a gap at each update, during which no update is emitted for GapDuration.
+	GapProbability float64
+	GapDuration    time.Duration
+}
+
+// Simulated generates prices following the configured model, as a replacement of the upstream for load and
+// front-end testing.
+type Simulated struct {
+	opts       SimulationOptions
+	bufferSize int
+	clock      clock.Clock
+}
+
+func NewSimulated(opts SimulationOptions, bufferSize int, clk clock.Clock) *Simulated {
+	return &Simulated{
+		opts:       opts,
+		bufferSize: bufferSize,
+		clock:      clk,
+	}
+}
+
+func (s *Simulated) Start(ctx context.Context, pair domain.Pair) (<-chan domain.PriceUpdate, error) {
+	if s.opts.Interval <= 0 {
+		return nil, errors.New("simulation interval must be greater than zero")
+	}
+
+	var (
+		model  = newPriceModel(s.opts, pair)
+		ticker = s.clock.NewTicker(s.opts.Interval)
+		ch     = make(chan domain.PriceUpdate, s.bufferSize)
+	)
+
+	go func() {
+		defer func() {
+			ticker.Stop()
+			close(ch)
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C():
+				price, emit := model.next()
+				if !emit {
+					continue
+				}
+
+				select {
+				case ch <- domain.PriceUpdate{
+					Pair:       pair,
+					Price:      price,
+					ReceivedAt: s.clock.Now().UTC(),
+				}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// priceModel computes the successive prices of a pair, independently of the time they are emitted at.
+type priceModel struct {
+	opts     SimulationOptions
+	rng      *rand.Rand
+	price    float64
+	dt       float64
+	gapTicks int
+}
+
+func newPriceModel(opts SimulationOptions, pair domain.Pair) *priceModel {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(pair.String()))
+
+	return &priceModel{
+		opts:  opts,
+		rng:   rand.New(rand.NewPCG(opts.Seed, hash.Sum64())),
+		price: opts.InitialPrice.InexactFloat64(),
+		dt:    float64(opts.Interval) / float64(year),
+	}
+}
+
+// next moves the price one interval forward, returning false when the update falls in a gap.
+func (m *priceModel) next() (decimal.Decimal, bool) {
+	if m.opts.Model == ModelFlat {
+		return m.opts.InitialPrice, true
+	}
+
+	m.price *= math.Exp((m.opts.Drift-m.opts.Volatility*m.opts.Volatility/2)*m.dt +
+		m.opts.Volatility*math.Sqrt(m.dt)*m.rng.NormFloat64())
+
+	switch m.opts.Model {
+	case ModelJumps:
+		if m.rng.Float64() < m.opts.JumpProbability {
+			direction := 1.0
+			if m.rng.IntN(2) == 0 {
+				direction = -1
+			}
+			m.price *= 1 + direction*m.opts.JumpSize
+		}
+
+	case ModelGaps:
+		if m.gapTicks > 0 {
+			m.gapTicks--
+			return decimal.Zero, false
+		}
+		if m.rng.Float64() < m.opts.GapProbability {
+			m.gapTicks = max(int(m.opts.GapDuration/m.opts.Interval)-1, 0) // the current update is the first one skipped
+			return decimal.Zero, false
+		}
+	}
+
+	return decimal.NewFromFloat(m.price).Round(simulatedPriceDecimals), true
+}
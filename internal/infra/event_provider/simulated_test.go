@@ -0,0 +1,152 @@
+package event_provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+)
+
+func newTestSimulationOptions(model SimulationModel) SimulationOptions {
+	return SimulationOptions{
+		Model:        model,
+		Interval:     time.Second,
+		Seed:         42,
+		InitialPrice: decimal.NewFromInt(50000),
+		Volatility:   0.8,
+	}
+}
+
+func simulate(model *priceModel, steps int) []decimal.Decimal {
+	var prices []decimal.Decimal
+
+	for i := 0; i < steps; i++ {
+		if price, emit := model.next(); emit {
+			prices = append(prices, price)
+		}
+	}
+
+	return prices
+}
+
+func TestSimulated_Models(t *testing.T) {
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		ethUsd = domain.NewPair(domain.ETH, domain.USD)
+	)
+
+	t.Run("Should be reproducible for the same seed", func(t *testing.T) {
+		opts := newTestSimulationOptions(ModelGBM)
+
+		first := simulate(newPriceModel(opts, btcUsd), 100)
+		second := simulate(newPriceModel(opts, btcUsd), 100)
+		assert.Equal(t, first, second)
+
+		other := simulate(newPriceModel(opts, ethUsd), 100)
+		assert.NotEqual(t, first, other, "Expected each pair to follow its own path")
+
+		opts.Seed = 7
+		reseeded := simulate(newPriceModel(opts, btcUsd), 100)
+		assert.NotEqual(t, first, reseeded)
+	})
+
+	t.Run("Should move the price with small steps", func(t *testing.T) {
+		prices := simulate(newPriceModel(newTestSimulationOptions(ModelGBM), btcUsd), 1000)
+		require.Len(t, prices, 1000)
+
+		previous := decimal.NewFromInt(50000)
+		for _, price := range prices {
+			change := price.Sub(previous).Div(previous).Abs()
+			assert.True(t, change.LessThan(decimal.NewFromFloat(0.01)), "Expected moves under 1%%, got %s", change)
+			previous = price
+		}
+	})
+
+	t.Run("Should keep a flat price", func(t *testing.T) {
+		prices := simulate(newPriceModel(newTestSimulationOptions(ModelFlat), btcUsd), 10)
+		for _, price := range prices {
+			assert.True(t, price.Equal(decimal.NewFromInt(50000)))
+		}
+	})
+
+	t.Run("Should jump", func(t *testing.T) {
+		opts := newTestSimulationOptions(ModelJumps)
+		opts.Volatility = 0
+		opts.JumpProbability = 0.5
+		opts.JumpSize = 0.1
+
+		prices := simulate(newPriceModel(opts, btcUsd), 100)
+
+		jumps := 0
+		previous := decimal.NewFromInt(50000)
+		for _, price := range prices {
+			if !price.Equal(previous) {
+				jumps++
+				change := price.Sub(previous).Div(previous).Abs().Round(4)
+				assert.True(t, change.Equal(decimal.NewFromFloat(0.1)), "Expected jumps of 10%%, got %s", change)
+			}
+			previous = price
+		}
+		assert.Greater(t, jumps, 20)
+	})
+
+	t.Run("Should leave gaps", func(t *testing.T) {
+		opts := newTestSimulationOptions(ModelGaps)
+		opts.GapProbability = 0.2
+		opts.GapDuration = 5 * time.Second
+
+		var (
+			model = newPriceModel(opts, btcUsd)
+			gaps  []int
+			run   int
+		)
+
+		for i := 0; i < 200; i++ {
+			if _, emit := model.next(); !emit {
+				run++
+				continue
+			}
+			if run > 0 {
+				gaps = append(gaps, run)
+				run = 0
+			}
+		}
+
+		require.NotEmpty(t, gaps)
+		for _, gap := range gaps {
+			assert.Zero(t, gap%5, "Expected gaps of whole periods of 5 intervals, got %d", gap)
+		}
+	})
+}
+
+func TestSimulated_Start(t *testing.T) {
+	var (
+		opts = newTestSimulationOptions(ModelGBM)
+		clk  = clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := NewSimulated(opts, 10, clk).Start(ctx, domain.NewPair(domain.BTC, domain.USD))
+	require.NoError(t, err)
+
+	for i := 1; i <= 3; i++ {
+		clk.BlockUntilDue(opts.Interval)
+		clk.Advance(opts.Interval)
+
+		update := <-ch
+		assert.Equal(t, "BTCUSD", update.Pair.String())
+		assert.True(t, update.ReceivedAt.Equal(clk.Now()), "Expected the update to be emitted every interval")
+	}
+
+	cancel()
+	for range ch {
+	}
+}
@@ -0,0 +1,74 @@
+package export
+
+import (
+	"context"
+	"time"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// pageSize is the number of updates read from the source at once, bounding the memory used by an export.
+const pageSize = 1000
+
+type RangeSource interface {
+	GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate
+}
+
+// Export writes the updates of the pair received in [from, to), reading them page by page from the source.
+// It returns the number of updates written.
+func Export(ctx context.Context, source RangeSource, pair domain.Pair, from, to time.Time, w Writer) (int, error) {
+	var (
+		written  int
+		cursor   = from
+		lastSeen time.Time
+		// the updates already written at the time of the cursor, skipped when reading the next page from it
+		skip int
+	)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+
+		// the next page starts at the last time written, as more updates may share it, so it's read beyond the
+		// updates already written at that time
+		limit := pageSize + skip
+		page := source.GetRange(pair, cursor, to, limit)
+
+		var (
+			progressed bool
+			sameTime   = skip
+		)
+		for _, update := range page {
+			// sources may return again an update at the page boundary, e.g. a downsampled bucket covering it
+			if written > 0 && update.ReceivedAt.Before(lastSeen) {
+				continue
+			}
+			if written > 0 && update.ReceivedAt.Equal(lastSeen) && skip > 0 {
+				skip--
+				continue
+			}
+
+			if err := w.Write(NewRecord(update)); err != nil {
+				return written, err
+			}
+
+			if written > 0 && update.ReceivedAt.Equal(lastSeen) {
+				sameTime++
+			} else {
+				sameTime = 1
+			}
+
+			written++
+			lastSeen = update.ReceivedAt
+			progressed = true
+		}
+
+		if len(page) < limit || !progressed {
+			return written, nil
+		}
+
+		cursor = lastSeen
+		skip = sameTime
+	}
+}
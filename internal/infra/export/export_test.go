@@ -0,0 +1,162 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/in_memory"
+)
+
+var btcUsd = domain.NewPair(domain.BTC, domain.USD)
+
+func testRecords() []Record {
+	receivedAt := time.Date(2025, 1, 1, 10, 0, 0, 123456789, time.UTC)
+
+	return []Record{
+		NewRecord(domain.PriceUpdate{Pair: btcUsd, Price: decimal.RequireFromString("50000.10"), ReceivedAt: receivedAt}),
+		NewRecord(domain.PriceUpdate{Pair: btcUsd, Price: decimal.RequireFromString("0.000000012345678901"), ReceivedAt: receivedAt.Add(5 * time.Second)}),
+	}
+}
+
+func writeAll(t *testing.T, format Format, records []Record) []byte {
+	var out bytes.Buffer
+
+	w, err := NewWriter(format, &out)
+	require.NoError(t, err)
+
+	for _, record := range records {
+		require.NoError(t, w.Write(record))
+	}
+	require.NoError(t, w.Close())
+
+	return out.Bytes()
+}
+
+func TestWriters(t *testing.T) {
+	records := testRecords()
+
+	t.Run("CSV", func(t *testing.T) {
+		assert.Equal(t, "pair,price,received_at\n"+
+			"BTCUSD,50000.1,2025-01-01T10:00:00.123456789Z\n"+
+			"BTCUSD,0.000000012345678901,2025-01-01T10:00:05.123456789Z\n", string(writeAll(t, FormatCSV, records)))
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		assert.Equal(t, `{"pair":"BTCUSD","price":"50000.1","received_at":"2025-01-01T10:00:00.123456789Z"}`+"\n"+
+			`{"pair":"BTCUSD","price":"0.000000012345678901","received_at":"2025-01-01T10:00:05.123456789Z"}`+"\n",
+			string(writeAll(t, FormatNDJSON, records)))
+	})
+
+	t.Run("Parquet", func(t *testing.T) {
+		content := writeAll(t, FormatParquet, records)
+
+		read, err := parquet.Read[Record](bytes.NewReader(content), int64(len(content)))
+		require.NoError(t, err)
+		require.Len(t, read, 2)
+		assert.Equal(t, records[1].Price, read[1].Price)
+		assert.True(t, records[1].ReceivedAt.Equal(read[1].ReceivedAt))
+	})
+
+	t.Run("Rejects unknown formats", func(t *testing.T) {
+		_, err := ParseFormat("xml")
+		assert.Error(t, err)
+
+		format, err := ParseFormat("parquet")
+		require.NoError(t, err)
+		assert.Equal(t, FormatParquet, format)
+	})
+}
+
+func TestExport(t *testing.T) {
+	var (
+		start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		total = 2*pageSize + 10
+		repo  = in_memory.NewPricesByRingBuffer(total)
+	)
+
+	for i := 0; i < total; i++ {
+		repo.Store(domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(int64(i)), ReceivedAt: start.Add(time.Duration(i) * time.Second)})
+	}
+
+	t.Run("Should export every page of the range", func(t *testing.T) {
+		var out bytes.Buffer
+		w, err := NewWriter(FormatCSV, &out)
+		require.NoError(t, err)
+
+		written, err := Export(context.Background(), repo, btcUsd, start.Add(5*time.Second), start.Add(time.Hour), w)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		assert.Equal(t, total-5, written)
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, total-5+1)
+		assert.True(t, strings.HasPrefix(lines[1], "BTCUSD,5,"))
+		assert.True(t, strings.HasPrefix(lines[len(lines)-1], "BTCUSD,2009,"))
+	})
+
+	t.Run("Should export the updates sharing the time of a page boundary", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			total  int
+			timeOf func(i int) time.Time
+		}{
+			{
+				name:   "three updates per second",
+				total:  pageSize + pageSize/2,
+				timeOf: func(i int) time.Time { return start.Add(time.Duration(i/3) * time.Second) },
+			},
+			{
+				name:   "more updates than a page at the same time",
+				total:  pageSize + 5,
+				timeOf: func(int) time.Time { return start },
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := in_memory.NewPricesByRingBuffer(tt.total)
+				for i := 0; i < tt.total; i++ {
+					repo.Store(domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(int64(i)), ReceivedAt: tt.timeOf(i)})
+				}
+
+				var out bytes.Buffer
+				w, err := NewWriter(FormatCSV, &out)
+				require.NoError(t, err)
+
+				written, err := Export(context.Background(), repo, btcUsd, start, start.Add(time.Hour), w)
+				require.NoError(t, err)
+				require.NoError(t, w.Close())
+
+				assert.Equal(t, tt.total, written)
+
+				lines := strings.Split(strings.TrimSpace(out.String()), "\n")[1:]
+				require.Len(t, lines, tt.total)
+				for i, line := range lines {
+					require.True(t, strings.HasPrefix(line, "BTCUSD,"+strconv.Itoa(i)+","), "Expected update %d in order, got %s", i, line)
+				}
+			})
+		}
+	})
+
+	t.Run("Should stop when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		w, err := NewWriter(FormatNDJSON, &bytes.Buffer{})
+		require.NoError(t, err)
+
+		_, err = Export(ctx, repo, btcUsd, start, start.Add(time.Hour), w)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
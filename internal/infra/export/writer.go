@@ -0,0 +1,137 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/pkg/errors"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatNDJSON  Format = "ndjson"
+	FormatParquet Format = "parquet"
+)
+
+// parquetRowGroupSize bounds the rows buffered in memory before a parquet row group is written.
+const parquetRowGroupSize = 10_000
+
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(value); format {
+	case FormatCSV, FormatNDJSON, FormatParquet:
+		return format, nil
+	default:
+		return "", errors.Errorf("invalid format %q, expected csv, ndjson or parquet", value)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "text/csv"
+	}
+}
+
+// Record is an exported price update. Prices are formatted by decimal.Decimal, so they are exact in every format,
+// and reception times are in UTC with nanosecond precision.
+type Record struct {
+	Pair       string    `json:"pair" parquet:"pair,dict"`
+	Price      string    `json:"price" parquet:"price"`
+	ReceivedAt time.Time `json:"received_at" parquet:"received_at,timestamp(nanosecond)"`
+}
+
+func NewRecord(update domain.PriceUpdate) Record {
+	return Record{
+		Pair:       update.Pair.String(),
+		Price:      update.Price.String(),
+		ReceivedAt: update.ReceivedAt.UTC(),
+	}
+}
+
+// Writer encodes records one at a time. Close writes what is buffered, without closing the underlying writer.
+type Writer interface {
+	Write(record Record) error
+	Close() error
+}
+
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	case FormatParquet:
+		return &parquetWriter{writer: parquet.NewGenericWriter[Record](w)}, nil
+	default:
+		return nil, errors.Errorf("unsupported format %q", format)
+	}
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"pair", "price", "received_at"}); err != nil {
+		return nil, errors.Wrap(err, "failed to write csv header")
+	}
+
+	return &csvWriter{writer: writer}, nil
+}
+
+func (w *csvWriter) Write(record Record) error {
+	err := w.writer.Write([]string{record.Pair, record.Price, record.ReceivedAt.Format(time.RFC3339Nano)})
+	return errors.Wrap(err, "failed to write csv record")
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return errors.Wrap(w.writer.Error(), "failed to flush csv records")
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(record Record) error {
+	return errors.Wrap(w.encoder.Encode(record), "failed to write json record")
+}
+
+func (w *ndjsonWriter) Close() error {
+	return nil
+}
+
+type parquetWriter struct {
+	writer   *parquet.GenericWriter[Record]
+	buffered int
+}
+
+func (w *parquetWriter) Write(record Record) error {
+	if _, err := w.writer.Write([]Record{record}); err != nil {
+		return errors.Wrap(err, "failed to write parquet record")
+	}
+
+	if w.buffered++; w.buffered >= parquetRowGroupSize {
+		w.buffered = 0
+		return errors.Wrap(w.writer.Flush(), "failed to write parquet row group")
+	}
+
+	return nil
+}
+
+func (w *parquetWriter) Close() error {
+	return errors.Wrap(w.writer.Close(), "failed to write parquet footer")
+}
@@ -0,0 +1,94 @@
+package fake_upstream
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Behaviour scripts how the fake upstream answers a request. The zero value answers normally.
+type Behaviour struct {
+	// Latency delays the answer.
+	Latency time.Duration `json:"-"`
+	// Status replaces the status code of the answer, e.g. 429 or 503.
+	Status int `json:"status,omitempty"`
+	// Body replaces the body of the answer.
+	Body string `json:"body,omitempty"`
+}
+
+// UnmarshalJSON accepts the latency as a duration string, e.g. "500ms", for the behaviours scripted through
+// the control endpoints.
+func (b *Behaviour) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Latency string `json:"latency"`
+		Status  int    `json:"status"`
+		Body    string `json:"body"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*b = Behaviour{Status: raw.Status, Body: raw.Body}
+
+	if raw.Latency != "" {
+		latency, err := time.ParseDuration(raw.Latency)
+		if err != nil {
+			return errors.Wrap(err, "invalid latency")
+		}
+		b.Latency = latency
+	}
+
+	return nil
+}
+
+// OK answers normally.
+func OK() Behaviour {
+	return Behaviour{}
+}
+
+// Slow answers normally after the given latency.
+func Slow(latency time.Duration) Behaviour {
+	return Behaviour{Latency: latency}
+}
+
+// RateLimited answers as the upstream does when the rate limit is exceeded.
+func RateLimited() Behaviour {
+	return Behaviour{
+		Status: http.StatusTooManyRequests,
+		Body:   `{"Response":"Error","Message":"You are over your rate limit please upgrade your account!","HasWarning":false,"Type":99,"Data":{}}`,
+	}
+}
+
+// ServerErrors answers with count consecutive server errors of the given status, e.g. 502 or 503.
+func ServerErrors(status, count int) []Behaviour {
+	behaviours := make([]Behaviour, count)
+	for i := range behaviours {
+		behaviours[i] = Behaviour{Status: status, Body: http.StatusText(status)}
+	}
+	return behaviours
+}
+
+// MalformedJSON answers with a truncated JSON body and status 200.
+func MalformedJSON() Behaviour {
+	return Behaviour{Body: `{"USD": 5000`}
+}
+
+// ErrorBody answers with the upstream error format and status 200, as the upstream does for invalid requests.
+func ErrorBody(message string) Behaviour {
+	return Behaviour{Body: errorBody(message)}
+}
+
+type errorResponse struct {
+	Response   string   `json:"Response"`
+	Message    string   `json:"Message"`
+	HasWarning bool     `json:"HasWarning"`
+	Type       int      `json:"Type"`
+	Data       struct{} `json:"Data"`
+}
+
+func errorBody(message string) string {
+	body, _ := json.Marshal(errorResponse{Response: "Error", Message: message, Type: 2})
+	return string(body)
+}
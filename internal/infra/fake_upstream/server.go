@@ -0,0 +1,379 @@
+package fake_upstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// maxHistoryLimit mirrors the upstream maximum of candles per history request.
+const maxHistoryLimit = 2000
+
+// Server is a stand-in for the CoinDesk (CryptoCompare) price API, answering the /data/price, /data/pricemulti,
+// /data/pricemultifull, /data/v2/histominute and /data/v2/histohour endpoints from prices set by the tests, with scripted behaviours.
+// It also exposes control endpoints under /_control, to be scripted from other processes.
+type Server struct {
+	mu         sync.Mutex
+	prices     map[domain.Currency]map[domain.Currency]decimal.Decimal
+	quotes     map[domain.Pair]domain.Quote
+	behaviours []Behaviour
+	fallback   Behaviour
+	requests   map[string]int
+	mux        *http.ServeMux
+}
+
+func New() *Server {
+	s := &Server{
+		prices:   make(map[domain.Currency]map[domain.Currency]decimal.Decimal),
+		quotes:   make(map[domain.Pair]domain.Quote),
+		requests: make(map[string]int),
+		mux:      http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /data/price", s.scripted(s.price))
+	s.mux.HandleFunc("GET /data/pricemulti", s.scripted(s.priceMulti))
+	s.mux.HandleFunc("GET /data/pricemultifull", s.scripted(s.priceMultiFull))
+	s.mux.HandleFunc("GET /data/v2/histominute", s.scripted(s.history(time.Minute)))
+	s.mux.HandleFunc("GET /data/v2/histohour", s.scripted(s.history(time.Hour)))
+
+	s.mux.HandleFunc("PUT /_control/prices/{pair}", s.controlPrice)
+	s.mux.HandleFunc("POST /_control/behaviours", s.controlBehaviours)
+	s.mux.HandleFunc("PUT /_control/fallback", s.controlFallback)
+	s.mux.HandleFunc("GET /_control/requests", s.controlRequests)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// SetPrice sets the price answered for a pair.
+func (s *Server) SetPrice(pair domain.Pair, price decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.prices[pair.From] == nil {
+		s.prices[pair.From] = make(map[domain.Currency]decimal.Decimal)
+	}
+	s.prices[pair.From][pair.To] = price
+}
+
+// SetQuote sets the market data answered along with the price of a pair by the full price endpoint.
+func (s *Server) SetQuote(pair domain.Pair, quote domain.Quote) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.quotes[pair] = quote
+}
+
+// Enqueue scripts the answers of the next requests to the price endpoints, one behaviour per request, in order.
+func (s *Server) Enqueue(behaviours ...Behaviour) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.behaviours = append(s.behaviours, behaviours...)
+}
+
+// SetFallback sets the behaviour of the requests once the enqueued behaviours are exhausted, OK by default.
+func (s *Server) SetFallback(behaviour Behaviour) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fallback = behaviour
+}
+
+// Requests returns the number of requests received by an endpoint path, e.g. /data/price.
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[path]
+}
+
+func (s *Server) next(path string) Behaviour {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[path]++
+
+	if len(s.behaviours) == 0 {
+		return s.fallback
+	}
+
+	behaviour := s.behaviours[0]
+	s.behaviours = s.behaviours[1:]
+
+	return behaviour
+}
+
+// scripted applies the next behaviour to a request, answering it with the handler unless the behaviour replaces it.
+func (s *Server) scripted(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		behaviour := s.next(r.URL.Path)
+
+		if behaviour.Latency > 0 {
+			select {
+			case <-time.After(behaviour.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if behaviour.Status == 0 && behaviour.Body == "" {
+			handler(w, r)
+			return
+		}
+
+		status := behaviour.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(behaviour.Body))
+	}
+}
+
+// price answers /data/price?fsym=BTC&tsyms=USD,EUR with {"USD":50000,"EUR":46000}.
+func (s *Server) price(w http.ResponseWriter, r *http.Request) {
+	from := domain.Currency(strings.ToUpper(r.URL.Query().Get("fsym")))
+	if from == "" {
+		writeBody(w, errorBody("fsym param is empty or null."))
+		return
+	}
+
+	prices, message := s.lookup(from, r.URL.Query().Get("tsyms"))
+	if message != "" {
+		writeBody(w, errorBody(message))
+		return
+	}
+
+	writeJSON(w, prices)
+}
+
+// priceMulti answers /data/pricemulti?fsyms=BTC,ETH&tsyms=USD with {"BTC":{"USD":50000},"ETH":{"USD":3000}}.
+func (s *Server) priceMulti(w http.ResponseWriter, r *http.Request) {
+	fsyms := splitSymbols(r.URL.Query().Get("fsyms"))
+	if len(fsyms) == 0 {
+		writeBody(w, errorBody("fsyms param is empty or null."))
+		return
+	}
+
+	response := make(map[domain.Currency]map[domain.Currency]json.Number, len(fsyms))
+	for _, from := range fsyms {
+		prices, message := s.lookup(domain.Currency(from), r.URL.Query().Get("tsyms"))
+		if message != "" {
+			writeBody(w, errorBody(message))
+			return
+		}
+		response[domain.Currency(from)] = prices
+	}
+
+	writeJSON(w, response)
+}
+
+// priceMultiFull answers /data/pricemultifull?fsyms=BTC&tsyms=USD with {"RAW":{"BTC":{"USD":{"PRICE":50000,...}}}},
+// along with the quote set for each pair.
+func (s *Server) priceMultiFull(w http.ResponseWriter, r *http.Request) {
+	fsyms := splitSymbols(r.URL.Query().Get("fsyms"))
+	if len(fsyms) == 0 {
+		writeBody(w, errorBody("fsyms param is empty or null."))
+		return
+	}
+
+	raw := make(map[domain.Currency]map[domain.Currency]map[string]json.Number, len(fsyms))
+	for _, from := range fsyms {
+		prices, message := s.lookup(domain.Currency(from), r.URL.Query().Get("tsyms"))
+		if message != "" {
+			writeBody(w, errorBody(message))
+			return
+		}
+
+		raw[domain.Currency(from)] = make(map[domain.Currency]map[string]json.Number, len(prices))
+		for to, price := range prices {
+			raw[domain.Currency(from)][to] = s.fullFields(domain.NewPair(domain.Currency(from), to), price)
+		}
+	}
+
+	writeJSON(w, map[string]any{"RAW": raw})
+}
+
+func (s *Server) fullFields(pair domain.Pair, price json.Number) map[string]json.Number {
+	s.mu.Lock()
+	quote := s.quotes[pair]
+	s.mu.Unlock()
+
+	fields := map[string]json.Number{"PRICE": price}
+
+	for name, value := range map[string]decimal.NullDecimal{
+		"BID":             quote.Bid,
+		"ASK":             quote.Ask,
+		"VOLUME24HOUR":    quote.Volume24h,
+		"VOLUME24HOURTO":  quote.QuoteVolume24h,
+		"HIGH24HOUR":      quote.High24h,
+		"LOW24HOUR":       quote.Low24h,
+		"CHANGEPCT24HOUR": quote.ChangePct24h,
+	} {
+		if value.Valid {
+			fields[name] = json.Number(value.Decimal.String())
+		}
+	}
+
+	return fields
+}
+
+// history answers the candles of /data/v2/histominute and /data/v2/histohour, flat at the current price.
+func (s *Server) history(interval time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			from = domain.Currency(strings.ToUpper(r.URL.Query().Get("fsym")))
+			to   = r.URL.Query().Get("tsym")
+		)
+
+		prices, message := s.lookup(from, to)
+		if message != "" {
+			writeBody(w, errorBody(message))
+			return
+		}
+
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit <= 0 {
+			limit = 1
+		}
+		limit = min(limit, maxHistoryLimit)
+
+		var (
+			current = time.Now().Truncate(interval)
+			price   = prices[domain.Currency(strings.ToUpper(to))]
+			candles = make([]map[string]any, 0, limit+1)
+		)
+
+		// the upstream returns limit+1 candles, the last one in progress
+		for i := limit; i >= 0; i-- {
+			candles = append(candles, map[string]any{
+				"time":  current.Add(-time.Duration(i) * interval).Unix(),
+				"open":  price,
+				"high":  price,
+				"low":   price,
+				"close": price,
+			})
+		}
+
+		writeJSON(w, map[string]any{
+			"Response": "Success",
+			"Data":     map[string]any{"Data": candles},
+		})
+	}
+}
+
+// lookup returns the prices of a currency in the comma separated target currencies, or the upstream error message.
+func (s *Server) lookup(from domain.Currency, tsyms string) (map[domain.Currency]json.Number, string) {
+	targets := splitSymbols(tsyms)
+	if len(targets) == 0 {
+		return nil, "tsyms param is empty or null."
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prices := make(map[domain.Currency]json.Number, len(targets))
+	for _, target := range targets {
+		price, exists := s.prices[from][domain.Currency(target)]
+		if !exists {
+			continue
+		}
+		prices[domain.Currency(target)] = json.Number(price.String())
+	}
+
+	if len(prices) == 0 {
+		return nil, fmt.Sprintf("cccagg_or_exchange market does not exist for this coin pair (%s-%s)", from, targets[0])
+	}
+
+	return prices, ""
+}
+
+func (s *Server) controlPrice(w http.ResponseWriter, r *http.Request) {
+	pair, err := domain.NewPairFromString(strings.ToUpper(r.PathValue("pair")))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Price decimal.Decimal `json:"price"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.SetPrice(pair, body.Price)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) controlBehaviours(w http.ResponseWriter, r *http.Request) {
+	var behaviours []Behaviour
+	if err := json.NewDecoder(r.Body).Decode(&behaviours); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.Enqueue(behaviours...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) controlFallback(w http.ResponseWriter, r *http.Request) {
+	var behaviour Behaviour
+	if err := json.NewDecoder(r.Body).Decode(&behaviour); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.SetFallback(behaviour)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) controlRequests(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	requests := make(map[string]int, len(s.requests))
+	for path, count := range s.requests {
+		requests[path] = count
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, requests)
+}
+
+func splitSymbols(value string) []string {
+	var symbols []string
+	for _, symbol := range strings.Split(value, ",") {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeBody(w, string(body))
+}
+
+func writeBody(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(body))
+}
@@ -0,0 +1,145 @@
+package fake_upstream
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+func TestServer(t *testing.T) {
+	var (
+		upstream = New()
+		server   = httptest.NewServer(upstream)
+	)
+	t.Cleanup(server.Close)
+
+	upstream.SetPrice(domain.NewPair(domain.BTC, domain.USD), decimal.RequireFromString("50000.12"))
+	upstream.SetPrice(domain.NewPair(domain.ETH, domain.USD), decimal.NewFromInt(3000))
+
+	get := func(t *testing.T, path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("Answers the price endpoints", func(t *testing.T) {
+		status, body := get(t, "/data/price?fsym=BTC&tsyms=USD")
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{"USD":50000.12}`, body)
+
+		_, body = get(t, "/data/pricemulti?fsyms=BTC,ETH&tsyms=USD")
+		assert.JSONEq(t, `{"BTC":{"USD":50000.12},"ETH":{"USD":3000}}`, body)
+	})
+
+	t.Run("Answers the full price endpoint with the quotes set", func(t *testing.T) {
+		upstream.SetQuote(domain.NewPair(domain.BTC, domain.USD), domain.Quote{
+			Volume24h: decimal.NewNullDecimal(decimal.RequireFromString("1234.5")),
+		})
+
+		_, body := get(t, "/data/pricemultifull?fsyms=BTC,ETH&tsyms=USD")
+		assert.JSONEq(t, `{"RAW":{
+			"BTC":{"USD":{"PRICE":50000.12,"VOLUME24HOUR":1234.5}},
+			"ETH":{"USD":{"PRICE":3000}}
+		}}`, body)
+	})
+
+	t.Run("Answers the history endpoints", func(t *testing.T) {
+		_, body := get(t, "/data/v2/histominute?fsym=BTC&tsym=USD&limit=3")
+
+		var response struct {
+			Response string
+			Data     struct {
+				Data []struct {
+					Time  int64
+					Close json.Number
+				}
+			}
+		}
+		require.NoError(t, json.Unmarshal([]byte(body), &response))
+		assert.Equal(t, "Success", response.Response)
+		require.Len(t, response.Data.Data, 4)
+		assert.Equal(t, "50000.12", response.Data.Data[0].Close.String())
+	})
+
+	t.Run("Answers unknown pairs with an error body", func(t *testing.T) {
+		status, body := get(t, "/data/price?fsym=BTC&tsyms=XYZ")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, `"Response":"Error"`)
+	})
+
+	t.Run("Applies the scripted behaviours in order", func(t *testing.T) {
+		upstream.Enqueue(RateLimited())
+		upstream.Enqueue(ServerErrors(http.StatusServiceUnavailable, 2)...)
+		upstream.Enqueue(MalformedJSON(), ErrorBody("boom"), Slow(50*time.Millisecond))
+
+		status, _ := get(t, "/data/price?fsym=BTC&tsyms=USD")
+		assert.Equal(t, http.StatusTooManyRequests, status)
+
+		for i := 0; i < 2; i++ {
+			status, _ = get(t, "/data/price?fsym=BTC&tsyms=USD")
+			assert.Equal(t, http.StatusServiceUnavailable, status)
+		}
+
+		_, body := get(t, "/data/price?fsym=BTC&tsyms=USD")
+		assert.False(t, json.Valid([]byte(body)))
+
+		status, body = get(t, "/data/price?fsym=BTC&tsyms=USD")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, "boom")
+
+		started := time.Now()
+		_, body = get(t, "/data/price?fsym=BTC&tsyms=USD")
+		assert.GreaterOrEqual(t, time.Since(started), 50*time.Millisecond)
+		assert.JSONEq(t, `{"USD":50000.12}`, body)
+	})
+
+	t.Run("Is scriptable through the control endpoints", func(t *testing.T) {
+		put := func(path, body string) {
+			req, err := http.NewRequest(http.MethodPut, server.URL+path, strings.NewReader(body))
+			require.NoError(t, err)
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		}
+
+		put("/_control/prices/BTCUSD", `{"price":"51000"}`)
+
+		resp, err := http.Post(server.URL+"/_control/behaviours", "application/json", strings.NewReader(`[{"status":502,"latency":"1ms"}]`))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+
+		status, _ := get(t, "/data/price?fsym=BTC&tsyms=USD")
+		assert.Equal(t, http.StatusBadGateway, status)
+
+		_, body := get(t, "/data/price?fsym=BTC&tsyms=USD")
+		assert.JSONEq(t, `{"USD":51000}`, body)
+
+		put("/_control/fallback", `{"status":500}`)
+		status, _ = get(t, "/data/price?fsym=BTC&tsyms=USD")
+		assert.Equal(t, http.StatusInternalServerError, status)
+		upstream.SetFallback(OK())
+
+		_, body = get(t, "/_control/requests")
+		var requests map[string]int
+		require.NoError(t, json.Unmarshal([]byte(body), &requests))
+		assert.Equal(t, upstream.Requests("/data/price"), requests["/data/price"])
+	})
+}
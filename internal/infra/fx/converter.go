@@ -0,0 +1,189 @@
+package fx
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+)
+
+// divisionPrecision is the number of decimals the cross rates are divided with
+const divisionPrecision = 18
+
+// Conversion is the exchange rate a price was converted with, and the time the rate was updated at.
+type Conversion struct {
+	From   domain.Currency
+	To     domain.Currency
+	Rate   decimal.Decimal
+	RateAt time.Time
+}
+
+// Converter converts prices between currencies, with the latest rates of the currencies against a base currency,
+// refreshed periodically from a source.
+type Converter struct {
+	mu         sync.RWMutex
+	log        *slog.Logger
+	clock      clock.Clock
+	source     Source
+	base       domain.Currency
+	currencies []domain.Currency
+	interval   time.Duration
+	rates      map[domain.Currency]Rate
+}
+
+func NewConverter(
+	source Source,
+	base domain.Currency,
+	currencies []domain.Currency,
+	interval time.Duration,
+	clk clock.Clock,
+) *Converter {
+	return &Converter{
+		log:        slog.Default(),
+		clock:      clk,
+		source:     source,
+		base:       base,
+		currencies: slices.Clone(currencies),
+		interval:   interval,
+		rates:      make(map[domain.Currency]Rate),
+	}
+}
+
+// Start refreshes the rates, then keeps refreshing them every interval in the background, until ctx is done.
+// A failed refresh keeps the previous rates.
+func (c *Converter) Start(ctx context.Context) {
+	if err := c.Refresh(ctx); err != nil {
+		c.log.Error("Failed to refresh FX rates", "error", err.Error())
+	}
+
+	ticker := c.clock.NewTicker(c.interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				if err := c.Refresh(ctx); err != nil {
+					c.log.Error("Failed to refresh FX rates", "error", err.Error())
+				}
+			}
+		}
+	}()
+}
+
+// Refresh replaces the rates with the ones of the source. The rates the source doesn't return are kept.
+func (c *Converter) Refresh(ctx context.Context) error {
+	rates, err := c.source.Rates(ctx, c.base, c.currencies)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch FX rates")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rate := range rates {
+		if !rate.Value.IsPositive() {
+			c.log.Warn("Ignoring invalid FX rate", "currency", rate.Currency, "rate", rate.Value.String())
+			continue
+		}
+		c.rates[rate.Currency] = rate
+	}
+
+	return nil
+}
+
+// Supports tells whether prices can be converted from and to the currency, once its rate is known.
+func (c *Converter) Supports(currency domain.Currency) bool {
+	return currency == c.base || slices.Contains(c.currencies, currency)
+}
+
+// Rate returns the rate converting prices from a currency to another, crossed through the base currency. The rate
+// is as old as the oldest rate it is computed from.
+func (c *Converter) Rate(from, to domain.Currency) (Conversion, error) {
+	fromRate, err := c.rate(from)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	toRate, err := c.rate(to)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	conversion := Conversion{
+		From:   from,
+		To:     to,
+		Rate:   toRate.Value,
+		RateAt: toRate.UpdatedAt,
+	}
+
+	// the rates are against the base currency, so only the conversions from another currency are divided
+	if from != c.base {
+		conversion.Rate = toRate.Value.DivRound(fromRate.Value, divisionPrecision)
+	}
+
+	if conversion.RateAt.IsZero() || (!fromRate.UpdatedAt.IsZero() && fromRate.UpdatedAt.Before(conversion.RateAt)) {
+		conversion.RateAt = fromRate.UpdatedAt
+	}
+
+	return conversion, nil
+}
+
+// rate returns the rate of a currency against the base currency, which is always 1 for the base currency itself.
+func (c *Converter) rate(currency domain.Currency) (Rate, error) {
+	if currency == c.base {
+		return Rate{Currency: currency, Value: decimal.NewFromInt(1)}, nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	rate, ok := c.rates[currency]
+	if !ok {
+		return Rate{}, errors.Errorf("no FX rate for %s", currency)
+	}
+
+	return rate, nil
+}
+
+// Convert converts the price of an update to a currency, along with the prices of its quote, into an update of the
+// pair quoted in that currency, e.g. BTCUSD into BTCEUR. The volume in the base currency and the change of the quote are kept as they are.
+func (c *Converter) Convert(update domain.PriceUpdate, to domain.Currency) (domain.PriceUpdate, Conversion, error) {
+	conversion, err := c.Rate(update.Pair.To, to)
+	if err != nil {
+		return domain.PriceUpdate{}, Conversion{}, err
+	}
+
+	converted := update
+	converted.Pair = domain.NewPair(update.Pair.From, to)
+	converted.Price = update.Price.Mul(conversion.Rate)
+
+	if update.Quote != nil {
+		quote := *update.Quote
+		quote.Bid = convertNull(quote.Bid, conversion.Rate)
+		quote.Ask = convertNull(quote.Ask, conversion.Rate)
+		quote.QuoteVolume24h = convertNull(quote.QuoteVolume24h, conversion.Rate)
+		quote.High24h = convertNull(quote.High24h, conversion.Rate)
+		quote.Low24h = convertNull(quote.Low24h, conversion.Rate)
+		converted.Quote = &quote
+	}
+
+	return converted, conversion, nil
+}
+
+func convertNull(value decimal.NullDecimal, rate decimal.Decimal) decimal.NullDecimal {
+	if !value.Valid {
+		return value
+	}
+	return decimal.NewNullDecimal(value.Decimal.Mul(rate))
+}
@@ -0,0 +1,153 @@
+package fx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+)
+
+var (
+	gbp    = domain.Currency("GBP")
+	start  = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	btcUsd = domain.NewPair(domain.BTC, domain.USD)
+)
+
+type MockSource struct {
+	mock.Mock
+}
+
+func (m *MockSource) Rates(ctx context.Context, base domain.Currency, currencies []domain.Currency) ([]Rate, error) {
+	args := m.Called(ctx, base, currencies)
+	rates, _ := args.Get(0).([]Rate)
+	return rates, args.Error(1)
+}
+
+func rate(currency domain.Currency, value string, at time.Time) Rate {
+	return Rate{Currency: currency, Value: decimal.RequireFromString(value), UpdatedAt: at}
+}
+
+func newConverter(t *testing.T, rates ...Rate) *Converter {
+	source := new(MockSource)
+	source.On("Rates", mock.Anything, domain.USD, []domain.Currency{domain.EUR, gbp}).Return(rates, nil)
+
+	converter := NewConverter(source, domain.USD, []domain.Currency{domain.EUR, gbp}, time.Hour, clock.New())
+	require.NoError(t, converter.Refresh(context.Background()))
+
+	return converter
+}
+
+func TestConverter_Rate(t *testing.T) {
+	converter := newConverter(t, rate(domain.EUR, "0.8", start), rate(gbp, "0.75", start.Add(-time.Minute)))
+
+	tests := []struct {
+		from, to domain.Currency
+		rate     string
+		rateAt   time.Time
+	}{
+		{from: domain.USD, to: domain.EUR, rate: "0.8", rateAt: start},
+		{from: domain.EUR, to: domain.USD, rate: "1.25", rateAt: start},
+		{from: domain.EUR, to: gbp, rate: "0.9375", rateAt: start.Add(-time.Minute)},
+		{from: domain.USD, to: domain.USD, rate: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from+tt.to), func(t *testing.T) {
+			conversion, err := converter.Rate(tt.from, tt.to)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.rate, conversion.Rate.String())
+			assert.Equal(t, tt.rateAt, conversion.RateAt)
+		})
+	}
+
+	t.Run("Fails without a rate", func(t *testing.T) {
+		_, err := newConverter(t, rate(domain.EUR, "0.8", start)).Rate(domain.USD, gbp)
+		assert.ErrorContains(t, err, "no FX rate for GBP")
+
+		_, err = converter.Rate(domain.USDT, domain.EUR)
+		assert.ErrorContains(t, err, "no FX rate for USDT")
+	})
+}
+
+func TestConverter_Convert(t *testing.T) {
+	converter := newConverter(t, rate(domain.EUR, "0.8", start))
+
+	update := domain.PriceUpdate{
+		Pair:       btcUsd,
+		Price:      decimal.RequireFromString("50000"),
+		ReceivedAt: start,
+		Quote: &domain.Quote{
+			Bid:          decimal.NewNullDecimal(decimal.RequireFromString("49999")),
+			Volume24h:    decimal.NewNullDecimal(decimal.RequireFromString("1200")),
+			ChangePct24h: decimal.NewNullDecimal(decimal.RequireFromString("-1.5")),
+		},
+	}
+
+	converted, conversion, err := converter.Convert(update, domain.EUR)
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.NewPair(domain.BTC, domain.EUR), converted.Pair)
+	assert.Equal(t, "40000", converted.Price.String())
+	assert.Equal(t, start, converted.ReceivedAt)
+	assert.Equal(t, "39999.2", converted.Quote.Bid.Decimal.String())
+	assert.False(t, converted.Quote.Ask.Valid)
+	assert.Equal(t, "1200", converted.Quote.Volume24h.Decimal.String(), "Expected the volume in the base currency")
+	assert.Equal(t, "-1.5", converted.Quote.ChangePct24h.Decimal.String())
+	assert.Equal(t, "49999", update.Quote.Bid.Decimal.String(), "Expected the original quote untouched")
+
+	assert.Equal(t, Conversion{From: domain.USD, To: domain.EUR, Rate: decimal.RequireFromString("0.8"), RateAt: start}, conversion)
+}
+
+func TestConverter_Start(t *testing.T) {
+	var (
+		source     = new(MockSource)
+		clk        = clock.NewFake(start)
+		converter  = NewConverter(source, domain.USD, []domain.Currency{domain.EUR}, time.Hour, clk)
+		currencies = []domain.Currency{domain.EUR}
+		failed     = make(chan struct{})
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	source.On("Rates", mock.Anything, domain.USD, currencies).Return([]Rate{rate(domain.EUR, "0.8", start)}, nil).Once()
+	source.On("Rates", mock.Anything, domain.USD, currencies).Return(nil, errors.New("unavailable")).Once().
+		Run(func(mock.Arguments) { close(failed) })
+	source.On("Rates", mock.Anything, domain.USD, currencies).Return([]Rate{rate(domain.EUR, "0.9", start.Add(2*time.Hour))}, nil)
+
+	converter.Start(ctx)
+
+	conversion, err := converter.Rate(domain.USD, domain.EUR)
+	require.NoError(t, err)
+	assert.Equal(t, "0.8", conversion.Rate.String(), "Expected the rates refreshed on start")
+
+	clk.BlockUntilDue(time.Hour)
+	clk.Advance(time.Hour)
+
+	select {
+	case <-failed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the rates refreshed after the interval")
+	}
+
+	conversion, err = converter.Rate(domain.USD, domain.EUR)
+	require.NoError(t, err)
+	assert.Equal(t, "0.8", conversion.Rate.String(), "Expected the previous rates kept on failure")
+
+	clk.BlockUntilDue(time.Hour)
+	clk.Advance(time.Hour)
+
+	assert.Eventually(t, func() bool {
+		conversion, err := converter.Rate(domain.USD, domain.EUR)
+		return err == nil && conversion.Rate.String() == "0.9"
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,59 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// fileRates is the format of the rates files, e.g.
+// {"base": "USD", "updated_at": "2025-01-01T00:00:00Z", "rates": {"EUR": "0.92", "GBP": "0.79"}}
+type fileRates struct {
+	Base      domain.Currency                     `json:"base"`
+	UpdatedAt time.Time                           `json:"updated_at"`
+	Rates     map[domain.Currency]decimal.Decimal `json:"rates"`
+}
+
+// FileSource reads the rates from a JSON file, for offline use. The file is read on every refresh, so it can be
+// updated while running.
+type FileSource struct {
+	path string
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+func (s *FileSource) Rates(_ context.Context, base domain.Currency, currencies []domain.Currency) ([]Rate, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read rates file")
+	}
+
+	var file fileRates
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, errors.Wrap(err, "failed to decode rates file")
+	}
+
+	if file.Base != base {
+		return nil, errors.Errorf("rates file has base %q, expected %q", file.Base, base)
+	}
+
+	rates := make([]Rate, 0, len(currencies))
+	for _, currency := range currencies {
+		value, ok := file.Rates[currency]
+		if !ok {
+			continue
+		}
+
+		rates = append(rates, Rate{Currency: currency, Value: value, UpdatedAt: file.UpdatedAt})
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,56 @@
+package fx
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+)
+
+// Rate is the exchange rate of a currency against the base currency of the rates: 1 base = Value currency.
+type Rate struct {
+	Currency  domain.Currency
+	Value     decimal.Decimal
+	UpdatedAt time.Time
+}
+
+// Source provides the exchange rates of currencies against a base currency. The rates it doesn't know are left out.
+type Source interface {
+	Rates(ctx context.Context, base domain.Currency, currencies []domain.Currency) ([]Rate, error)
+}
+
+type PriceAPI interface {
+	GetPrice(ctx context.Context, pair domain.Pair) (decimal.Decimal, error)
+}
+
+// PriceAPISource fetches the rates as the prices of the base currency from the prices API, e.g. USDEUR.
+type PriceAPISource struct {
+	priceAPI PriceAPI
+	clock    clock.Clock
+}
+
+func NewPriceAPISource(priceAPI PriceAPI, clk clock.Clock) *PriceAPISource {
+	return &PriceAPISource{
+		priceAPI: priceAPI,
+		clock:    clk,
+	}
+}
+
+func (s *PriceAPISource) Rates(ctx context.Context, base domain.Currency, currencies []domain.Currency) ([]Rate, error) {
+	rates := make([]Rate, 0, len(currencies))
+
+	for _, currency := range currencies {
+		price, err := s.priceAPI.GetPrice(ctx, domain.NewPair(base, currency))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch the %s rate", currency)
+		}
+
+		rates = append(rates, Rate{Currency: currency, Value: price, UpdatedAt: s.clock.Now().UTC()})
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,67 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+)
+
+type MockPriceAPI struct {
+	mock.Mock
+}
+
+func (m *MockPriceAPI) GetPrice(ctx context.Context, pair domain.Pair) (decimal.Decimal, error) {
+	args := m.Called(ctx, pair)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+
+func TestPriceAPISource_Rates(t *testing.T) {
+	var (
+		priceAPI = new(MockPriceAPI)
+		clk      = clock.NewFake(start)
+	)
+
+	priceAPI.On("GetPrice", mock.Anything, domain.NewPair(domain.USD, domain.EUR)).Return(decimal.RequireFromString("0.92"), nil)
+	priceAPI.On("GetPrice", mock.Anything, domain.NewPair(domain.USD, gbp)).Return(decimal.RequireFromString("0.79"), nil)
+
+	rates, err := NewPriceAPISource(priceAPI, clk).Rates(context.Background(), domain.USD, []domain.Currency{domain.EUR, gbp})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Rate{rate(domain.EUR, "0.92", start), rate(gbp, "0.79", start)}, rates)
+}
+
+func TestFileSource_Rates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"base": "USD",
+		"updated_at": "2025-01-01T10:00:00Z",
+		"rates": {"EUR": "0.8", "JPY": 150}
+	}`), 0o600))
+
+	source := NewFileSource(path)
+
+	t.Run("Returns the rates of the file", func(t *testing.T) {
+		rates, err := source.Rates(context.Background(), domain.USD, []domain.Currency{domain.EUR, gbp})
+		require.NoError(t, err)
+		assert.Equal(t, []Rate{rate(domain.EUR, "0.8", start)}, rates)
+	})
+
+	t.Run("Rejects a file of another base currency", func(t *testing.T) {
+		_, err := source.Rates(context.Background(), domain.EUR, []domain.Currency{domain.USD})
+		assert.ErrorContains(t, err, `rates file has base "USD"`)
+	})
+
+	t.Run("Fails on a missing file", func(t *testing.T) {
+		_, err := NewFileSource(filepath.Join(t.TempDir(), "missing.json")).Rates(context.Background(), domain.USD, nil)
+		assert.Error(t, err)
+	})
+}
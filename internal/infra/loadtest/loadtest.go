@@ -0,0 +1,158 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+)
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type Options struct {
+	// URL is the base URL of the server, e.g. http://localhost:8080
+	URL string
+	// Pairs are streamed round-robin by the connections
+	Pairs []domain.Pair
+	// Connections is the number of concurrent streams to open
+	Connections int
+	// RampUp spreads the opening of the connections evenly over its duration
+	RampUp time.Duration
+	// Duration of the whole run, ramp-up included
+	Duration time.Duration
+}
+
+func (o Options) validate() error {
+	switch {
+	case len(o.Pairs) == 0:
+		return errors.New("at least one pair is required")
+	case o.Connections <= 0:
+		return errors.New("the number of connections must be positive")
+	case o.Duration <= 0:
+		return errors.New("the duration must be positive")
+	case o.RampUp < 0 || o.RampUp >= o.Duration:
+		return errors.New("the ramp-up must be shorter than the duration")
+	}
+	return nil
+}
+
+// connection is the outcome of a single stream.
+type connection struct {
+	pair         domain.Pair
+	connected    bool
+	err          error
+	disconnected bool // the stream was closed by the server before the end of the run
+	malformed    int
+	latencies    []time.Duration
+	received     []time.Time // the received_at of every update, in arrival order
+}
+
+// Run opens the connections to the price streams of the server, ramping them up, and reads the updates until the
+// run duration elapses or ctx is done, reporting the latency, gaps and duplicates observed by the clients.
+func Run(ctx context.Context, client HTTPClient, opts Options) (Report, error) {
+	if err := opts.validate(); err != nil {
+		return Report{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	var (
+		started     = time.Now()
+		wg          sync.WaitGroup
+		connections = make([]*connection, opts.Connections)
+		baseURL     = strings.TrimSuffix(opts.URL, "/")
+	)
+
+	for i := range connections {
+		conn := &connection{pair: opts.Pairs[i%len(opts.Pairs)]}
+		connections[i] = conn
+
+		delay := opts.RampUp * time.Duration(i) / time.Duration(opts.Connections)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			// the reception times are requested in nanoseconds, to measure the latency and tell the updates apart
+			conn.stream(ctx, client, baseURL+"/prices/"+conn.pair.String()+"/stream?precision=ns")
+		}()
+	}
+
+	wg.Wait()
+
+	// an interrupted run is reported as well, up to the interruption
+	return newReport(connections, time.Since(started)), nil
+}
+
+func (c *connection) stream(ctx context.Context, client HTTPClient, streamURL string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, nil)
+	if err != nil {
+		c.err = errors.Wrap(err, "failed to create request")
+		return
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.err = errors.Wrap(err, "failed to connect to stream")
+		}
+		return
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		c.err = errors.Errorf("unexpected status code: %d", resp.StatusCode)
+		return
+	}
+
+	c.connected = true
+
+	err = sse.ReadEvents(resp.Body, func(data []byte) error {
+		now := time.Now()
+
+		var update sse.PriceStreamResponse
+		if err := json.Unmarshal(data, &update); err != nil {
+			c.malformed++
+			return nil
+		}
+
+		receivedAt, err := time.Parse(time.RFC3339Nano, update.ReceivedAt)
+		if err != nil {
+			c.malformed++
+			return nil
+		}
+
+		c.received = append(c.received, receivedAt)
+		if !update.Backfilled {
+			c.latencies = append(c.latencies, now.Sub(receivedAt))
+		}
+
+		return nil
+	})
+
+	if ctx.Err() != nil { // the run is over
+		return
+	}
+
+	c.disconnected = true
+	c.err = err
+}
@@ -0,0 +1,115 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+func TestRun(t *testing.T) {
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		base   = time.Now().Add(-50 * time.Millisecond)
+	)
+
+	event := func(at time.Time) string {
+		return fmt.Sprintf("data: {\"pair\":\"BTCUSD\",\"price\":\"50000\",\"received_at\":%q}\n\n", at.Format(time.RFC3339Nano))
+	}
+
+	t.Run("Reports the latency, gaps and duplicates of the streams", func(t *testing.T) {
+		var requests atomic.Int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/prices/BTCUSD/stream", r.URL.Path)
+			assert.Equal(t, "ns", r.URL.Query().Get("precision"))
+
+			w.Header().Set("Content-Type", "text/event-stream")
+
+			// the first stream receives every update, the second one misses one and receives another twice
+			script := []time.Time{base, base.Add(time.Millisecond), base.Add(2 * time.Millisecond)}
+			if requests.Add(1) == 2 {
+				script = []time.Time{base, base, base.Add(2 * time.Millisecond)}
+			}
+
+			for _, at := range script {
+				_, _ = fmt.Fprint(w, event(at))
+			}
+			w.(http.Flusher).Flush()
+
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		report, err := Run(context.Background(), server.Client(), Options{
+			URL:         server.URL,
+			Pairs:       []domain.Pair{btcUsd},
+			Connections: 2,
+			Duration:    200 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, report.Connections)
+		assert.Equal(t, 2, report.Connected)
+		assert.Zero(t, report.Failed)
+		assert.Zero(t, report.Disconnected)
+		assert.Equal(t, 6, report.Updates)
+		assert.Equal(t, 1, report.Duplicates)
+		assert.Equal(t, 1, report.Gaps)
+		assert.GreaterOrEqual(t, report.Latency.P50, 40*time.Millisecond)
+		assert.GreaterOrEqual(t, report.Latency.Max, report.Latency.P99)
+		assert.GreaterOrEqual(t, report.Latency.P99, report.Latency.P50)
+
+		var out bytes.Buffer
+		require.NoError(t, report.Write(&out))
+		assert.Contains(t, out.String(), "duplicates")
+		assert.Contains(t, out.String(), "p99.9")
+	})
+
+	t.Run("Reports the failed and closed connections", func(t *testing.T) {
+		var requests atomic.Int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if requests.Add(1) == 1 {
+				http.Error(w, "too many clients", http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = fmt.Fprint(w, event(base))
+		}))
+		defer server.Close()
+
+		report, err := Run(context.Background(), server.Client(), Options{
+			URL:         server.URL,
+			Pairs:       []domain.Pair{btcUsd},
+			Connections: 2,
+			RampUp:      50 * time.Millisecond,
+			Duration:    200 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.Connected)
+		assert.Equal(t, 1, report.Failed)
+		assert.Equal(t, 1, report.Disconnected)
+		assert.Equal(t, 1, report.Updates)
+		assert.Equal(t, map[string]int{"unexpected status code: 503": 1}, report.Errors)
+	})
+
+	t.Run("Rejects invalid options", func(t *testing.T) {
+		_, err := Run(context.Background(), http.DefaultClient, Options{
+			Pairs:       []domain.Pair{btcUsd},
+			Connections: 1,
+			RampUp:      time.Minute,
+			Duration:    time.Second,
+		})
+		assert.Error(t, err)
+	})
+}
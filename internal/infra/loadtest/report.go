@@ -0,0 +1,176 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type Latency struct {
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	P999 time.Duration
+	Max  time.Duration
+}
+
+// Report summarises a run. Gaps are the updates a connection missed while streaming: updates of its pair received by
+// other connections, between the first and the last update it received. Duplicates are the updates a connection
+// received more than once.
+type Report struct {
+	Elapsed      time.Duration
+	Connections  int
+	Connected    int
+	Failed       int
+	Disconnected int
+	Updates      int
+	Malformed    int
+	Duplicates   int
+	Gaps         int
+	Latency      Latency
+	Errors       map[string]int
+}
+
+func newReport(connections []*connection, elapsed time.Duration) Report {
+	report := Report{
+		Elapsed:     elapsed,
+		Connections: len(connections),
+		Errors:      make(map[string]int),
+	}
+
+	var (
+		latencies []time.Duration
+		seen      = make(map[string]map[int64]struct{}) // the updates received by any connection, per pair
+		unique    = make([][]int64, len(connections))
+	)
+
+	for i, conn := range connections {
+		if conn.connected {
+			report.Connected++
+		} else if conn.err != nil {
+			report.Failed++
+		}
+		if conn.disconnected {
+			report.Disconnected++
+		}
+		if conn.err != nil {
+			report.Errors[conn.err.Error()]++
+		}
+
+		report.Updates += len(conn.received)
+		report.Malformed += conn.malformed
+		latencies = append(latencies, conn.latencies...)
+
+		pairSeen, ok := seen[conn.pair.String()]
+		if !ok {
+			pairSeen = make(map[int64]struct{})
+			seen[conn.pair.String()] = pairSeen
+		}
+
+		received := make(map[int64]struct{}, len(conn.received))
+		for _, at := range conn.received {
+			if _, duplicated := received[at.UnixNano()]; duplicated {
+				report.Duplicates++
+				continue
+			}
+			received[at.UnixNano()] = struct{}{}
+			pairSeen[at.UnixNano()] = struct{}{}
+			unique[i] = append(unique[i], at.UnixNano())
+		}
+		slices.Sort(unique[i])
+	}
+
+	sorted := make(map[string][]int64, len(seen))
+	for pair, updates := range seen {
+		for at := range updates {
+			sorted[pair] = append(sorted[pair], at)
+		}
+		slices.Sort(sorted[pair])
+	}
+
+	for i, conn := range connections {
+		if len(unique[i]) == 0 {
+			continue
+		}
+
+		var (
+			all   = sorted[conn.pair.String()]
+			first = sort.Search(len(all), func(j int) bool { return all[j] >= unique[i][0] })
+			last  = sort.Search(len(all), func(j int) bool { return all[j] > unique[i][len(unique[i])-1] })
+		)
+		report.Gaps += last - first - len(unique[i])
+	}
+
+	report.Latency = newLatency(latencies)
+
+	return report
+}
+
+func newLatency(latencies []time.Duration) Latency {
+	if len(latencies) == 0 {
+		return Latency{}
+	}
+
+	slices.Sort(latencies)
+
+	percentile := func(p float64) time.Duration {
+		index := int(math.Ceil(p/100*float64(len(latencies)))) - 1 //nolint:mnd // percentage
+		return latencies[max(index, 0)]
+	}
+
+	return Latency{
+		P50:  percentile(50),   //nolint:mnd // percentile
+		P90:  percentile(90),   //nolint:mnd // percentile
+		P99:  percentile(99),   //nolint:mnd // percentile
+		P999: percentile(99.9), //nolint:mnd // percentile
+		Max:  latencies[len(latencies)-1],
+	}
+}
+
+// Write prints the report as a table.
+func (r Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // padding
+
+	lines := []string{
+		fmt.Sprintf("Elapsed\t%s", r.Elapsed.Round(time.Millisecond)),
+		fmt.Sprintf("Connections\t%d", r.Connections),
+		fmt.Sprintf("  connected\t%d", r.Connected),
+		fmt.Sprintf("  failed\t%d", r.Failed),
+		fmt.Sprintf("  disconnected early\t%d", r.Disconnected),
+		fmt.Sprintf("Updates\t%d", r.Updates),
+		fmt.Sprintf("  malformed\t%d", r.Malformed),
+		fmt.Sprintf("  duplicates\t%d", r.Duplicates),
+		fmt.Sprintf("  gaps\t%d", r.Gaps),
+		"Latency\t",
+		fmt.Sprintf("  p50\t%s", r.Latency.P50),
+		fmt.Sprintf("  p90\t%s", r.Latency.P90),
+		fmt.Sprintf("  p99\t%s", r.Latency.P99),
+		fmt.Sprintf("  p99.9\t%s", r.Latency.P999),
+		fmt.Sprintf("  max\t%s", r.Latency.Max),
+	}
+
+	if len(r.Errors) > 0 {
+		lines = append(lines, "Errors\t")
+
+		messages := make([]string, 0, len(r.Errors))
+		for message := range r.Errors {
+			messages = append(messages, message)
+		}
+		sort.Strings(messages)
+
+		for _, message := range messages {
+			lines = append(lines, fmt.Sprintf("  %d\t%s", r.Errors[message], message))
+		}
+	}
+
+	if _, err := fmt.Fprintln(tw, strings.Join(lines, "\n")); err != nil {
+		return err
+	}
+
+	return tw.Flush()
+}
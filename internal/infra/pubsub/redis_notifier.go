@@ -0,0 +1,131 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/codec"
+)
+
+const (
+	DefaultChannel      = "prices:updates"
+	redisPublishTimeout = 3 * time.Second
+)
+
+// priceUpdateMessage inlines the optional fields of the update, so that the backfilled and synthetic updates are
+// delivered as such by every instance.
+type priceUpdateMessage struct {
+	From       string          `json:"from"`
+	To         string          `json:"to"`
+	Price      decimal.Decimal `json:"price"`
+	ReceivedAt time.Time       `json:"received_at"`
+	*codec.Extras
+}
+
+// RedisNotifier fans out price updates to every instance subscribed to the same Redis channel: the instances
+// polling prices publish them with Broadcast, and every instance delivers them to its clients through Subscribe.
+type RedisNotifier struct {
+	log        *slog.Logger
+	client     redis.UniversalClient
+	channel    string
+	bufferSize int
+}
+
+func NewRedisNotifier(client redis.UniversalClient, channel string, bufferSize int) *RedisNotifier {
+	if channel == "" {
+		channel = DefaultChannel
+	}
+
+	return &RedisNotifier{
+		log:        slog.Default(),
+		client:     client,
+		channel:    channel,
+		bufferSize: bufferSize,
+	}
+}
+
+// Broadcast publishes the update to the other instances, and to itself.
+func (n *RedisNotifier) Broadcast(update domain.PriceUpdate) {
+	message := priceUpdateMessage{
+		From:       string(update.Pair.From),
+		To:         string(update.Pair.To),
+		Price:      update.Price,
+		ReceivedAt: update.ReceivedAt,
+		Extras:     codec.NewExtras(update),
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		n.log.Error("Failed to encode price update", "pair", update.Pair.String(), "error", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisPublishTimeout)
+	defer cancel()
+
+	if err = n.client.Publish(ctx, n.channel, payload).Err(); err != nil {
+		n.log.Error("Failed to publish price update", "pair", update.Pair.String(), "error", err.Error())
+	}
+}
+
+// Subscribe returns the updates published by every instance, until ctx is done. The subscription is confirmed
+// before returning, so no update published afterward is missed.
+func (n *RedisNotifier) Subscribe(ctx context.Context) (<-chan domain.PriceUpdate, error) {
+	sub := n.client.Subscribe(ctx, n.channel)
+
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, errors.Wrap(err, "failed to subscribe to price updates")
+	}
+
+	updates := make(chan domain.PriceUpdate, n.bufferSize)
+
+	go func() {
+		defer close(updates)
+		defer func() {
+			_ = sub.Close()
+		}()
+
+		messages := sub.Channel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var message priceUpdateMessage
+				if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+					n.log.Error("Failed to decode published price update", "error", err.Error())
+					continue
+				}
+
+				update := domain.PriceUpdate{
+					Pair:       domain.NewPair(domain.Currency(message.From), domain.Currency(message.To)),
+					Price:      message.Price,
+					ReceivedAt: message.ReceivedAt,
+				}
+				message.Extras.Apply(&update)
+
+				select {
+				case updates <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return updates, nil
+}
@@ -0,0 +1,107 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+func TestRedisNotifier(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	newClient := func() *redis.Client {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() {
+			_ = client.Close()
+		})
+		return client
+	}
+
+	t.Run("Should deliver published updates to every subscribed instance", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var (
+			publisher   = NewRedisNotifier(newClient(), "", 10)
+			subscriber1 = NewRedisNotifier(newClient(), "", 10)
+			subscriber2 = NewRedisNotifier(newClient(), "", 10)
+			update      = domain.PriceUpdate{
+				Pair:       domain.NewPair(domain.BTC, domain.USD),
+				Price:      decimal.RequireFromString("50000.123456789"),
+				ReceivedAt: time.Now().UTC(),
+				Backfilled: true,
+				Legs:       []domain.Pair{domain.NewPair(domain.BTC, domain.EUR), domain.NewPair(domain.EUR, domain.USD)},
+				Quote:      &domain.Quote{Volume24h: decimal.NewNullDecimal(decimal.RequireFromString("1234.5"))},
+			}
+		)
+
+		updates1, err := subscriber1.Subscribe(ctx)
+		require.NoError(t, err)
+		updates2, err := subscriber2.Subscribe(ctx)
+		require.NoError(t, err)
+
+		publisher.Broadcast(update)
+
+		for _, updates := range []<-chan domain.PriceUpdate{updates1, updates2} {
+			select {
+			case received := <-updates:
+				assert.Equal(t, update.Pair, received.Pair)
+				assert.True(t, update.Price.Equal(received.Price))
+				assert.True(t, update.ReceivedAt.Equal(received.ReceivedAt))
+				assert.True(t, received.Backfilled, "Expected the backfilled flag delivered")
+				assert.Equal(t, update.Legs, received.Legs, "Expected the legs of the synthetic price delivered")
+				if assert.NotNil(t, received.Quote) {
+					assert.Equal(t, "1234.5", received.Quote.Volume24h.Decimal.String())
+					assert.False(t, received.Quote.Bid.Valid)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Expected the update to be delivered")
+			}
+		}
+	})
+
+	t.Run("Should deliver the updates without optional fields as live quoted prices", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		updates, err := NewRedisNotifier(newClient(), "plain", 10).Subscribe(ctx)
+		require.NoError(t, err)
+
+		NewRedisNotifier(newClient(), "plain", 10).Broadcast(domain.PriceUpdate{
+			Pair:       domain.NewPair(domain.BTC, domain.USD),
+			Price:      decimal.RequireFromString("50000"),
+			ReceivedAt: time.Now().UTC(),
+		})
+
+		select {
+		case received := <-updates:
+			assert.False(t, received.Backfilled)
+			assert.False(t, received.Synthetic())
+			assert.Nil(t, received.Quote)
+		case <-time.After(time.Second):
+			t.Fatal("Expected the update to be delivered")
+		}
+	})
+
+	t.Run("Should close the updates channel when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		updates, err := NewRedisNotifier(newClient(), "other", 10).Subscribe(ctx)
+		require.NoError(t, err)
+
+		cancel()
+
+		assert.Eventually(t, func() bool {
+			_, ok := <-updates
+			return !ok
+		}, time.Second, 10*time.Millisecond)
+	})
+}
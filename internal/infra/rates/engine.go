@@ -0,0 +1,174 @@
+package rates
+
+import (
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// MaxLegs bounds the number of quoted pairs a synthetic price is derived through.
+const MaxLegs = 3
+
+// divisionPrecision is the number of decimals the inverted legs are divided with
+const divisionPrecision = 18
+
+// leg is an edge of the rates graph: a quoted pair, traversed from its base to its quote currency or inverted.
+type leg struct {
+	pair     domain.Pair
+	inverted bool
+}
+
+func (l leg) from() domain.Currency {
+	if l.inverted {
+		return l.pair.To
+	}
+	return l.pair.From
+}
+
+func (l leg) to() domain.Currency {
+	if l.inverted {
+		return l.pair.From
+	}
+	return l.pair.To
+}
+
+// Engine derives the prices of synthetic pairs, not quoted upstream, from the latest prices of the quoted ones, e.g.
+// ETHBTC from ETHUSD and BTCUSD, or USDBTC by inverting BTCUSD.
+type Engine struct {
+	mu      sync.Mutex
+	targets []domain.Pair
+	latest  map[domain.Pair]domain.PriceUpdate
+}
+
+func NewEngine(targets []domain.Pair) *Engine {
+	return &Engine{
+		targets: slices.Clone(targets),
+		latest:  make(map[domain.Pair]domain.PriceUpdate),
+	}
+}
+
+// Targets returns the synthetic pairs derived by the engine.
+func (e *Engine) Targets() []domain.Pair {
+	return slices.Clone(e.targets)
+}
+
+// Update records the price of a quoted pair and returns the prices of the synthetic pairs derived through it.
+// Each synthetic pair is derived through the fewest legs, among the pairs quoted so far.
+func (e *Engine) Update(update domain.PriceUpdate) []domain.PriceUpdate {
+	if update.Synthetic() || update.Backfilled {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if latest, ok := e.latest[update.Pair]; ok && latest.ReceivedAt.After(update.ReceivedAt) {
+		return nil
+	}
+	e.latest[update.Pair] = update
+
+	quoted := make([]domain.Pair, 0, len(e.latest))
+	for pair := range e.latest {
+		quoted = append(quoted, pair)
+	}
+
+	var derived []domain.PriceUpdate
+
+	for _, target := range e.targets {
+		route := findRoute(quoted, target)
+		if !slices.ContainsFunc(route, func(l leg) bool { return l.pair == update.Pair }) {
+			continue
+		}
+
+		derived = append(derived, e.derive(target, route))
+	}
+
+	return derived
+}
+
+func (e *Engine) derive(target domain.Pair, route []leg) domain.PriceUpdate {
+	var (
+		numerator   = decimal.NewFromInt(1)
+		denominator = decimal.NewFromInt(1)
+		synthetic   = domain.PriceUpdate{Pair: target, Legs: make([]domain.Pair, 0, len(route))}
+	)
+
+	for _, l := range route {
+		latest := e.latest[l.pair]
+
+		if l.inverted {
+			denominator = denominator.Mul(latest.Price)
+		} else {
+			numerator = numerator.Mul(latest.Price)
+		}
+
+		if latest.ReceivedAt.After(synthetic.ReceivedAt) {
+			synthetic.ReceivedAt = latest.ReceivedAt
+		}
+		synthetic.Legs = append(synthetic.Legs, l.pair)
+	}
+
+	synthetic.Price = numerator.DivRound(denominator, divisionPrecision)
+
+	return synthetic
+}
+
+// CanDerive tells whether the target pair can be derived from the quoted pairs, through at most MaxLegs of them.
+func CanDerive(quoted []domain.Pair, target domain.Pair) bool {
+	return findRoute(quoted, target) != nil
+}
+
+// findRoute returns the shortest route from the base to the quote currency of the target through the quoted pairs,
+// or nil when there is none within MaxLegs. Ties are broken by the pairs symbols, so routes are stable.
+func findRoute(quoted []domain.Pair, target domain.Pair) []leg {
+	quoted = slices.Clone(quoted)
+	sort.Slice(quoted, func(i, j int) bool { return quoted[i].String() < quoted[j].String() })
+
+	graph := make(map[domain.Currency][]leg)
+	for _, pair := range quoted {
+		graph[pair.From] = append(graph[pair.From], leg{pair: pair})
+		graph[pair.To] = append(graph[pair.To], leg{pair: pair, inverted: true})
+	}
+
+	// breadth-first search, remembering the leg each currency was reached through
+	var (
+		reachedBy = map[domain.Currency]leg{}
+		visited   = map[domain.Currency]bool{target.From: true}
+		frontier  = []domain.Currency{target.From}
+	)
+
+	for depth := 0; depth < MaxLegs && len(frontier) > 0; depth++ {
+		var next []domain.Currency
+
+		for _, currency := range frontier {
+			for _, l := range graph[currency] {
+				if visited[l.to()] {
+					continue
+				}
+				visited[l.to()] = true
+				reachedBy[l.to()] = l
+				next = append(next, l.to())
+			}
+		}
+
+		if visited[target.To] {
+			break
+		}
+		frontier = next
+	}
+
+	if !visited[target.To] {
+		return nil
+	}
+
+	var route []leg
+	for currency := target.To; currency != target.From; currency = reachedBy[currency].from() {
+		route = append([]leg{reachedBy[currency]}, route...)
+	}
+
+	return route
+}
@@ -0,0 +1,149 @@
+package rates
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+var (
+	btcUsd = domain.NewPair(domain.BTC, domain.USD)
+	ethUsd = domain.NewPair(domain.ETH, domain.USD)
+	ethBtc = domain.NewPair(domain.ETH, domain.BTC)
+	usdBtc = domain.NewPair(domain.USD, domain.BTC)
+	eurUsd = domain.NewPair(domain.EUR, domain.USD)
+	btcEur = domain.NewPair(domain.BTC, domain.EUR)
+	start  = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+)
+
+func quote(pair domain.Pair, price string, at time.Time) domain.PriceUpdate {
+	return domain.PriceUpdate{Pair: pair, Price: decimal.RequireFromString(price), ReceivedAt: at}
+}
+
+func TestEngine_Update(t *testing.T) {
+	t.Run("Derives a cross rate once every leg is quoted", func(t *testing.T) {
+		engine := NewEngine([]domain.Pair{ethBtc})
+
+		assert.Empty(t, engine.Update(quote(ethUsd, "3000", start)))
+
+		derived := engine.Update(quote(btcUsd, "60000", start.Add(time.Second)))
+		require.Len(t, derived, 1)
+
+		assert.Equal(t, ethBtc, derived[0].Pair)
+		assert.Equal(t, "0.05", derived[0].Price.String())
+		assert.Equal(t, start.Add(time.Second), derived[0].ReceivedAt, "Expected the time of the latest leg")
+		assert.Equal(t, []domain.Pair{ethUsd, btcUsd}, derived[0].Legs)
+		assert.True(t, derived[0].Synthetic())
+	})
+
+	t.Run("Derives again whenever any leg is updated", func(t *testing.T) {
+		engine := NewEngine([]domain.Pair{ethBtc})
+		engine.Update(quote(ethUsd, "3000", start))
+		engine.Update(quote(btcUsd, "60000", start))
+
+		derived := engine.Update(quote(ethUsd, "3300", start.Add(time.Second)))
+		require.Len(t, derived, 1)
+		assert.Equal(t, "0.055", derived[0].Price.String())
+	})
+
+	t.Run("Inverts a quoted pair", func(t *testing.T) {
+		engine := NewEngine([]domain.Pair{usdBtc})
+
+		derived := engine.Update(quote(btcUsd, "50000", start))
+		require.Len(t, derived, 1)
+		assert.Equal(t, "0.00002", derived[0].Price.String())
+		assert.Equal(t, []domain.Pair{btcUsd}, derived[0].Legs)
+	})
+
+	t.Run("Derives through inverted legs", func(t *testing.T) {
+		engine := NewEngine([]domain.Pair{btcEur})
+		engine.Update(quote(btcUsd, "55000", start))
+
+		derived := engine.Update(quote(eurUsd, "1.1", start))
+		require.Len(t, derived, 1)
+		assert.Equal(t, "50000", derived[0].Price.String())
+		assert.Equal(t, []domain.Pair{btcUsd, eurUsd}, derived[0].Legs)
+	})
+
+	t.Run("Only derives the pairs routed through the updated one", func(t *testing.T) {
+		engine := NewEngine([]domain.Pair{ethBtc, usdBtc})
+		engine.Update(quote(btcUsd, "60000", start))
+		engine.Update(quote(eurUsd, "1.1", start))
+
+		derived := engine.Update(quote(ethUsd, "3000", start))
+		require.Len(t, derived, 1)
+		assert.Equal(t, ethBtc, derived[0].Pair)
+
+		assert.Empty(t, engine.Update(quote(eurUsd, "1.2", start)))
+	})
+
+	t.Run("Ignores synthetic, backfilled and stale updates", func(t *testing.T) {
+		engine := NewEngine([]domain.Pair{usdBtc})
+		engine.Update(quote(btcUsd, "50000", start))
+
+		backfilled := quote(btcUsd, "40000", start.Add(-time.Hour))
+		backfilled.Backfilled = true
+		assert.Empty(t, engine.Update(backfilled))
+
+		synthetic := quote(btcUsd, "40000", start.Add(time.Second))
+		synthetic.Legs = []domain.Pair{ethBtc, ethUsd}
+		assert.Empty(t, engine.Update(synthetic))
+
+		assert.Empty(t, engine.Update(quote(btcUsd, "40000", start.Add(-time.Second))))
+
+		derived := engine.Update(quote(btcUsd, "50000", start.Add(time.Second)))
+		require.Len(t, derived, 1)
+		assert.Equal(t, "0.00002", derived[0].Price.String())
+	})
+}
+
+func TestCanDerive(t *testing.T) {
+	quoted := []domain.Pair{btcUsd, ethUsd, eurUsd}
+
+	assert.True(t, CanDerive(quoted, ethBtc))
+	assert.True(t, CanDerive(quoted, usdBtc))
+	assert.True(t, CanDerive(quoted, domain.NewPair(domain.ETH, domain.EUR)))
+	assert.False(t, CanDerive(quoted, domain.NewPair(domain.BTC, domain.USDT)))
+
+	t.Run("Within the maximum number of legs", func(t *testing.T) {
+		chain := []domain.Pair{
+			domain.NewPair("SOL", domain.ETH),
+			domain.NewPair(domain.ETH, domain.BTC),
+			domain.NewPair(domain.BTC, domain.USDT),
+			domain.NewPair(domain.USDT, domain.USD),
+		}
+
+		assert.True(t, CanDerive(chain, domain.NewPair("SOL", domain.USDT)))
+		assert.False(t, CanDerive(chain, domain.NewPair("SOL", domain.USD)))
+	})
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Broadcast(update domain.PriceUpdate) {
+	m.Called(update)
+}
+
+func TestTriangulatingNotifier_Broadcast(t *testing.T) {
+	var (
+		notifier = new(MockNotifier)
+		update   = quote(btcUsd, "50000", start)
+	)
+
+	notifier.On("Broadcast", update).Return().Once()
+	notifier.On("Broadcast", mock.MatchedBy(func(u domain.PriceUpdate) bool {
+		return u.Pair == usdBtc && u.Synthetic()
+	})).Return().Once()
+
+	NewTriangulatingNotifier(notifier, NewEngine([]domain.Pair{usdBtc})).Broadcast(update)
+
+	notifier.AssertExpectations(t)
+}
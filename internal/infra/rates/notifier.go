@@ -0,0 +1,30 @@
+package rates
+
+import (
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+type Notifier interface {
+	Broadcast(update domain.PriceUpdate)
+}
+
+// TriangulatingNotifier broadcasts the updates of the quoted pairs, followed by the synthetic ones derived from them.
+type TriangulatingNotifier struct {
+	notifier Notifier
+	engine   *Engine
+}
+
+func NewTriangulatingNotifier(notifier Notifier, engine *Engine) *TriangulatingNotifier {
+	return &TriangulatingNotifier{
+		notifier: notifier,
+		engine:   engine,
+	}
+}
+
+func (n *TriangulatingNotifier) Broadcast(update domain.PriceUpdate) {
+	n.notifier.Broadcast(update)
+
+	for _, synthetic := range n.engine.Update(update) {
+		n.notifier.Broadcast(synthetic)
+	}
+}
@@ -0,0 +1,291 @@
+package snapshot
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"iter"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/codec"
+)
+
+// A snapshot is a gzip compressed NDJSON file: a header line followed by one line per price update, each one
+// carrying the CRC32 of its fields so that corrupted entries are detected and skipped on restore.
+// Version 2 adds the optional fields of the updates and the weight of the averaged prices, the entries without them
+// are the same as in version 1.
+const (
+	formatName      = "crypto-pricing-api/snapshot"
+	formatVersion   = 2
+	formatVersionV1 = 1
+	maxLineSize     = 64 * 1024
+)
+
+// Source is a repository whose state can be snapshotted.
+type Source interface {
+	Pairs() []domain.Pair
+	GetSince(pair domain.Pair, since time.Time) []domain.PriceUpdate
+}
+
+// WeightedSource is a source holding averaged prices, reporting the number of raw prices each one stands for.
+type WeightedSource interface {
+	Weighted(pair domain.Pair) iter.Seq2[domain.PriceUpdate, int64]
+}
+
+// Sink is a repository where a snapshot can be restored.
+type Sink interface {
+	Store(priceUpdate domain.PriceUpdate)
+}
+
+// WeightedSink is a sink restoring the averaged prices with the number of raw prices they stand for.
+type WeightedSink interface {
+	StoreWeighted(priceUpdate domain.PriceUpdate, weight int64)
+}
+
+type Stats struct {
+	Pairs   int   `json:"pairs"`
+	Updates int   `json:"updates"`
+	Skipped int   `json:"skipped"`
+	Bytes   int64 `json:"bytes"`
+	// Truncated reports a restored snapshot whose end could not be read
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type entry struct {
+	From       string        `json:"from"`
+	To         string        `json:"to"`
+	Price      string        `json:"price"`
+	ReceivedAt int64         `json:"received_at"`      // unix nano
+	Weight     int64         `json:"weight,omitempty"` // raw prices averaged, omitted for the raw ones
+	Extras     *codec.Extras `json:"extras,omitempty"`
+	Checksum   uint32        `json:"crc32"`
+}
+
+func (e entry) checksum() uint32 {
+	fields := fmt.Sprintf("%s|%s|%s|%d", e.From, e.To, e.Price, e.ReceivedAt)
+
+	if e.Weight > 1 {
+		fields += "|" + strconv.FormatInt(e.Weight, 10)
+	}
+
+	if e.Extras != nil {
+		extras, _ := json.Marshal(e.Extras)
+		fields += "|" + string(extras)
+	}
+
+	return crc32.ChecksumIEEE([]byte(fields))
+}
+
+// Write snapshots every pair of the source into path. The file is written aside, under a name of its own so that
+// concurrent writes don't interleave, and renamed once synced, so an existing snapshot is only replaced by a
+// complete one.
+func Write(path string, source Source) (Stats, error) {
+	var (
+		stats Stats
+		dir   = filepath.Dir(path)
+	)
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return stats, errors.Wrap(err, "failed to create snapshot directory")
+	}
+
+	file, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return stats, errors.Wrap(err, "failed to create snapshot file")
+	}
+
+	tmpPath := file.Name()
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(tmpPath) // no-op once renamed
+	}()
+
+	gz := gzip.NewWriter(file)
+	encoder := json.NewEncoder(gz)
+
+	if err = encoder.Encode(header{Format: formatName, Version: formatVersion, CreatedAt: time.Now().UTC()}); err != nil {
+		return stats, errors.Wrap(err, "failed to write snapshot header")
+	}
+
+	for _, pair := range source.Pairs() {
+		written := 0
+
+		for update, weight := range weightedUpdates(source, pair) {
+			e := entry{
+				From:       string(update.Pair.From),
+				To:         string(update.Pair.To),
+				Price:      update.Price.String(),
+				ReceivedAt: update.ReceivedAt.UnixNano(),
+				Extras:     codec.NewExtras(update),
+			}
+			if weight > 1 {
+				e.Weight = weight
+			}
+			e.Checksum = e.checksum()
+
+			if err = encoder.Encode(e); err != nil {
+				return stats, errors.Wrap(err, "failed to write snapshot entry")
+			}
+
+			written++
+		}
+
+		if written > 0 {
+			stats.Pairs++
+			stats.Updates += written
+		}
+	}
+
+	if err = gz.Close(); err != nil {
+		return stats, errors.Wrap(err, "failed to compress snapshot")
+	}
+
+	if err = file.Sync(); err != nil {
+		return stats, errors.Wrap(err, "failed to sync snapshot file")
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return stats, errors.Wrap(err, "failed to stat snapshot file")
+	}
+	stats.Bytes = info.Size()
+
+	if err = os.Rename(tmpPath, path); err != nil {
+		return stats, errors.Wrap(err, "failed to replace snapshot file")
+	}
+
+	if err = syncDir(dir); err != nil {
+		return stats, errors.Wrap(err, "failed to sync snapshot directory")
+	}
+
+	return stats, nil
+}
+
+// weightedUpdates iterates over the updates of the pair, weighted by 1 unless the source reports their weight.
+func weightedUpdates(source Source, pair domain.Pair) iter.Seq2[domain.PriceUpdate, int64] {
+	if weighted, ok := source.(WeightedSource); ok {
+		return weighted.Weighted(pair)
+	}
+
+	return func(yield func(domain.PriceUpdate, int64) bool) {
+		for _, update := range source.GetSince(pair, time.Time{}) {
+			if !yield(update, 1) {
+				return
+			}
+		}
+	}
+}
+
+// syncDir persists the entries of the directory, e.g. a file renamed into it.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = d.Close()
+	}()
+
+	return d.Sync()
+}
+
+// Restore stores the updates of the snapshot at path into the sink, with their weight if it is a WeightedSink.
+// Corrupted entries are skipped, and a truncated snapshot is restored up to the last complete entry. A snapshot of
+// an unknown format or version is rejected.
+func Restore(path string, sink Sink) (Stats, error) {
+	var stats Stats
+
+	file, err := os.Open(path)
+	if err != nil {
+		return stats, errors.Wrap(err, "failed to open snapshot file")
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return stats, errors.Wrap(err, "failed to decompress snapshot")
+	}
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+
+	if !scanner.Scan() {
+		return stats, errors.New("snapshot header is missing")
+	}
+
+	var h header
+	if err = json.Unmarshal(scanner.Bytes(), &h); err != nil || h.Format != formatName {
+		return stats, errors.New("not a snapshot file")
+	}
+	if h.Version != formatVersion && h.Version != formatVersionV1 {
+		return stats, errors.Errorf("unsupported snapshot version %d", h.Version)
+	}
+
+	var (
+		pairs       = make(map[domain.Pair]struct{})
+		weighted, _ = sink.(WeightedSink)
+	)
+
+	for scanner.Scan() {
+		update, weight, ok := decodeEntry(scanner.Bytes())
+		if !ok {
+			stats.Skipped++
+			continue
+		}
+
+		if weighted != nil && weight > 1 {
+			weighted.StoreWeighted(update, weight)
+		} else {
+			sink.Store(update)
+		}
+
+		stats.Updates++
+		pairs[update.Pair] = struct{}{}
+	}
+
+	stats.Pairs = len(pairs)
+
+	// gzip checksums the whole stream: a truncated or corrupted tail is flagged, keeping what was restored
+	stats.Truncated = scanner.Err() != nil
+
+	return stats, nil
+}
+
+// decodeEntry returns the update of the line along with its weight, 0 for a raw one.
+func decodeEntry(line []byte) (domain.PriceUpdate, int64, bool) {
+	var e entry
+	if err := json.Unmarshal(line, &e); err != nil || e.Checksum != e.checksum() {
+		return domain.PriceUpdate{}, 0, false
+	}
+
+	price, err := decimal.NewFromString(e.Price)
+	if err != nil {
+		return domain.PriceUpdate{}, 0, false
+	}
+
+	update := domain.PriceUpdate{
+		Pair:       domain.NewPair(domain.Currency(e.From), domain.Currency(e.To)),
+		Price:      price,
+		ReceivedAt: time.Unix(0, e.ReceivedAt).UTC(),
+	}
+	e.Extras.Apply(&update)
+
+	return update, e.Weight, true
+}
@@ -0,0 +1,249 @@
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/downsampling"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/in_memory"
+)
+
+func newTestRepository(t *testing.T) *in_memory.PricesByRingBuffer {
+	repo := in_memory.NewPricesByRingBuffer(100)
+	start := time.Now().Add(-time.Minute).UTC()
+
+	for _, pair := range []domain.Pair{domain.NewPair(domain.BTC, domain.USD), domain.NewPair(domain.ETH, domain.USD)} {
+		for i := 0; i < 5; i++ {
+			repo.Store(domain.PriceUpdate{
+				Pair:       pair,
+				Price:      decimal.RequireFromString("1000.123456789").Add(decimal.NewFromInt(int64(i))),
+				ReceivedAt: start.Add(time.Duration(i) * time.Second),
+			})
+		}
+	}
+
+	return repo
+}
+
+// rewrite decompresses the snapshot, applies change to its content and compresses it back.
+func rewrite(t *testing.T, path string, change func(string) string) {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err = w.Write([]byte(change(string(content))))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+}
+
+func TestWriteAndRestore(t *testing.T) {
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		source = newTestRepository(t)
+	)
+
+	t.Run("Should restore every update", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "snapshots", "prices.snapshot")
+
+		written, err := Write(path, source)
+		require.NoError(t, err)
+		assert.Equal(t, 2, written.Pairs)
+		assert.Equal(t, 10, written.Updates)
+		assert.Positive(t, written.Bytes)
+
+		restored := in_memory.NewPricesByRingBuffer(100)
+		stats, err := Restore(path, restored)
+		require.NoError(t, err)
+		assert.Equal(t, Stats{Pairs: 2, Updates: 10}, stats)
+
+		assert.Equal(t, source.GetAll(btcUsd), restored.GetAll(btcUsd))
+	})
+
+	t.Run("Should restore the optional fields of the updates", func(t *testing.T) {
+		var (
+			path    = filepath.Join(t.TempDir(), "prices.snapshot")
+			quoted  = in_memory.NewPricesByRingBuffer(10)
+			ethBtc  = domain.NewPair(domain.ETH, domain.BTC)
+			updates = []domain.PriceUpdate{
+				{
+					Pair:       ethBtc,
+					Price:      decimal.RequireFromString("0.05"),
+					ReceivedAt: time.Now().Add(-time.Second).UTC(),
+					Backfilled: true,
+				},
+				{
+					Pair:       ethBtc,
+					Price:      decimal.RequireFromString("0.051"),
+					ReceivedAt: time.Now().UTC(),
+					Legs:       []domain.Pair{domain.NewPair(domain.ETH, domain.USD), domain.NewPair(domain.BTC, domain.USD)},
+					Quote:      &domain.Quote{Bid: decimal.NewNullDecimal(decimal.RequireFromString("0.0509"))},
+				},
+			}
+		)
+
+		for _, update := range updates {
+			quoted.Store(update)
+		}
+
+		_, err := Write(path, quoted)
+		require.NoError(t, err)
+
+		restored := in_memory.NewPricesByRingBuffer(10)
+		stats, err := Restore(path, restored)
+		require.NoError(t, err)
+		assert.Zero(t, stats.Skipped)
+
+		all := restored.GetAll(ethBtc)
+		require.Len(t, all, 2)
+		assert.True(t, all[0].Backfilled)
+		assert.Equal(t, updates[1].Legs, all[1].Legs)
+		require.NotNil(t, all[1].Quote)
+		assert.Equal(t, "0.0509", all[1].Quote.Bid.Decimal.String())
+	})
+
+	t.Run("Should restore the averaged prices with their weight", func(t *testing.T) {
+		var (
+			path   = filepath.Join(t.TempDir(), "prices.snapshot")
+			policy = downsampling.Policy{{Resolution: 0, Retention: time.Hour}, {Resolution: time.Minute, Retention: 24 * time.Hour}}
+			now    = time.Now().UTC()
+			bucket = now.Add(-2 * time.Hour).Truncate(time.Minute)
+			repo   = downsampling.NewPricesByResolution(policy, 0)
+		)
+
+		for i, price := range []int64{10, 20, 30} {
+			repo.Store(domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(price), ReceivedAt: bucket.Add(time.Duration(i) * time.Second)})
+		}
+		repo.Compact(now)
+
+		_, err := Write(path, repo)
+		require.NoError(t, err)
+
+		restored := downsampling.NewPricesByResolution(policy, 0)
+		_, err = Restore(path, restored)
+		require.NoError(t, err)
+		restored.Compact(now)
+
+		restored.Store(domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(40), ReceivedAt: bucket.Add(30 * time.Second)})
+		restored.Compact(now)
+
+		all := restored.GetSince(btcUsd, time.Time{})
+		require.Len(t, all, 1)
+		assert.Equal(t, "25", all[0].Price.String(), "Expected the restored average to weigh 3 prices")
+	})
+
+	t.Run("Should not interleave concurrent writes", func(t *testing.T) {
+		var (
+			dir  = t.TempDir()
+			path = filepath.Join(dir, "prices.snapshot")
+			wg   sync.WaitGroup
+		)
+
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := Write(path, source)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		stats, err := Restore(path, in_memory.NewPricesByRingBuffer(100))
+		require.NoError(t, err)
+		assert.Equal(t, Stats{Pairs: 2, Updates: 10}, stats)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "Expected the temporary files to be removed")
+	})
+
+	t.Run("Should restore version 1 snapshots", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "prices.snapshot")
+		_, err := Write(path, source)
+		require.NoError(t, err)
+
+		rewrite(t, path, func(content string) string {
+			return strings.Replace(content, `"version":2`, `"version":1`, 1)
+		})
+
+		restored := in_memory.NewPricesByRingBuffer(100)
+		stats, err := Restore(path, restored)
+		require.NoError(t, err)
+		assert.Equal(t, Stats{Pairs: 2, Updates: 10}, stats)
+	})
+
+	t.Run("Should skip corrupted entries", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "prices.snapshot")
+		_, err := Write(path, source)
+		require.NoError(t, err)
+
+		rewrite(t, path, func(content string) string {
+			lines := strings.Split(content, "\n")
+			lines[1] = strings.Replace(lines[1], "1000.", "9000.", 1) // checksum mismatch
+			lines[2] = lines[2][:10]                                  // invalid json
+			return strings.Join(lines, "\n")
+		})
+
+		restored := in_memory.NewPricesByRingBuffer(100)
+		stats, err := Restore(path, restored)
+		require.NoError(t, err)
+		assert.Equal(t, 8, stats.Updates)
+		assert.Equal(t, 2, stats.Skipped)
+
+		// the corrupted entries belong to the pair written first, which depends on the repository iteration order
+		ethUsd := domain.NewPair(domain.ETH, domain.USD)
+		assert.ElementsMatch(t, []int{3, 5}, []int{len(restored.GetAll(btcUsd)), len(restored.GetAll(ethUsd))})
+	})
+
+	t.Run("Should restore a truncated snapshot up to the corruption", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "prices.snapshot")
+		_, err := Write(path, source)
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, content[:len(content)-4], 0o600))
+
+		stats, err := Restore(path, in_memory.NewPricesByRingBuffer(100))
+		require.NoError(t, err)
+		assert.True(t, stats.Truncated)
+		assert.Equal(t, 10, stats.Updates, "Expected the entries before the gzip trailer to be restored")
+	})
+
+	t.Run("Should reject unknown formats and versions", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "prices.snapshot")
+		_, err := Write(path, source)
+		require.NoError(t, err)
+
+		rewrite(t, path, func(content string) string {
+			return strings.Replace(content, `"version":2`, `"version":3`, 1)
+		})
+
+		_, err = Restore(path, in_memory.NewPricesByRingBuffer(100))
+		assert.ErrorContains(t, err, "unsupported snapshot version 3")
+
+		require.NoError(t, os.WriteFile(path, []byte("not gzip"), 0o600))
+		_, err = Restore(path, in_memory.NewPricesByRingBuffer(100))
+		assert.Error(t, err)
+	})
+}
@@ -6,9 +6,11 @@ import (
 	"log/slog"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 
 	"github.com/tonytcb/crypto-pricing-api/internal/domain"
 )
@@ -21,15 +23,96 @@ type Client struct {
 	writer  http.ResponseWriter
 	flusher http.Flusher
 	done    chan struct{}
+	closing sync.Once
+	sent    atomic.Uint64
+	render  Renderer
+}
+
+// Renderer turns a price update into the data of the event streamed to a client.
+type Renderer func(update domain.PriceUpdate) (any, error)
+
+// RenderPrice renders the price of an update, the default.
+func RenderPrice(update domain.PriceUpdate) (any, error) {
+	return NewPriceStreamResponse(update), nil
 }
 
 type PriceStreamResponse struct {
-	Pair       string `json:"pair"`
-	Price      string `json:"price"`
-	ReceivedAt string `json:"received_at"`
+	Pair       string   `json:"pair"`
+	Price      string   `json:"price"`
+	ReceivedAt string   `json:"received_at"`
+	Backfilled bool     `json:"backfilled,omitempty"`
+	Synthetic  bool     `json:"synthetic,omitempty"`
+	Legs       []string `json:"legs,omitempty"`
+	*QuoteResponse
+	Conversion *ConversionResponse `json:"conversion,omitempty"`
+}
+
+// QuoteResponse holds the market data of a price, only set in full responses. The values not reported upstream
+// are omitted.
+type QuoteResponse struct {
+	Bid            string `json:"bid,omitempty"`
+	Ask            string `json:"ask,omitempty"`
+	Spread         string `json:"spread,omitempty"`
+	Volume24h      string `json:"volume_24h,omitempty"`
+	QuoteVolume24h string `json:"quote_volume_24h,omitempty"`
+	High24h        string `json:"high_24h,omitempty"`
+	Low24h         string `json:"low_24h,omitempty"`
+	ChangePct24h   string `json:"change_pct_24h,omitempty"`
+}
+
+// ConversionResponse is the FX rate a price was converted with, from the quote currency of the pair, and the time
+// the rate was updated at.
+type ConversionResponse struct {
+	From   string `json:"from"`
+	Rate   string `json:"fx_rate"`
+	RateAt string `json:"fx_rate_at"`
+}
+
+func NewPriceStreamResponse(update domain.PriceUpdate) PriceStreamResponse {
+	response := PriceStreamResponse{
+		Pair:       update.Pair.String(),
+		Price:      update.Price.String(),
+		ReceivedAt: update.ReceivedAt.Format(time.RFC3339),
+		Backfilled: update.Backfilled,
+		Synthetic:  update.Synthetic(),
+	}
+
+	for _, leg := range update.Legs {
+		response.Legs = append(response.Legs, leg.String())
+	}
+
+	return response
+}
+
+// NewFullPriceStreamResponse returns the response of an update along with its quote, when it has one.
+func NewFullPriceStreamResponse(update domain.PriceUpdate) PriceStreamResponse {
+	response := NewPriceStreamResponse(update)
+
+	if update.Quote != nil {
+		response.QuoteResponse = &QuoteResponse{
+			Bid:            formatNullDecimal(update.Quote.Bid),
+			Ask:            formatNullDecimal(update.Quote.Ask),
+			Spread:         formatNullDecimal(update.Quote.Spread()),
+			Volume24h:      formatNullDecimal(update.Quote.Volume24h),
+			QuoteVolume24h: formatNullDecimal(update.Quote.QuoteVolume24h),
+			High24h:        formatNullDecimal(update.Quote.High24h),
+			Low24h:         formatNullDecimal(update.Quote.Low24h),
+			ChangePct24h:   formatNullDecimal(update.Quote.ChangePct24h),
+		}
+	}
+
+	return response
+}
+
+func formatNullDecimal(value decimal.NullDecimal) string {
+	if !value.Valid {
+		return ""
+	}
+	return value.Decimal.String()
 }
 
-func NewClient(id string, w http.ResponseWriter, bufferSize int) (*Client, error) {
+// NewClient streams the updates to w, as rendered by render, e.g. RenderPrice.
+func NewClient(id string, w http.ResponseWriter, bufferSize int, render Renderer) (*Client, error) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		return nil, errors.New("response writer does not support streaming")
@@ -42,6 +125,7 @@ func NewClient(id string, w http.ResponseWriter, bufferSize int) (*Client, error
 		writer:  w,
 		flusher: flusher,
 		done:    make(chan struct{}),
+		render:  render,
 	}
 
 	return client, nil
@@ -51,6 +135,11 @@ func (c *Client) ID() string {
 	return c.id
 }
 
+// EventsSent returns the number of events written to the client stream so far.
+func (c *Client) EventsSent() uint64 {
+	return c.sent.Load()
+}
+
 func (c *Client) Send(update domain.PriceUpdate) error {
 	select {
 	case c.ch <- update:
@@ -80,13 +169,13 @@ func (c *Client) Listen(pair domain.Pair) {
 }
 
 func (c *Client) writeUpdate(update domain.PriceUpdate) error {
-	response := PriceStreamResponse{
-		Pair:       update.Pair.String(),
-		Price:      update.Price.String(),
-		ReceivedAt: update.ReceivedAt.Format(time.RFC3339),
+	event, err := c.render(update)
+	if err != nil {
+		c.log.Warn("Skipping update the client can't be sent", "pair", update.Pair.String(), "client_id", c.id, "error", err.Error())
+		return nil
 	}
 
-	data, err := json.Marshal(response)
+	data, err := json.Marshal(event)
 	if err != nil {
 		return err
 	}
@@ -99,18 +188,19 @@ func (c *Client) writeUpdate(update domain.PriceUpdate) error {
 		return errors.Wrap(err, "failed to stream update to client")
 	}
 
+	// counted before flushing, so that the event is counted once the client receives it
+	c.sent.Add(1)
+
 	c.flusher.Flush()
 
 	return nil
 }
 
+// Close stops the client, which may be closed by both the hub and its handler concurrently.
 func (c *Client) Close() {
-	select {
-	case <-c.done:
-		return
-	default:
+	c.closing.Do(func() {
 		close(c.done)
-	}
+	})
 }
 
 func (c *Client) IsClosed() bool {
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,7 +21,7 @@ import (
 func TestNewClient(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		w := mocks.NewThreadSafeRecorder()
-		client, err := NewClient("test-client-1", w, 10)
+		client, err := NewClient("test-client-1", w, 10, RenderPrice)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, client)
@@ -30,7 +31,7 @@ func TestNewClient(t *testing.T) {
 
 	t.Run("Error - Writer does not support flushing", func(t *testing.T) {
 		mockWriter := &mockResponseWriter{}
-		client, err := NewClient("test-client-1", mockWriter, 10)
+		client, err := NewClient("test-client-1", mockWriter, 10, RenderPrice)
 
 		assert.Error(t, err)
 		assert.Nil(t, client)
@@ -40,7 +41,7 @@ func TestNewClient(t *testing.T) {
 
 func TestClient_ID(t *testing.T) {
 	w := mocks.NewThreadSafeRecorder()
-	client, err := NewClient("test-client-id", w, 10)
+	client, err := NewClient("test-client-id", w, 10, RenderPrice)
 	require.NoError(t, err)
 
 	assert.Equal(t, "test-client-id", client.ID())
@@ -51,7 +52,7 @@ func TestClient_Send(t *testing.T) {
 
 	t.Run("Buffer full", func(t *testing.T) {
 		w := mocks.NewThreadSafeRecorder()
-		client, err := NewClient("test-client-1", w, 1) // Buffer size of 1
+		client, err := NewClient("test-client-1", w, 1, RenderPrice) // Buffer size of 1
 		require.NoError(t, err)
 
 		btcUsd := domain.NewPair(domain.BTC, domain.USD)
@@ -77,7 +78,7 @@ func TestClient_Send(t *testing.T) {
 func TestClient_Listen(t *testing.T) {
 	t.Run("Receive updates for registered pair", func(t *testing.T) {
 		w := mocks.NewThreadSafeRecorder()
-		client, err := NewClient("test-client-1", w, 10)
+		client, err := NewClient("test-client-1", w, 10, RenderPrice)
 		require.NoError(t, err)
 
 		btcUsd := domain.NewPair(domain.BTC, domain.USD)
@@ -91,34 +92,54 @@ func TestClient_Listen(t *testing.T) {
 
 		require.NoError(t, client.Send(update))
 
-		// Check the response
-		assert.Eventually(t, func() bool {
-			response := w.BodyString()
+		require.True(t, w.WaitFlushed(), "Client should receive the update for registered pair")
 
-			if !strings.Contains(response, "data: ") {
-				return false
-			}
+		response := w.BodyString()
+		require.True(t, strings.HasPrefix(response, "data: "))
 
-			// Parse the JSON from the response
-			jsonStr := strings.TrimPrefix(strings.TrimSuffix(response, "\n\n"), "data: ")
-			var respObj PriceStreamResponse
-			err := json.Unmarshal([]byte(jsonStr), &respObj)
-			if err != nil {
-				return false
-			}
+		var respObj PriceStreamResponse
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSuffix(response, "\n\n"), "data: ")), &respObj))
+		assert.Equal(t, "BTCUSD", respObj.Pair)
+		assert.Equal(t, "50000", respObj.Price)
+		assert.NotEmpty(t, respObj.ReceivedAt)
 
-			return respObj.Pair == "BTCUSD" &&
-				respObj.Price == "50000" &&
-				respObj.ReceivedAt != ""
-		}, 100*time.Millisecond, 10*time.Millisecond, "Client should receive the update for registered pair")
+		assert.Equal(t, uint64(1), client.EventsSent(), "Client should count the delivered event")
 
 		// Close the client to stop the listener
 		client.Close()
 	})
 
+	t.Run("Render updates with the client renderer", func(t *testing.T) {
+		w := mocks.NewThreadSafeRecorder()
+		client, err := NewClient("test-client-1", w, 10, func(update domain.PriceUpdate) (any, error) {
+			if update.Price.IsZero() {
+				return nil, errors.New("no price")
+			}
+			return NewFullPriceStreamResponse(update), nil
+		})
+		require.NoError(t, err)
+
+		btcUsd := domain.NewPair(domain.BTC, domain.USD)
+		go client.Listen(btcUsd)
+
+		require.NoError(t, client.Send(domain.PriceUpdate{Pair: btcUsd, ReceivedAt: time.Now()}))
+		require.NoError(t, client.Send(domain.PriceUpdate{
+			Pair:       btcUsd,
+			Price:      decimal.NewFromFloat(50000),
+			ReceivedAt: time.Now(),
+			Quote:      &domain.Quote{Volume24h: decimal.NewNullDecimal(decimal.NewFromInt(1200))},
+		}))
+
+		require.True(t, w.WaitFlushed(), "Client should receive the full quote")
+		assert.Contains(t, w.BodyString(), `"volume_24h":"1200"`)
+		assert.Equal(t, uint64(1), client.EventsSent(), "Expected the update that failed to render to be skipped")
+
+		client.Close()
+	})
+
 	t.Run("Ignore updates for unregistered pair", func(t *testing.T) {
 		w := mocks.NewThreadSafeRecorder()
-		client, err := NewClient("test-client-1", w, 10)
+		client, err := NewClient("test-client-1", w, 10, RenderPrice)
 		require.NoError(t, err)
 
 		btcUsd := domain.NewPair(domain.BTC, domain.USD)
@@ -132,19 +153,20 @@ func TestClient_Listen(t *testing.T) {
 		go client.Listen(btcUsd)
 
 		require.NoError(t, client.Send(update))
+		require.NoError(t, client.Send(domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromFloat(50000), ReceivedAt: time.Now()}))
 
-		// Check the response - should remain empty as the update should be ignored
-		assert.Eventually(t, func() bool {
-			response := w.BodyString()
-			return response == ""
-		}, 100*time.Millisecond, 10*time.Millisecond, "Response should remain empty for unregistered pair")
+		// the updates are handled in order, so the first one was ignored once the second one is received
+		require.True(t, w.WaitFlushed(), "Client should receive the update for registered pair")
+		assert.NotContains(t, w.BodyString(), "ETHUSD", "Expected the update for unregistered pair to be ignored")
+		assert.Contains(t, w.BodyString(), "BTCUSD")
+		assert.Equal(t, uint64(1), client.EventsSent())
 
 		client.Close()
 	})
 
 	t.Run("Stop listening when client is closed", func(t *testing.T) {
 		w := mocks.NewThreadSafeRecorder()
-		client, err := NewClient("test-client-1", w, 10)
+		client, err := NewClient("test-client-1", w, 10, RenderPrice)
 		require.NoError(t, err)
 
 		btcUsd := domain.NewPair(domain.BTC, domain.USD)
@@ -154,27 +176,28 @@ func TestClient_Listen(t *testing.T) {
 			ReceivedAt: time.Now(),
 		}
 
-		go client.Listen(btcUsd)
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			client.Listen(btcUsd)
+		}()
 
 		require.NoError(t, client.Send(update))
 
-		assert.Eventually(t, func() bool {
-			response := w.BodyString()
-			return strings.Contains(response, "data: ")
-		}, 100*time.Millisecond, 10*time.Millisecond, "Client should receive the update")
+		require.True(t, w.WaitFlushed(), "Client should receive the update")
+		assert.Contains(t, w.BodyString(), "data: ")
 
 		w.Reset()
 
 		client.Close()
+		<-stopped
 
 		update.Price = decimal.NewFromFloat(55000)
 
 		assert.NoError(t, client.Send(update))
 
-		assert.Eventually(t, func() bool {
-			response := w.BodyString()
-			return client.IsClosed() && response == ""
-		}, 100*time.Millisecond, 10*time.Millisecond, "Client should be closed and not process updates")
+		assert.True(t, client.IsClosed())
+		assert.Empty(t, w.BodyString(), "Client should be closed and not process updates")
 	})
 }
 
@@ -207,3 +230,43 @@ func (m *mockResponseWriter) WriteHeader(statusCode int) {
 func newNoopLogger() *slog.Logger {
 	return slog.New(slog.NewTextHandler(io.Discard, nil))
 }
+
+func TestNewFullPriceStreamResponse(t *testing.T) {
+	update := domain.PriceUpdate{
+		Pair:       domain.NewPair(domain.BTC, domain.USD),
+		Price:      decimal.RequireFromString("50000"),
+		ReceivedAt: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("Keeps the default shape without a quote", func(t *testing.T) {
+		data, err := json.Marshal(NewFullPriceStreamResponse(update))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"pair":"BTCUSD","price":"50000","received_at":"2025-01-01T10:00:00Z"}`, string(data))
+	})
+
+	t.Run("Adds the quote values reported", func(t *testing.T) {
+		update.Quote = &domain.Quote{
+			Bid:          decimal.NewNullDecimal(decimal.RequireFromString("49999.5")),
+			Ask:          decimal.NewNullDecimal(decimal.RequireFromString("50000.5")),
+			Volume24h:    decimal.NewNullDecimal(decimal.RequireFromString("1200.25")),
+			ChangePct24h: decimal.NewNullDecimal(decimal.RequireFromString("-1.5")),
+		}
+
+		data, err := json.Marshal(NewFullPriceStreamResponse(update))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"pair": "BTCUSD",
+			"price": "50000",
+			"received_at": "2025-01-01T10:00:00Z",
+			"bid": "49999.5",
+			"ask": "50000.5",
+			"spread": "1",
+			"volume_24h": "1200.25",
+			"change_pct_24h": "-1.5"
+		}`, string(data))
+
+		data, err = json.Marshal(NewPriceStreamResponse(update))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "bid", "Expected the default response to omit the quote")
+	})
+}
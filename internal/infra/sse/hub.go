@@ -2,44 +2,67 @@ package sse
 
 import (
 	"log/slog"
+	"math"
 	"sync"
 	"time"
 
 	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
 )
 
 type PricesRepository interface {
 	Store(priceUpdate domain.PriceUpdate)
+	GetLatest(pair domain.Pair) (domain.PriceUpdate, bool)
 	GetSince(pair domain.Pair, since time.Time) []domain.PriceUpdate
 }
 
+// rangeReader is implemented by the repositories able to bound the number of prices read.
+type rangeReader interface {
+	GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate
+}
+
+// retentionReporter is implemented by the repositories that may not hold the whole history of a pair.
+type retentionReporter interface {
+	AvailableSince(pair domain.Pair) (time.Time, bool)
+}
+
+// maxTime is the latest time representable in nanoseconds, bounding the history ranges read until now.
+var maxTime = time.Unix(0, math.MaxInt64)
+
 type Hub struct {
-	mu              sync.RWMutex
-	pricesRepo      PricesRepository
-	cleanUpInterval time.Duration
-	log             *slog.Logger
-	clients         map[*Client]struct{}
-	register        chan *Client
-	unregister      chan *Client
-	broadcast       chan domain.PriceUpdate
-	done            chan struct{}
-}
-
-func NewHub(pricesRepo PricesRepository, cleanUpInterval time.Duration) *Hub {
+	mu                     sync.RWMutex
+	pricesRepo             PricesRepository
+	cleanUpInterval        time.Duration
+	cleanUpIntervalChanged chan struct{}
+	log                    *slog.Logger
+	clock                  clock.Clock
+	clients                map[*Client]struct{}
+	latest                 map[domain.Pair]domain.PriceUpdate
+	register               chan *Client
+	unregister             chan *Client
+	broadcast              chan domain.PriceUpdate
+	done                   chan struct{}
+}
+
+// NewHub buffers up to bufferSize updates broadcast while the previous ones are sent, dropping the next ones.
+func NewHub(pricesRepo PricesRepository, cleanUpInterval time.Duration, bufferSize int, clk clock.Clock) *Hub {
 	return &Hub{
-		pricesRepo:      pricesRepo,
-		cleanUpInterval: cleanUpInterval,
-		log:             slog.Default(),
-		clients:         make(map[*Client]struct{}),
-		register:        make(chan *Client),
-		unregister:      make(chan *Client),
-		broadcast:       make(chan domain.PriceUpdate),
-		done:            make(chan struct{}),
+		pricesRepo:             pricesRepo,
+		cleanUpInterval:        cleanUpInterval,
+		cleanUpIntervalChanged: make(chan struct{}, 1),
+		log:                    slog.Default(),
+		clock:                  clk,
+		clients:                make(map[*Client]struct{}),
+		latest:                 make(map[domain.Pair]domain.PriceUpdate),
+		register:               make(chan *Client),
+		unregister:             make(chan *Client),
+		broadcast:              make(chan domain.PriceUpdate, bufferSize),
+		done:                   make(chan struct{}),
 	}
 }
 
 func (h *Hub) Start() {
-	cleanupTicker := time.NewTicker(h.cleanUpInterval)
+	cleanupTicker := h.clock.NewTicker(h.getCleanUpInterval())
 	defer cleanupTicker.Stop()
 
 	for {
@@ -51,12 +74,16 @@ func (h *Hub) Start() {
 			h.removeClient(client)
 
 		case update := <-h.broadcast:
+			h.setLatest(update)
 			h.broadcastUpdate(update)
 			h.pricesRepo.Store(update)
 
-		case <-cleanupTicker.C:
+		case <-cleanupTicker.C():
 			h.cleanupDisconnectedClients()
 
+		case <-h.cleanUpIntervalChanged:
+			cleanupTicker.Reset(h.getCleanUpInterval())
+
 		case <-h.done:
 			h.closeAllClients()
 			return
@@ -80,8 +107,66 @@ func (h *Hub) Broadcast(update domain.PriceUpdate) {
 	}
 }
 
-func (h *Hub) GetHistory(pair domain.Pair, since time.Time) []domain.PriceUpdate {
-	return h.pricesRepo.GetSince(pair, since)
+// GetHistory returns up to limit prices of the pair received since the given time, in ascending order.
+// A non-positive limit returns all of them.
+func (h *Hub) GetHistory(pair domain.Pair, since time.Time, limit int) []domain.PriceUpdate {
+	if repo, ok := h.pricesRepo.(rangeReader); ok {
+		return repo.GetRange(pair, since, maxTime, limit)
+	}
+
+	history := h.pricesRepo.GetSince(pair, since)
+	if limit > 0 && len(history) > limit {
+		history = history[:limit]
+	}
+	return history
+}
+
+// Latest returns the latest price of the pair, broadcast since started, or stored before.
+func (h *Hub) Latest(pair domain.Pair) (domain.PriceUpdate, bool) {
+	h.mu.RLock()
+	latest, ok := h.latest[pair]
+	h.mu.RUnlock()
+
+	if ok {
+		return latest, true
+	}
+
+	return h.pricesRepo.GetLatest(pair)
+}
+
+func (h *Hub) setLatest(update domain.PriceUpdate) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if latest, ok := h.latest[update.Pair]; !ok || !latest.ReceivedAt.After(update.ReceivedAt) {
+		h.latest[update.Pair] = update
+	}
+}
+
+// HistoryAvailableSince returns the time from which the stored history of the pair is complete, when it is limited.
+func (h *Hub) HistoryAvailableSince(pair domain.Pair) (time.Time, bool) {
+	if repo, ok := h.pricesRepo.(retentionReporter); ok {
+		return repo.AvailableSince(pair)
+	}
+	return time.Time{}, false
+}
+
+// SetCleanUpInterval changes how often disconnected clients are cleaned up, taking effect immediately.
+func (h *Hub) SetCleanUpInterval(interval time.Duration) {
+	h.mu.Lock()
+	h.cleanUpInterval = interval
+	h.mu.Unlock()
+
+	select {
+	case h.cleanUpIntervalChanged <- struct{}{}:
+	default: // a change is already pending, the latest interval will be read
+	}
+}
+
+func (h *Hub) getCleanUpInterval() time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.cleanUpInterval
 }
 
 func (h *Hub) Stop() {
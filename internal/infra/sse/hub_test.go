@@ -3,37 +3,42 @@ package sse
 import (
 	"log/slog"
 	"net/http/httptest"
-	"strings"
 	"testing"
 	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/clock"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/in_memory"
 	"github.com/tonytcb/crypto-pricing-api/test/mocks"
 )
 
 func TestHub_Start(t *testing.T) {
 	slog.SetDefault(newNoopLogger())
 
-	pricesRepo := new(MockPricesRepository)
-	hub := NewHub(pricesRepo, 100*time.Millisecond)
+	var (
+		pricesRepo = new(MockPricesRepository)
+		clk        = clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		hub        = NewHub(pricesRepo, 100*time.Millisecond, 10, clk)
+	)
 
 	w1 := mocks.NewThreadSafeRecorder()
-	client1, err := NewClient("test-client-1", w1, 10)
+	client1, err := NewClient("test-client-1", w1, 10, RenderPrice)
 	assert.NoError(t, err)
 
 	w2 := mocks.NewThreadSafeRecorder()
-	client2, err := NewClient("test-client-2", w2, 10)
+	client2, err := NewClient("test-client-2", w2, 10, RenderPrice)
 	assert.NoError(t, err)
 
 	btcUsd := domain.NewPair(domain.BTC, domain.USD)
 	update := domain.PriceUpdate{
 		Pair:       btcUsd,
 		Price:      decimal.NewFromFloat(50000),
-		ReceivedAt: time.Now(),
+		ReceivedAt: clk.Now(),
 	}
 
 	pricesRepo.On("Store", mock.Anything).Return()
@@ -45,50 +50,174 @@ func TestHub_Start(t *testing.T) {
 
 	hub.RegisterClient(client1)
 	hub.RegisterClient(client2)
+	syncHub(hub)
 
-	assert.Eventually(t, func() bool {
-		return hub.ClientCount() == 2
-	}, 100*time.Millisecond, 10*time.Millisecond, "Both clients should be registered")
+	assert.Equal(t, 2, hub.ClientCount(), "Both clients should be registered")
 
 	go client1.Listen(btcUsd)
 	go client2.Listen(btcUsd)
-	time.Sleep(10 * time.Millisecond) // Give time for goroutines to start
 
 	hub.Broadcast(update)
 
-	assert.Eventually(t, func() bool {
-		response1 := w1.BodyString()
-		response2 := w2.BodyString()
-		return strings.Contains(response1, "50000") && strings.Contains(response2, "50000")
-	}, 100*time.Millisecond, 10*time.Millisecond, "Clients should receive the broadcast")
+	require.True(t, w1.WaitFlushed(), "Client1 should receive the broadcast")
+	require.True(t, w2.WaitFlushed(), "Client2 should receive the broadcast")
+	assert.Contains(t, w1.BodyString(), "50000")
+	assert.Contains(t, w2.BodyString(), "50000")
 
+	syncHub(hub)
 	pricesRepo.AssertCalled(t, "Store", mock.Anything)
 
 	hub.UnregisterClient(client1)
+	syncHub(hub)
 
-	assert.Eventually(t, func() bool {
-		return hub.ClientCount() == 1
-	}, 100*time.Millisecond, 10*time.Millisecond, "Client1 should be unregistered")
+	assert.Equal(t, 1, hub.ClientCount(), "Client1 should be unregistered")
 
 	client2.Close()
 
-	assert.Eventually(t, func() bool {
-		return hub.ClientCount() == 0
-	}, 200*time.Millisecond, 10*time.Millisecond, "Disconnected client should be cleaned up")
+	clk.BlockUntilDue(100 * time.Millisecond)
+	clk.Advance(100 * time.Millisecond)
+
+	waitCleanUp(t, hub)
+	assert.Equal(t, 0, hub.ClientCount(), "Disconnected client should be cleaned up")
+}
+
+func TestHub_SetCleanUpInterval(t *testing.T) {
+	slog.SetDefault(newNoopLogger())
+
+	var (
+		pricesRepo = new(MockPricesRepository)
+		clk        = clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		hub        = NewHub(pricesRepo, time.Hour, 10, clk)
+	)
+
+	go hub.Start()
+	defer hub.Stop()
+
+	client, err := NewClient("test-client-1", mocks.NewThreadSafeRecorder(), 10, RenderPrice)
+	assert.NoError(t, err)
+
+	hub.RegisterClient(client)
+	client.Close()
+
+	hub.SetCleanUpInterval(10 * time.Millisecond)
+
+	// waits for the hub to reset its ticker, far before the initial interval
+	clk.BlockUntilDue(10 * time.Millisecond)
+	clk.Advance(10 * time.Millisecond)
+
+	waitCleanUp(t, hub)
+	assert.Equal(t, 0, hub.ClientCount(), "Disconnected client should be cleaned up with the new interval")
+}
+
+// syncHub returns once the hub handled every event received before, as it handles them one at a time.
+func syncHub(hub *Hub) {
+	hub.UnregisterClient(&Client{id: "sync", done: make(chan struct{})})
+}
+
+// waitCleanUp returns once the hub handled the due clean-up tick, which it may pick after other ready events.
+func waitCleanUp(t *testing.T, hub *Hub) {
+	t.Helper()
+
+	for range 100 {
+		syncHub(hub)
+		if hub.ClientCount() == 0 {
+			return
+		}
+	}
+}
+
+func TestHub_Broadcast(t *testing.T) {
+	slog.SetDefault(newNoopLogger())
+
+	hub := NewHub(new(MockPricesRepository), time.Minute, 2, clock.New())
+	update := domain.PriceUpdate{Pair: domain.NewPair(domain.BTC, domain.USD), Price: decimal.NewFromInt(50000)}
+
+	// not started, so the updates are buffered until the buffer is full
+	hub.Broadcast(update)
+	hub.Broadcast(update)
+	hub.Broadcast(update)
+
+	assert.Len(t, hub.broadcast, 2)
+}
+
+func TestHub_Latest(t *testing.T) {
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		ethUsd = domain.NewPair(domain.ETH, domain.USD)
+		now    = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		stored = domain.PriceUpdate{Pair: ethUsd, Price: decimal.NewFromInt(3000), ReceivedAt: now.Add(-time.Hour)}
+	)
+
+	pricesRepo := new(MockPricesRepository)
+	pricesRepo.On("GetLatest", ethUsd).Return(stored, true)
+	pricesRepo.On("GetLatest", domain.NewPair(domain.ETH, domain.EUR)).Return(domain.PriceUpdate{}, false)
+
+	hub := NewHub(pricesRepo, time.Minute, 10, clock.New())
+
+	hub.setLatest(domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(50000), ReceivedAt: now})
+	hub.setLatest(domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(40000), ReceivedAt: now.Add(-time.Minute), Backfilled: true})
+
+	latest, ok := hub.Latest(btcUsd)
+	assert.True(t, ok)
+	assert.Equal(t, "50000", latest.Price.String(), "Expected older updates to be ignored")
+
+	latest, ok = hub.Latest(ethUsd)
+	assert.True(t, ok)
+	assert.Equal(t, stored, latest, "Expected the latest stored price before any broadcast")
+
+	_, ok = hub.Latest(domain.NewPair(domain.ETH, domain.EUR))
+	assert.False(t, ok)
+
+	pricesRepo.AssertNotCalled(t, "GetLatest", btcUsd)
+	pricesRepo.AssertNotCalled(t, "GetSince", mock.Anything, mock.Anything)
+}
+
+func TestHub_GetHistory(t *testing.T) {
+	var (
+		btcUsd  = domain.NewPair(domain.BTC, domain.USD)
+		now     = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		history = []domain.PriceUpdate{
+			{Pair: btcUsd, Price: decimal.NewFromInt(50000), ReceivedAt: now.Add(-2 * time.Minute)},
+			{Pair: btcUsd, Price: decimal.NewFromInt(51000), ReceivedAt: now.Add(-time.Minute)},
+			{Pair: btcUsd, Price: decimal.NewFromInt(52000), ReceivedAt: now},
+		}
+	)
+
+	t.Run("Reads up to the limit from the repositories bounding the range", func(t *testing.T) {
+		repo := in_memory.NewPricesByRingBuffer(10)
+		for _, update := range history {
+			repo.Store(update)
+		}
+
+		hub := NewHub(repo, time.Minute, 10, clock.New())
+
+		assert.Equal(t, history[1:], hub.GetHistory(btcUsd, now.Add(-time.Minute), 0))
+		assert.Equal(t, history[:2], hub.GetHistory(btcUsd, time.Time{}, 2))
+	})
+
+	t.Run("Truncates the history of the other repositories to the limit", func(t *testing.T) {
+		pricesRepo := new(MockPricesRepository)
+		pricesRepo.On("GetSince", btcUsd, time.Time{}).Return(history)
+
+		hub := NewHub(pricesRepo, time.Minute, 10, clock.New())
+
+		assert.Equal(t, history[:2], hub.GetHistory(btcUsd, time.Time{}, 2))
+		assert.Equal(t, history, hub.GetHistory(btcUsd, time.Time{}, 0))
+	})
 }
 
 func TestHub_CleanupDisconnectedClients(t *testing.T) {
 	slog.SetDefault(newNoopLogger())
 
 	pricesRepo := new(MockPricesRepository)
-	hub := NewHub(pricesRepo, time.Minute)
+	hub := NewHub(pricesRepo, time.Minute, 10, clock.New())
 
 	w1 := httptest.NewRecorder()
-	client1, err := NewClient("test-client-1", w1, 10)
+	client1, err := NewClient("test-client-1", w1, 10, RenderPrice)
 	assert.NoError(t, err)
 
 	w2 := httptest.NewRecorder()
-	client2, err := NewClient("test-client-2", w2, 10)
+	client2, err := NewClient("test-client-2", w2, 10, RenderPrice)
 	assert.NoError(t, err)
 	client2.Close()
 
@@ -104,14 +233,14 @@ func TestHub_CloseAllClients(t *testing.T) {
 	slog.SetDefault(newNoopLogger())
 
 	pricesRepo := new(MockPricesRepository)
-	hub := NewHub(pricesRepo, time.Minute)
+	hub := NewHub(pricesRepo, time.Minute, 10, clock.New())
 
 	w1 := httptest.NewRecorder()
-	client1, err := NewClient("test-client-1", w1, 10)
+	client1, err := NewClient("test-client-1", w1, 10, RenderPrice)
 	assert.NoError(t, err)
 
 	w2 := httptest.NewRecorder()
-	client2, err := NewClient("test-client-2", w2, 10)
+	client2, err := NewClient("test-client-2", w2, 10, RenderPrice)
 	assert.NoError(t, err)
 
 	hub.addClient(client1)
@@ -127,16 +256,16 @@ func TestHub_CloseAllClients(t *testing.T) {
 
 func TestHub_ClientCount(t *testing.T) {
 	pricesRepo := new(MockPricesRepository)
-	hub := NewHub(pricesRepo, time.Minute)
+	hub := NewHub(pricesRepo, time.Minute, 10, clock.New())
 
 	assert.Equal(t, 0, hub.ClientCount())
 
 	w1 := httptest.NewRecorder()
-	client1, err := NewClient("test-client-1", w1, 10)
+	client1, err := NewClient("test-client-1", w1, 10, RenderPrice)
 	assert.NoError(t, err)
 
 	w2 := httptest.NewRecorder()
-	client2, err := NewClient("test-client-2", w2, 10)
+	client2, err := NewClient("test-client-2", w2, 10, RenderPrice)
 	assert.NoError(t, err)
 
 	hub.addClient(client1)
@@ -157,6 +286,11 @@ func (m *MockPricesRepository) Store(priceUpdate domain.PriceUpdate) {
 	m.Called(priceUpdate)
 }
 
+func (m *MockPricesRepository) GetLatest(pair domain.Pair) (domain.PriceUpdate, bool) {
+	args := m.Called(pair)
+	return args.Get(0).(domain.PriceUpdate), args.Bool(1)
+}
+
 func (m *MockPricesRepository) GetSince(pair domain.Pair, since time.Time) []domain.PriceUpdate {
 	args := m.Called(pair, since)
 	return args.Get(0).([]domain.PriceUpdate)
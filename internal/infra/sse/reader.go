@@ -0,0 +1,52 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// maxEventSize bounds the size of a single event read from a stream
+const maxEventSize = 1024 * 1024
+
+// ReadEvents reads a Server-Sent Events stream, calling onEvent with the data of every event received.
+// Multi-line data fields are joined with a new line; comments and other fields are ignored.
+// It returns nil when the stream ends, or the first error returned by onEvent.
+func ReadEvents(r io.Reader, onEvent func(data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxEventSize)
+
+	var data bytes.Buffer
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		if len(line) == 0 { // a blank line dispatches the event
+			if data.Len() > 0 {
+				if err := onEvent(bytes.Clone(data.Bytes())); err != nil {
+					return err
+				}
+				data.Reset()
+			}
+			continue
+		}
+
+		value, found := bytes.CutPrefix(line, []byte("data:"))
+		if !found {
+			continue
+		}
+
+		if data.Len() > 0 {
+			data.WriteByte('\n')
+		}
+		data.Write(bytes.TrimPrefix(value, []byte(" ")))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "failed to read event stream")
+	}
+
+	return nil
+}
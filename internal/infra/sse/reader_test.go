@@ -0,0 +1,37 @@
+package sse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadEvents(t *testing.T) {
+	t.Run("Reads every event", func(t *testing.T) {
+		stream := ": comment\n\ndata: {\"price\":\"1\"}\n\nevent: price\ndata: line1\ndata: line2\n\ndata: incomplete"
+
+		var events []string
+		err := ReadEvents(strings.NewReader(stream), func(data []byte) error {
+			events = append(events, string(data))
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{`{"price":"1"}`, "line1\nline2"}, events)
+	})
+
+	t.Run("Stops on callback error", func(t *testing.T) {
+		stopErr := errors.New("stop")
+
+		var calls int
+		err := ReadEvents(strings.NewReader("data: 1\n\ndata: 2\n\n"), func(_ []byte) error {
+			calls++
+			return stopErr
+		})
+
+		assert.Equal(t, stopErr, err)
+		assert.Equal(t, 1, calls)
+	})
+}
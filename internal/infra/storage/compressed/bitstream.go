@@ -0,0 +1,71 @@
+package compressed
+
+// bitWriter appends bits, most significant first, to a growing byte slice.
+type bitWriter struct {
+	buf []byte
+	// free is the number of unused low bits in the last byte of buf
+	free uint8
+}
+
+func (w *bitWriter) writeBit(bit bool) {
+	if w.free == 0 {
+		w.buf = append(w.buf, 0)
+		w.free = 8
+	}
+
+	w.free--
+
+	if bit {
+		w.buf[len(w.buf)-1] |= 1 << w.free
+	}
+}
+
+// writeBits writes the n low bits of v.
+func (w *bitWriter) writeBits(v uint64, n uint8) {
+	for n > 0 {
+		if w.free == 0 {
+			w.buf = append(w.buf, 0)
+			w.free = 8
+		}
+
+		chunk := min(n, w.free)
+		n -= chunk
+		w.free -= chunk
+
+		bits := byte(v>>n) & (1<<chunk - 1)
+		w.buf[len(w.buf)-1] |= bits << w.free
+	}
+}
+
+// bitReader reads the bits written by a bitWriter. Reads past the end return zero bits, the number of
+// encoded values is tracked by the caller.
+type bitReader struct {
+	buf []byte
+	pos uint64
+}
+
+func (r *bitReader) readBit() bool {
+	return r.readBits(1) == 1
+}
+
+func (r *bitReader) readBits(n uint8) uint64 {
+	var v uint64
+
+	for n > 0 {
+		index := r.pos / 8
+		if index >= uint64(len(r.buf)) {
+			return v << n
+		}
+
+		offset := uint8(r.pos % 8)
+		chunk := min(n, 8-offset)
+
+		bits := uint64(r.buf[index]>>(8-offset-chunk)) & (1<<chunk - 1)
+		v = v<<chunk | bits
+
+		n -= chunk
+		r.pos += uint64(chunk)
+	}
+
+	return v
+}
@@ -0,0 +1,239 @@
+package compressed
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// Timestamps are encoded as the delta of their deltas, in nanoseconds, with a prefix selecting the number of bits:
+// the same interval between updates takes a single bit, and the jitter of a few milliseconds fits in 24 bits.
+var timestampBuckets = []struct {
+	prefix     uint64
+	prefixBits uint8
+	valueBits  uint8
+}{
+	{prefix: 0b10, prefixBits: 2, valueBits: 14},
+	{prefix: 0b110, prefixBits: 3, valueBits: 24},
+	{prefix: 0b1110, prefixBits: 4, valueBits: 36},
+	{prefix: 0b1111, prefixBits: 4, valueBits: 64},
+}
+
+// block holds a fixed maximum number of points: the first timestamp and price are written in full, the following
+// timestamps as delta-of-delta and the following prices XORed with the previous one, as in Facebook's Gorilla.
+// Prices are encoded as float64, the ones not representable exactly are kept aside as exceptions, as are the
+// optional fields of the backfilled, synthetic and quoted updates.
+type block struct {
+	w     bitWriter
+	count int
+
+	first, last int64 // unix nano
+	lastDelta   int64
+	lastValue   uint64
+	leading     uint8
+	trailing    uint8
+
+	exceptions map[int]decimal.Decimal
+	extras     map[int]extras
+}
+
+// extras holds the optional fields of an update.
+type extras struct {
+	backfilled bool
+	legs       []domain.Pair
+	quote      *domain.Quote
+}
+
+func (b *block) append(unixNano int64, price decimal.Decimal) {
+	f, _ := price.Float64()
+	if !decimal.NewFromFloat(f).Equal(price) {
+		if b.exceptions == nil {
+			b.exceptions = make(map[int]decimal.Decimal)
+		}
+		b.exceptions[b.count] = price
+	}
+
+	value := math.Float64bits(f)
+
+	if b.count == 0 {
+		b.w.writeBits(uint64(unixNano), 64)
+		b.w.writeBits(value, 64)
+		b.first, b.last, b.lastValue = unixNano, unixNano, value
+		b.leading = math.MaxUint8 // no previous window
+		b.count++
+		return
+	}
+
+	delta := unixNano - b.last
+	b.writeDeltaOfDelta(delta - b.lastDelta)
+	b.writeValue(value)
+
+	b.last, b.lastDelta = unixNano, delta
+	b.count++
+}
+
+// setExtras keeps the optional fields of the update appended last, when it sets any.
+func (b *block) setExtras(update domain.PriceUpdate) {
+	if !update.Backfilled && len(update.Legs) == 0 && update.Quote == nil {
+		return
+	}
+
+	if b.extras == nil {
+		b.extras = make(map[int]extras)
+	}
+	b.extras[b.count-1] = extras{backfilled: update.Backfilled, legs: update.Legs, quote: update.Quote}
+}
+
+func (b *block) writeDeltaOfDelta(dod int64) {
+	if dod == 0 {
+		b.w.writeBit(false)
+		return
+	}
+
+	for _, bucket := range timestampBuckets {
+		if bucket.valueBits == 64 || fitsSigned(dod, bucket.valueBits) {
+			b.w.writeBits(bucket.prefix, bucket.prefixBits)
+			b.w.writeBits(uint64(dod), bucket.valueBits)
+			return
+		}
+	}
+}
+
+func (b *block) writeValue(value uint64) {
+	xor := value ^ b.lastValue
+	b.lastValue = value
+
+	if xor == 0 {
+		b.w.writeBit(false)
+		return
+	}
+
+	b.w.writeBit(true)
+
+	leading := uint8(bits.LeadingZeros64(xor))
+	trailing := uint8(bits.TrailingZeros64(xor))
+
+	// the length of the meaningful bits is written on 6 bits, capping the leading zeros at 31 keeps it below 64
+	leading = min(leading, 31)
+
+	if b.leading != math.MaxUint8 && leading >= b.leading && trailing >= b.trailing {
+		b.w.writeBit(false)
+		b.w.writeBits(xor>>b.trailing, 64-b.leading-b.trailing)
+		return
+	}
+
+	b.leading, b.trailing = leading, trailing
+	meaningful := 64 - leading - trailing
+
+	b.w.writeBit(true)
+	b.w.writeBits(uint64(leading), 5)
+	b.w.writeBits(uint64(meaningful-1), 6)
+	b.w.writeBits(xor>>trailing, meaningful)
+}
+
+// sizeBytes estimates the memory held by the block.
+func (b *block) sizeBytes() int {
+	const (
+		exceptionSize = 48
+		extrasSize    = 64
+	)
+	return cap(b.w.buf) + len(b.exceptions)*exceptionSize + len(b.extras)*extrasSize
+}
+
+// iterator decodes the points of a block in order. It reads the bits written up to its creation, so it must not be
+// used concurrently with appends to the block.
+type iterator struct {
+	b     *block
+	r     bitReader
+	index int
+
+	unixNano int64
+	delta    int64
+	value    uint64
+	leading  uint8
+	trailing uint8
+}
+
+func (b *block) iterator() *iterator {
+	return &iterator{b: b, r: bitReader{buf: b.w.buf}}
+}
+
+// next decodes the next point, returning false once all points are read.
+func (it *iterator) next() bool {
+	if it.index >= it.b.count {
+		return false
+	}
+
+	if it.index == 0 {
+		it.unixNano = int64(it.r.readBits(64))
+		it.value = it.r.readBits(64)
+		it.index++
+		return true
+	}
+
+	it.delta += it.readDeltaOfDelta()
+	it.unixNano += it.delta
+	it.readValue()
+	it.index++
+
+	return true
+}
+
+func (it *iterator) readDeltaOfDelta() int64 {
+	if !it.r.readBit() {
+		return 0
+	}
+
+	for _, bucket := range timestampBuckets[:len(timestampBuckets)-1] {
+		// every bucket prefix is a run of ones ended by a zero, except for the last one
+		if !it.r.readBit() {
+			return signExtend(it.r.readBits(bucket.valueBits), bucket.valueBits)
+		}
+	}
+
+	last := timestampBuckets[len(timestampBuckets)-1]
+	return int64(it.r.readBits(last.valueBits))
+}
+
+func (it *iterator) readValue() {
+	if !it.r.readBit() {
+		return
+	}
+
+	if it.r.readBit() {
+		it.leading = uint8(it.r.readBits(5))
+		meaningful := uint8(it.r.readBits(6)) + 1
+		it.trailing = 64 - it.leading - meaningful
+	}
+
+	meaningful := 64 - it.leading - it.trailing
+	it.value ^= it.r.readBits(meaningful) << it.trailing
+}
+
+// at returns the current point.
+func (it *iterator) at() (int64, decimal.Decimal) {
+	if price, exists := it.b.exceptions[it.index-1]; exists {
+		return it.unixNano, price
+	}
+	return it.unixNano, decimal.NewFromFloat(math.Float64frombits(it.value))
+}
+
+// applyExtras sets the optional fields of the current point on the update.
+func (it *iterator) applyExtras(update *domain.PriceUpdate) {
+	if e, exists := it.b.extras[it.index-1]; exists {
+		update.Backfilled, update.Legs, update.Quote = e.backfilled, e.legs, e.quote
+	}
+}
+
+func fitsSigned(v int64, n uint8) bool {
+	limit := int64(1) << (n - 1)
+	return v >= -limit && v < limit
+}
+
+func signExtend(v uint64, n uint8) int64 {
+	shift := 64 - n
+	return int64(v<<shift) >> shift
+}
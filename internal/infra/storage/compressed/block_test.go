@@ -0,0 +1,76 @@
+package compressed
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitstream(t *testing.T) {
+	var w bitWriter
+	w.writeBit(true)
+	w.writeBits(0b101, 3)
+	w.writeBits(1<<63|1, 64)
+	w.writeBit(false)
+	w.writeBits(0x3ff, 10)
+
+	r := bitReader{buf: w.buf}
+	assert.True(t, r.readBit())
+	assert.Equal(t, uint64(0b101), r.readBits(3))
+	assert.Equal(t, uint64(1<<63|1), r.readBits(64))
+	assert.False(t, r.readBit())
+	assert.Equal(t, uint64(0x3ff), r.readBits(10))
+}
+
+func TestBlock_RoundTrip(t *testing.T) {
+	var (
+		rnd   = rand.New(rand.NewSource(1))
+		start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+		times []int64
+		price []decimal.Decimal
+	)
+
+	ts, value := start, 50000.0
+	for i := 0; i < 1000; i++ {
+		switch {
+		case i%100 == 0:
+			ts += int64(time.Hour) // a gap, falling in the largest bucket
+		case i%10 == 0:
+			ts += int64(5*time.Second) + rnd.Int63n(int64(time.Second)) - int64(500*time.Millisecond)
+		default:
+			ts += int64(5*time.Second) + rnd.Int63n(2000) - 1000
+		}
+
+		if i%3 != 0 {
+			value = float64(int64((value+rnd.NormFloat64()*10)*100)) / 100
+		}
+
+		times = append(times, ts)
+		price = append(price, decimal.NewFromFloat(value))
+	}
+
+	// prices not representable as float64 are kept as exceptions
+	price[500] = decimal.RequireFromString("50000.123456789012345678901")
+	price[501] = decimal.RequireFromString("-0.000000000000000000001")
+
+	b := &block{}
+	for i := range times {
+		b.append(times[i], price[i])
+	}
+
+	it := b.iterator()
+	for i := range times {
+		require.True(t, it.next(), "Expected point %d", i)
+
+		unixNano, decoded := it.at()
+		require.Equal(t, times[i], unixNano, "Unexpected timestamp at %d", i)
+		require.True(t, price[i].Equal(decoded), "Expected price %s at %d, got %s", price[i], i, decoded)
+	}
+	assert.False(t, it.next())
+
+	assert.Less(t, b.sizeBytes(), len(times)*8, "Expected less than 8 bytes per point")
+}
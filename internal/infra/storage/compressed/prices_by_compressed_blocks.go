@@ -0,0 +1,203 @@
+package compressed
+
+import (
+	"iter"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+const DefaultBlockSize = 512
+
+type series struct {
+	blocks  []*block // the last one is being appended
+	count   int
+	latest  domain.PriceUpdate
+	dropped bool
+}
+
+// PricesByCompressedBlocks is a memory-efficient in-memory prices repository: the updates of each pair are encoded
+// in Gorilla-style compressed blocks, taking a few bytes per update instead of a full domain.PriceUpdate.
+// History is kept by whole blocks: once a pair holds more than maxHistorySize updates, its oldest block is dropped
+// as soon as the remaining ones hold at least maxHistorySize updates.
+// Updates are expected in reception order, and reception times are restored in UTC.
+type PricesByCompressedBlocks struct {
+	mutex          sync.RWMutex
+	series         map[domain.Pair]*series
+	maxHistorySize int
+	blockSize      int
+}
+
+func NewPricesByCompressedBlocks(maxHistorySize, blockSize int) *PricesByCompressedBlocks {
+	if blockSize <= 0 {
+		blockSize = DefaultBlockSize
+	}
+
+	return &PricesByCompressedBlocks{
+		series:         make(map[domain.Pair]*series),
+		maxHistorySize: maxHistorySize,
+		blockSize:      blockSize,
+	}
+}
+
+func (r *PricesByCompressedBlocks) Store(priceUpdate domain.PriceUpdate) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	s, exists := r.series[priceUpdate.Pair]
+	if !exists {
+		s = &series{}
+		r.series[priceUpdate.Pair] = s
+	}
+
+	if len(s.blocks) == 0 || s.blocks[len(s.blocks)-1].count >= r.blockSize {
+		s.blocks = append(s.blocks, &block{})
+	}
+
+	last := s.blocks[len(s.blocks)-1]
+	last.append(priceUpdate.ReceivedAt.UnixNano(), priceUpdate.Price)
+	last.setExtras(priceUpdate)
+	s.count++
+	s.latest = priceUpdate
+
+	for len(s.blocks) > 1 && s.count-s.blocks[0].count >= r.maxHistorySize {
+		s.count -= s.blocks[0].count
+		s.blocks[0] = nil
+		s.blocks = s.blocks[1:]
+		s.dropped = true
+	}
+}
+
+func (r *PricesByCompressedBlocks) GetLatest(pair domain.Pair) (domain.PriceUpdate, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	s, exists := r.series[pair]
+	if !exists {
+		return domain.PriceUpdate{}, false
+	}
+
+	return s.latest, true
+}
+
+// GetSince returns the updates received at or after since, in ascending order.
+func (r *PricesByCompressedBlocks) GetSince(pair domain.Pair, since time.Time) []domain.PriceUpdate {
+	return r.GetRange(pair, since, time.Unix(0, math.MaxInt64), 0)
+}
+
+// GetRange returns up to limit updates received in [from, to), in ascending order. A non-positive limit returns all of them.
+func (r *PricesByCompressedBlocks) GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate {
+	result := make([]domain.PriceUpdate, 0)
+
+	for update := range r.Range(pair, from, to) {
+		if limit > 0 && len(result) == limit {
+			break
+		}
+		result = append(result, update)
+	}
+
+	return result
+}
+
+// Range iterates over the updates received in [from, to) in ascending order, decoding only the blocks overlapping
+// the range. The repository is read-locked during the iteration, so the loop body must not block nor write to it.
+func (r *PricesByCompressedBlocks) Range(pair domain.Pair, from, to time.Time) iter.Seq[domain.PriceUpdate] {
+	return func(yield func(domain.PriceUpdate) bool) {
+		r.mutex.RLock()
+		defer r.mutex.RUnlock()
+
+		s, exists := r.series[pair]
+		if !exists {
+			return
+		}
+
+		fromNano, toNano := clampedUnixNano(from), clampedUnixNano(to)
+
+		// the first block that may hold updates at or after from is the one before the first block starting after it
+		start := sort.Search(len(s.blocks), func(i int) bool {
+			return s.blocks[i].first > fromNano
+		})
+		start = max(start-1, 0)
+
+		for _, b := range s.blocks[start:] {
+			if b.first >= toNano {
+				return
+			}
+			if b.last < fromNano {
+				continue
+			}
+
+			for it := b.iterator(); it.next(); {
+				unixNano, price := it.at()
+				if unixNano < fromNano {
+					continue
+				}
+				if unixNano >= toNano {
+					return
+				}
+
+				update := domain.PriceUpdate{Pair: pair, Price: price, ReceivedAt: time.Unix(0, unixNano).UTC()}
+				it.applyExtras(&update)
+				if !yield(update) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// AvailableSince returns the reception time of the oldest update held for the pair once blocks were dropped.
+func (r *PricesByCompressedBlocks) AvailableSince(pair domain.Pair) (time.Time, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	s, exists := r.series[pair]
+	if !exists || !s.dropped {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, s.blocks[0].first).UTC(), true
+}
+
+// Pairs returns the pairs with stored price updates.
+func (r *PricesByCompressedBlocks) Pairs() []domain.Pair {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	pairs := make([]domain.Pair, 0, len(r.series))
+	for pair := range r.series {
+		pairs = append(pairs, pair)
+	}
+
+	return pairs
+}
+
+// SizeBytes estimates the memory held by the encoded updates.
+func (r *PricesByCompressedBlocks) SizeBytes() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var size int
+	for _, s := range r.series {
+		for _, b := range s.blocks {
+			size += b.sizeBytes()
+		}
+	}
+
+	return size
+}
+
+// clampedUnixNano converts times out of the unix nano range, e.g. the zero time, to its bounds.
+func clampedUnixNano(t time.Time) int64 {
+	switch {
+	case t.Before(time.Unix(0, math.MinInt64)):
+		return math.MinInt64
+	case t.After(time.Unix(0, math.MaxInt64)):
+		return math.MaxInt64
+	default:
+		return t.UnixNano()
+	}
+}
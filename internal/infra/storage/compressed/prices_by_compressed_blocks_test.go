@@ -0,0 +1,179 @@
+package compressed
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain/domaintest"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/in_memory"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/storagetest"
+)
+
+func TestPricesByCompressedBlocks(t *testing.T) {
+	var (
+		btcUsd  = domain.NewPair(domain.BTC, domain.USD)
+		start   = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		updates = domaintest.NewPriceUpdates(btcUsd, 25, start, 5*time.Second)
+		repo    = NewPricesByCompressedBlocks(10, 4)
+	)
+
+	for _, update := range updates {
+		repo.Store(update)
+	}
+
+	t.Run("Should keep at least the max history size by whole blocks", func(t *testing.T) {
+		all := repo.GetSince(btcUsd, time.Time{})
+		require.Len(t, all, 13, "Expected the blocks holding the last 10 updates to be kept")
+		assert.Equal(t, updates[12], all[0])
+		assert.Equal(t, updates[24], all[12])
+
+		availableSince, limited := repo.AvailableSince(btcUsd)
+		assert.True(t, limited)
+		assert.True(t, availableSince.Equal(updates[12].ReceivedAt))
+	})
+
+	t.Run("Should return the latest update", func(t *testing.T) {
+		latest, exists := repo.GetLatest(btcUsd)
+		require.True(t, exists)
+		assert.Equal(t, updates[24], latest)
+
+		_, exists = repo.GetLatest(domain.NewPair(domain.ETH, domain.USD))
+		assert.False(t, exists)
+	})
+
+	t.Run("Should query ranges across blocks", func(t *testing.T) {
+		ranged := repo.GetRange(btcUsd, updates[14].ReceivedAt, updates[21].ReceivedAt, 0)
+		require.Len(t, ranged, 7)
+		assert.Equal(t, updates[14], ranged[0])
+		assert.Equal(t, updates[20], ranged[6])
+
+		limited := repo.GetRange(btcUsd, updates[14].ReceivedAt, updates[21].ReceivedAt, 2)
+		assert.Equal(t, updates[14:16], limited)
+
+		assert.Len(t, repo.GetSince(btcUsd, updates[23].ReceivedAt.Add(time.Nanosecond)), 1)
+		assert.Empty(t, repo.GetRange(btcUsd, updates[21].ReceivedAt, updates[14].ReceivedAt, 0))
+	})
+
+	t.Run("Should stop iterating on break", func(t *testing.T) {
+		count := 0
+		for range repo.Range(btcUsd, start, time.Now()) {
+			count++
+			if count == 5 {
+				break
+			}
+		}
+		assert.Equal(t, 5, count)
+	})
+}
+
+// BenchmarkMemoryUsage stores a day of 5s updates for 10 pairs, reporting the heap used per update.
+func BenchmarkMemoryUsage(b *testing.B) {
+	const (
+		pairs          = 10
+		updatesPerPair = 24 * 60 * 12
+	)
+
+	var (
+		start   = time.Now().Add(-24 * time.Hour)
+		updates = make([][]domain.PriceUpdate, pairs)
+	)
+
+	// realistic updates: a polling jitter of a few milliseconds and a random walk of cent-rounded prices
+	rnd := rand.New(rand.NewSource(1))
+	for i := range updates {
+		pair := domain.NewPair(domain.Currency(fmt.Sprintf("C%02d", i)), domain.USD)
+		updates[i] = domaintest.NewPriceUpdates(pair, updatesPerPair, start, 5*time.Second)
+
+		price := 50000.0
+		for j := range updates[i] {
+			price = float64(int64((price+rnd.NormFloat64()*5)*100)) / 100
+			updates[i][j].Price = decimal.NewFromFloat(price)
+			updates[i][j].ReceivedAt = updates[i][j].ReceivedAt.Add(time.Duration(rnd.Int63n(int64(5 * time.Millisecond))))
+		}
+	}
+
+	type repository interface {
+		Store(priceUpdate domain.PriceUpdate)
+	}
+
+	repositories := map[string]func() repository{
+		"RingBuffer": func() repository { return in_memory.NewPricesByRingBuffer(updatesPerPair) },
+		"Compressed": func() repository { return NewPricesByCompressedBlocks(updatesPerPair, DefaultBlockSize) },
+	}
+
+	for name, newRepository := range repositories {
+		b.Run(name, func(b *testing.B) {
+			var bytesPerUpdate float64
+
+			for b.Loop() {
+				before := heapInUse()
+
+				repo := newRepository()
+				for _, pairUpdates := range updates {
+					for _, update := range pairUpdates {
+						repo.Store(update)
+					}
+				}
+
+				bytesPerUpdate = float64(heapInUse()-before) / float64(pairs*updatesPerPair)
+				runtime.KeepAlive(repo)
+			}
+
+			b.ReportMetric(bytesPerUpdate, "heap-bytes/update")
+		})
+	}
+}
+
+func BenchmarkGetRange(b *testing.B) {
+	var (
+		btcUsd  = domain.NewPair(domain.BTC, domain.USD)
+		start   = time.Now().Add(-24 * time.Hour)
+		updates = domaintest.NewPriceUpdates(btcUsd, 100_000, start, 5*time.Second)
+		from    = updates[len(updates)-100].ReceivedAt
+	)
+
+	ring := in_memory.NewPricesByRingBuffer(len(updates))
+	compressed := NewPricesByCompressedBlocks(len(updates), DefaultBlockSize)
+
+	for _, update := range updates {
+		ring.Store(update)
+		compressed.Store(update)
+	}
+
+	b.Run("RingBuffer", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = ring.GetRange(btcUsd, from, time.Now(), 0)
+		}
+	})
+
+	b.Run("Compressed", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = compressed.GetRange(btcUsd, from, time.Now(), 0)
+		}
+	})
+}
+
+func heapInUse() int64 {
+	runtime.GC()
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	return int64(stats.HeapAlloc)
+}
+
+func TestPricesByCompressedBlocks_Contract(t *testing.T) {
+	storagetest.TestPricesRepository(t, func(_ *testing.T) storagetest.PricesRepository {
+		return NewPricesByCompressedBlocks(10, 4)
+	})
+}
@@ -0,0 +1,82 @@
+package downsampling
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const rawResolution = "raw"
+
+// Tier keeps the prices at a given resolution for a retention period. A zero resolution keeps the raw prices.
+type Tier struct {
+	Resolution time.Duration
+	Retention  time.Duration
+}
+
+// Policy lists the tiers from the finest to the coarsest resolution. Prices older than the retention of a tier
+// are averaged into the next one, and dropped after the retention of the last tier.
+type Policy []Tier
+
+// ParsePolicy parses a comma separated list of resolution:retention tiers, e.g. "raw:24h,1m:720h,1h:8760h".
+func ParsePolicy(value string) (Policy, error) {
+	var policy Policy
+
+	for _, entry := range strings.Split(value, ",") {
+		resolution, retention, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found {
+			return nil, errors.Errorf("invalid tier %q, expected resolution:retention", entry)
+		}
+
+		var tier Tier
+
+		if resolution != rawResolution {
+			d, err := time.ParseDuration(resolution)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid resolution of tier %q", entry)
+			}
+			tier.Resolution = d
+		}
+
+		d, err := time.ParseDuration(retention)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid retention of tier %q", entry)
+		}
+		tier.Retention = d
+
+		policy = append(policy, tier)
+	}
+
+	return policy, policy.Validate()
+}
+
+func (p Policy) Validate() error {
+	if len(p) == 0 {
+		return errors.New("at least one tier is required")
+	}
+
+	if p[0].Resolution != 0 {
+		return errors.New("the first tier must keep the raw prices")
+	}
+
+	for i, tier := range p {
+		if tier.Retention <= 0 {
+			return errors.Errorf("tier %d: retention must be greater than zero", i+1)
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		if tier.Resolution <= p[i-1].Resolution {
+			return errors.Errorf("tier %d: resolution must be coarser than the previous tier", i+1)
+		}
+
+		if tier.Retention <= p[i-1].Retention {
+			return errors.Errorf("tier %d: retention must be longer than the previous tier", i+1)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package downsampling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	t.Run("Should parse the tiers", func(t *testing.T) {
+		policy, err := ParsePolicy("raw:24h, 1m:720h,1h:8760h")
+		require.NoError(t, err)
+
+		assert.Equal(t, Policy{
+			{Resolution: 0, Retention: 24 * time.Hour},
+			{Resolution: time.Minute, Retention: 720 * time.Hour},
+			{Resolution: time.Hour, Retention: 8760 * time.Hour},
+		}, policy)
+	})
+
+	t.Run("Should reject invalid policies", func(t *testing.T) {
+		for _, value := range []string{
+			"",
+			"raw",
+			"raw:forever",
+			"1m:24h",
+			"raw:24h,1x:720h",
+			"raw:24h,1h:720h,1m:8760h",
+			"raw:24h,1m:1h",
+			"raw:0s",
+		} {
+			_, err := ParsePolicy(value)
+			assert.Error(t, err, value)
+		}
+	})
+}
@@ -0,0 +1,293 @@
+package downsampling
+
+import (
+	"iter"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
+
+// point is a price at the resolution of its tier: the average of count raw prices received in the bucket
+// starting at the point ReceivedAt.
+type point struct {
+	update domain.PriceUpdate
+	count  int64
+}
+
+// PricesByResolution is an in-memory prices repository applying a time-based retention Policy. A background
+// compaction averages the prices past the retention of a tier into the next one, so each tier holds a distinct
+// period of time, and queries are served at the finest resolution still kept for each period. The raw prices keep
+// their optional fields, which the averaged ones don't have.
+type PricesByResolution struct {
+	mu     sync.RWMutex
+	log    *slog.Logger
+	policy Policy
+	series map[domain.Pair][][]point
+	// rawSince holds, per pair, the time from which the prices are kept raw, once older ones were compacted
+	rawSince  map[domain.Pair]time.Time
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPricesByResolution compacts the prices every compactionInterval, or only when Compact is called if it is zero.
+func NewPricesByResolution(policy Policy, compactionInterval time.Duration) *PricesByResolution {
+	r := &PricesByResolution{
+		log:      slog.Default(),
+		policy:   policy,
+		series:   make(map[domain.Pair][][]point),
+		rawSince: make(map[domain.Pair]time.Time),
+		done:     make(chan struct{}),
+	}
+
+	if compactionInterval > 0 {
+		go r.compactPeriodically(compactionInterval)
+	}
+
+	return r
+}
+
+func (r *PricesByResolution) Store(priceUpdate domain.PriceUpdate) {
+	r.store(point{update: priceUpdate, count: 1})
+}
+
+// StoreWeighted stores a price averaging weight raw prices, e.g. restored from a snapshot. It is held with the raw
+// prices until the next compaction moves it to the tier of its time, where it is averaged again with its weight.
+func (r *PricesByResolution) StoreWeighted(priceUpdate domain.PriceUpdate, weight int64) {
+	r.store(point{update: priceUpdate, count: max(weight, 1)})
+}
+
+func (r *PricesByResolution) store(p point) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tiers, exists := r.series[p.update.Pair]
+	if !exists {
+		tiers = make([][]point, len(r.policy))
+		r.series[p.update.Pair] = tiers
+	}
+
+	raw := tiers[0]
+
+	// updates are expected in reception order, a late one is inserted in place
+	if n := len(raw); n == 0 || !p.update.ReceivedAt.Before(raw[n-1].update.ReceivedAt) {
+		tiers[0] = append(raw, p)
+		return
+	}
+
+	i := searchPoints(raw, p.update.ReceivedAt)
+	raw = append(raw, point{})
+	copy(raw[i+1:], raw[i:])
+	raw[i] = p
+	tiers[0] = raw
+}
+
+func (r *PricesByResolution) GetLatest(pair domain.Pair) (domain.PriceUpdate, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, tier := range r.series[pair] {
+		if len(tier) > 0 {
+			return tier[len(tier)-1].update, true
+		}
+	}
+
+	return domain.PriceUpdate{}, false
+}
+
+// GetSince returns the prices at or after the given time in ascending order, each period at the finest resolution
+// kept. A downsampled price is included when its bucket ends after since.
+func (r *PricesByResolution) GetSince(pair domain.Pair, since time.Time) []domain.PriceUpdate {
+	return r.GetRange(pair, since, time.Time{}, 0)
+}
+
+// GetRange returns up to limit prices in [from, to), like GetSince. A zero to has no upper bound, and a
+// non-positive limit returns all of them.
+func (r *PricesByResolution) GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tiers := r.series[pair]
+	result := make([]domain.PriceUpdate, 0)
+
+	// the coarsest tier holds the oldest period
+	for i := len(tiers) - 1; i >= 0; i-- {
+		// a downsampled point covers its whole bucket, so it is included when the bucket ends after from
+		start := from
+		if resolution := r.policy[i].Resolution; resolution > 0 {
+			start = from.Add(-(resolution - 1))
+		}
+
+		for _, p := range tiers[i][searchPoints(tiers[i], start):] {
+			if !to.IsZero() && !p.update.ReceivedAt.Before(to) {
+				break
+			}
+			if limit > 0 && len(result) == limit {
+				return result
+			}
+			result = append(result, p.update)
+		}
+	}
+
+	return result
+}
+
+// Weighted iterates over the prices of the pair in ascending order, each period at the finest resolution kept,
+// along with the number of raw prices each one averages.
+func (r *PricesByResolution) Weighted(pair domain.Pair) iter.Seq2[domain.PriceUpdate, int64] {
+	return func(yield func(domain.PriceUpdate, int64) bool) {
+		r.mu.RLock()
+
+		// copied, so the iteration doesn't hold the lock
+		tiers := r.series[pair]
+		points := make([]point, 0)
+		for i := len(tiers) - 1; i >= 0; i-- {
+			points = append(points, tiers[i]...)
+		}
+
+		r.mu.RUnlock()
+
+		for _, p := range points {
+			if !yield(p.update, p.count) {
+				return
+			}
+		}
+	}
+}
+
+// Pairs returns the pairs with stored prices.
+func (r *PricesByResolution) Pairs() []domain.Pair {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pairs := make([]domain.Pair, 0, len(r.series))
+	for pair := range r.series {
+		pairs = append(pairs, pair)
+	}
+
+	return pairs
+}
+
+// AvailableSince returns the time from which the prices of the pair are kept raw, once older ones were downsampled
+// or dropped. Until then, the whole history is held raw and there is no limit.
+func (r *PricesByResolution) AvailableSince(pair domain.Pair) (time.Time, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	since, limited := r.rawSince[pair]
+	return since, limited
+}
+
+// Compact moves the prices past the retention of each tier into the next one, averaged by its resolution, and
+// drops the prices past the retention of the last tier.
+func (r *PricesByResolution) Compact(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var compacted, dropped int
+
+	for pair, tiers := range r.series {
+		for i, tier := range r.policy {
+			expired := searchPoints(tiers[i], now.Add(-tier.Retention))
+			if expired == 0 {
+				continue
+			}
+
+			// the prices leaving any tier were raw until the retention of the first one
+			r.rawSince[pair] = now.Add(-r.policy[0].Retention)
+
+			if i+1 < len(r.policy) {
+				tiers[i+1] = mergeDownsampled(tiers[i+1], tiers[i][:expired], r.policy[i+1].Resolution)
+				compacted += expired
+			} else {
+				dropped += expired
+			}
+
+			// copied, so the backing array of the expired points can be released
+			tiers[i] = append([]point(nil), tiers[i][expired:]...)
+		}
+
+		if isEmpty(tiers) {
+			delete(r.series, pair)
+			delete(r.rawSince, pair)
+		}
+	}
+
+	if compacted > 0 || dropped > 0 {
+		r.log.Debug("Prices compacted", "downsampled", compacted, "dropped", dropped)
+	}
+}
+
+// Close stops the background compaction.
+func (r *PricesByResolution) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.done)
+	})
+	return nil
+}
+
+func (r *PricesByResolution) compactPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case now := <-ticker.C:
+			r.Compact(now)
+		}
+	}
+}
+
+// mergeDownsampled appends the points averaged by resolution to the tier. The points are newer than the tier,
+// except for the bucket they may share with its last point, which is then averaged with it.
+func mergeDownsampled(tier []point, points []point, resolution time.Duration) []point {
+	for _, p := range points {
+		bucket := p.update.ReceivedAt.Truncate(resolution)
+
+		if n := len(tier); n > 0 && tier[n-1].update.ReceivedAt.Equal(bucket) {
+			tier[n-1] = average(tier[n-1], p)
+			continue
+		}
+
+		tier = append(tier, point{
+			update: domain.PriceUpdate{Pair: p.update.Pair, Price: p.update.Price, ReceivedAt: bucket},
+			count:  p.count,
+		})
+	}
+
+	return tier
+}
+
+// average combines two points weighted by the number of raw prices they represent, keeping the time of the first.
+func average(a, b point) point {
+	count := a.count + b.count
+
+	sum := a.update.Price.Mul(decimal.NewFromInt(a.count)).Add(b.update.Price.Mul(decimal.NewFromInt(b.count)))
+
+	a.update.Price = sum.Div(decimal.NewFromInt(count))
+	a.count = count
+
+	return a
+}
+
+// searchPoints returns the index of the first point at or after t.
+func searchPoints(points []point, t time.Time) int {
+	return sort.Search(len(points), func(i int) bool {
+		return !points[i].update.ReceivedAt.Before(t)
+	})
+}
+
+func isEmpty(tiers [][]point) bool {
+	for _, tier := range tiers {
+		if len(tier) > 0 {
+			return false
+		}
+	}
+	return true
+}
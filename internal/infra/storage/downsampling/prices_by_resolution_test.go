@@ -0,0 +1,191 @@
+package downsampling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/storagetest"
+)
+
+func TestPricesByResolution(t *testing.T) {
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		now    = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		policy = Policy{
+			{Resolution: 0, Retention: time.Hour},
+			{Resolution: time.Minute, Retention: 24 * time.Hour},
+			{Resolution: time.Hour, Retention: 7 * 24 * time.Hour},
+		}
+	)
+
+	store := func(repo *PricesByResolution, receivedAt time.Time, price int64) {
+		repo.Store(domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(price), ReceivedAt: receivedAt})
+	}
+
+	t.Run("Should average prices past the raw retention by minute", func(t *testing.T) {
+		repo := NewPricesByResolution(policy, 0)
+
+		old := now.Add(-2 * time.Hour)
+		store(repo, old, 10)
+		store(repo, old.Add(20*time.Second), 20)
+		store(repo, old.Add(40*time.Second), 30)
+		store(repo, old.Add(time.Minute), 40)
+		store(repo, now.Add(-time.Minute), 50)
+
+		repo.Compact(now)
+
+		all := repo.GetSince(btcUsd, time.Time{})
+		require.Len(t, all, 3)
+
+		assert.True(t, all[0].ReceivedAt.Equal(old))
+		assert.Equal(t, "20", all[0].Price.String())
+		assert.True(t, all[1].ReceivedAt.Equal(old.Add(time.Minute)))
+		assert.Equal(t, "40", all[1].Price.String())
+		assert.True(t, all[2].ReceivedAt.Equal(now.Add(-time.Minute)), "Expected recent prices to be kept raw")
+	})
+
+	t.Run("Should weight averages when a bucket is compacted in several passes", func(t *testing.T) {
+		repo := NewPricesByResolution(policy, 0)
+
+		bucket := now.Add(-2 * time.Hour)
+		store(repo, bucket, 10)
+		store(repo, bucket.Add(10*time.Second), 20)
+		store(repo, bucket.Add(50*time.Second), 40)
+
+		repo.Compact(bucket.Add(time.Hour + 30*time.Second))
+		repo.Compact(now)
+
+		all := repo.GetSince(btcUsd, time.Time{})
+		require.Len(t, all, 1)
+		assert.Equal(t, "23.3333333333333333", all[0].Price.String())
+	})
+
+	t.Run("Should cascade to coarser tiers and drop expired prices", func(t *testing.T) {
+		repo := NewPricesByResolution(policy, 0)
+
+		store(repo, now.Add(-30*24*time.Hour), 1)
+		store(repo, now.Add(-48*time.Hour), 10)
+		store(repo, now.Add(-48*time.Hour+30*time.Minute), 20)
+		store(repo, now.Add(-2*time.Hour), 30)
+		store(repo, now, 40)
+
+		// the first pass downsamples by minute, the second by hour the minutes past their retention
+		repo.Compact(now)
+		repo.Compact(now)
+
+		all := repo.GetSince(btcUsd, time.Time{})
+		require.Len(t, all, 3)
+
+		assert.Equal(t, "15", all[0].Price.String(), "Expected the hourly average")
+		assert.True(t, all[0].ReceivedAt.Equal(now.Add(-48*time.Hour)))
+		assert.Equal(t, "30", all[1].Price.String())
+		assert.Equal(t, "40", all[2].Price.String())
+
+		latest, exists := repo.GetLatest(btcUsd)
+		require.True(t, exists)
+		assert.Equal(t, "40", latest.Price.String())
+	})
+
+	t.Run("Should query ranges across tiers", func(t *testing.T) {
+		repo := NewPricesByResolution(policy, 0)
+
+		store(repo, now.Add(-3*time.Hour), 10)
+		store(repo, now.Add(-30*time.Minute), 20)
+		store(repo, now.Add(-10*time.Minute), 30)
+		repo.Compact(now)
+
+		since := repo.GetSince(btcUsd, now.Add(-3*time.Hour+30*time.Second))
+		require.Len(t, since, 3, "Expected the minute bucket covering since to be included")
+
+		ranged := repo.GetRange(btcUsd, now.Add(-4*time.Hour), now.Add(-20*time.Minute), 0)
+		require.Len(t, ranged, 2)
+		assert.Equal(t, "20", ranged[1].Price.String())
+
+		assert.Len(t, repo.GetRange(btcUsd, time.Time{}, time.Time{}, 2), 2)
+	})
+
+	t.Run("Should include the prices at the start of the range", func(t *testing.T) {
+		repo := NewPricesByResolution(policy, 0)
+
+		store(repo, now, 10)
+
+		require.Len(t, repo.GetSince(btcUsd, now), 1)
+		require.Len(t, repo.GetRange(btcUsd, now, now.Add(time.Second), 0), 1)
+		assert.Empty(t, repo.GetRange(btcUsd, now.Add(-time.Second), now, 0), "Expected the end of the range excluded")
+
+		// the minute bucket starting at from is included, the previous one is not
+		repo.Compact(now.Add(2 * time.Hour))
+		require.Len(t, repo.GetSince(btcUsd, now), 1)
+		assert.Empty(t, repo.GetSince(btcUsd, now.Add(time.Minute)))
+	})
+
+	t.Run("Should only limit the history once prices were compacted", func(t *testing.T) {
+		repo := NewPricesByResolution(policy, 0)
+
+		store(repo, now.Add(-30*time.Minute), 10)
+		store(repo, now, 20)
+
+		repo.Compact(now)
+		_, limited := repo.AvailableSince(btcUsd)
+		assert.False(t, limited, "Expected the whole history kept raw")
+
+		repo.Compact(now.Add(time.Hour))
+		availableSince, limited := repo.AvailableSince(btcUsd)
+		assert.True(t, limited)
+		assert.True(t, availableSince.Equal(now), "Expected the raw retention threshold")
+	})
+
+	t.Run("Should keep the weight of the averaged prices stored again", func(t *testing.T) {
+		var (
+			source   = NewPricesByResolution(policy, 0)
+			restored = NewPricesByResolution(policy, 0)
+			bucket   = now.Add(-2 * time.Hour)
+		)
+
+		store(source, bucket, 10)
+		store(source, bucket.Add(10*time.Second), 20)
+		store(source, bucket.Add(20*time.Second), 30)
+		source.Compact(now)
+
+		for update, weight := range source.Weighted(btcUsd) {
+			assert.Equal(t, int64(3), weight)
+			restored.StoreWeighted(update, weight)
+		}
+		restored.Compact(now)
+
+		store(restored, bucket.Add(30*time.Second), 40)
+		restored.Compact(now)
+
+		all := restored.GetSince(btcUsd, time.Time{})
+		require.Len(t, all, 1)
+		assert.Equal(t, "25", all[0].Price.String(), "Expected the restored average to weigh 3 prices")
+	})
+
+	t.Run("Should keep out of order updates sorted", func(t *testing.T) {
+		repo := NewPricesByResolution(policy, 0)
+
+		store(repo, now, 30)
+		store(repo, now.Add(-2*time.Second), 10)
+		store(repo, now.Add(-time.Second), 20)
+
+		all := repo.GetSince(btcUsd, time.Time{})
+		require.Len(t, all, 3)
+		assert.Equal(t, "10", all[0].Price.String())
+		assert.Equal(t, "30", all[2].Price.String())
+	})
+}
+
+func TestPricesByResolution_Contract(t *testing.T) {
+	storagetest.TestPricesRepository(t, func(t *testing.T) storagetest.PricesRepository {
+		repo := NewPricesByResolution(Policy{{Resolution: 0, Retention: time.Hour}}, 0)
+		t.Cleanup(func() {
+			_ = repo.Close()
+		})
+		return repo
+	})
+}
@@ -1,6 +1,7 @@
 package in_memory
 
 import (
+	"iter"
 	"sync"
 	"time"
 
@@ -50,6 +51,33 @@ func (r *PricesByRingBuffer) GetLatest(pair domain.Pair) (domain.PriceUpdate, bo
 	return buffer.items[latestIndex], true
 }
 
+// GetOldest returns the oldest price update still held for the pair.
+func (r *PricesByRingBuffer) GetOldest(pair domain.Pair) (domain.PriceUpdate, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	buffer, exists := r.buffers[pair]
+	if !exists || buffer.size == 0 {
+		return domain.PriceUpdate{}, false
+	}
+
+	return buffer.items[buffer.head], true
+}
+
+// AvailableSince returns the reception time of the oldest update held for the pair once its buffer is full,
+// as older updates may have been dropped. Otherwise, the whole history is held and there is no limit.
+func (r *PricesByRingBuffer) AvailableSince(pair domain.Pair) (time.Time, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	buffer, exists := r.buffers[pair]
+	if !exists || buffer.size < buffer.capacity {
+		return time.Time{}, false
+	}
+
+	return buffer.items[buffer.head].ReceivedAt, true
+}
+
 func (r *PricesByRingBuffer) GetAll(pair domain.Pair) []domain.PriceUpdate {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -62,6 +90,7 @@ func (r *PricesByRingBuffer) GetAll(pair domain.Pair) []domain.PriceUpdate {
 	return buffer.toSlice()
 }
 
+// GetSince returns the updates received at or after since, in ascending order.
 func (r *PricesByRingBuffer) GetSince(pair domain.Pair, since time.Time) []domain.PriceUpdate {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -71,25 +100,73 @@ func (r *PricesByRingBuffer) GetSince(pair domain.Pair, since time.Time) []domai
 		return []domain.PriceUpdate{}
 	}
 
-	allUpdates := buffer.toSlice()
+	return buffer.copyRange(buffer.search(since), buffer.size)
+}
+
+// GetRange returns up to limit updates received in [from, to), in ascending order. A non-positive limit returns all of them.
+func (r *PricesByRingBuffer) GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	buffer, exists := r.buffers[pair]
+	if !exists || buffer.size == 0 {
+		return []domain.PriceUpdate{}
+	}
+
+	start, end := buffer.search(from), buffer.search(to)
+
+	return buffer.copyRange(start, limitEnd(start, end, limit))
+}
+
+// Range iterates over the updates received in [from, to) in ascending order, without copying the history.
+// The repository is read-locked during the iteration, so the loop body must not block nor write to it.
+func (r *PricesByRingBuffer) Range(pair domain.Pair, from, to time.Time) iter.Seq[domain.PriceUpdate] {
+	return func(yield func(domain.PriceUpdate) bool) {
+		r.mutex.RLock()
+		defer r.mutex.RUnlock()
+
+		buffer, exists := r.buffers[pair]
+		if !exists {
+			return
+		}
 
-	// Find the first update that is not before 'since'
-	startIndex := len(allUpdates)
-	for i, update := range allUpdates {
-		if !update.ReceivedAt.Before(since) {
-			startIndex = i
-			break
+		for i, end := buffer.search(from), buffer.search(to); i < end; i++ {
+			if !yield(buffer.at(i)) {
+				return
+			}
 		}
 	}
+}
 
-	if startIndex >= len(allUpdates) {
-		return []domain.PriceUpdate{}
+// Pairs returns the pairs with stored price updates.
+func (r *PricesByRingBuffer) Pairs() []domain.Pair {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	pairs := make([]domain.Pair, 0, len(r.buffers))
+	for pair, buffer := range r.buffers {
+		if buffer.size > 0 {
+			pairs = append(pairs, pair)
+		}
 	}
 
-	result := make([]domain.PriceUpdate, len(allUpdates)-startIndex)
-	copy(result, allUpdates[startIndex:])
+	return pairs
+}
 
-	return result
+// Resize changes the maximum number of items kept per pair, keeping the most recent ones when shrinking.
+func (r *PricesByRingBuffer) Resize(maxHistorySize int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.maxHistorySize = maxHistorySize
+
+	for pair, buffer := range r.buffers {
+		resized := newRingBuffer(maxHistorySize)
+		for _, item := range buffer.toSlice() {
+			resized.push(item)
+		}
+		r.buffers[pair] = resized
+	}
 }
 
 // Clear removes all price updates from the repository.
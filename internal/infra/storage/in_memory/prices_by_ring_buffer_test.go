@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/storagetest"
 )
 
 func TestPricesByRingBufferStore(t *testing.T) {
@@ -60,6 +61,31 @@ func TestPricesByRingBufferStore(t *testing.T) {
 		assert.True(t, latest.ReceivedAt.Equal(update5.ReceivedAt), "Expected receivedAt to be %v, got %v", now, latest.ReceivedAt)
 	})
 
+	t.Run("Should return the oldest item held and the history limit", func(t *testing.T) {
+		oldest, exists := repo.GetOldest(BtcUsd)
+		assert.True(t, exists, "Expected price update to exist")
+		assert.True(t, oldest.Price.Equal(update3.Price), "Expected price to be %s, got %s", update3.Price, oldest.Price)
+
+		availableSince, limited := repo.AvailableSince(BtcUsd)
+		assert.True(t, limited, "Expected a full buffer to limit the history")
+		assert.True(t, availableSince.Equal(update3.ReceivedAt))
+
+		_, limited = NewPricesByRingBuffer(maxSize).AvailableSince(BtcUsd)
+		assert.False(t, limited, "Expected no limit when nothing was dropped")
+	})
+
+	t.Run("Should keep the most recent items when resized", func(t *testing.T) {
+		repo.Resize(2)
+
+		all := repo.GetAll(BtcUsd)
+		assert.Len(t, all, 2, "Expected only the latest 2 items to be kept")
+		assert.True(t, all[0].Price.Equal(update4.Price), "Expected first price to be %s, got %s", update4.Price, all[0].Price)
+
+		repo.Resize(maxSize)
+		repo.Store(update5)
+		assert.Len(t, repo.GetAll(BtcUsd), maxSize, "Expected the buffer to grow back to %d items", maxSize)
+	})
+
 	t.Run("Should clean all items", func(t *testing.T) {
 		repo.Clear()
 
@@ -70,3 +96,9 @@ func TestPricesByRingBufferStore(t *testing.T) {
 		assert.Len(t, history, 0, "Expected empty history after clear")
 	})
 }
+
+func TestPricesByRingBuffer_Contract(t *testing.T) {
+	storagetest.TestPricesRepository(t, func(_ *testing.T) storagetest.PricesRepository {
+		return NewPricesByRingBuffer(10)
+	})
+}
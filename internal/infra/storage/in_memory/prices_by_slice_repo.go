@@ -1,6 +1,8 @@
 package in_memory
 
 import (
+	"iter"
+	"sort"
 	"sync"
 	"time"
 
@@ -67,30 +69,66 @@ func (r *PricesBySliceRepo) GetAll(pair domain.Pair) []domain.PriceUpdate {
 	return result
 }
 
+// GetSince returns the updates received at or after since, in ascending order.
 func (r *PricesBySliceRepo) GetSince(pair domain.Pair, since time.Time) []domain.PriceUpdate {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	history, exists := r.prices[pair]
-	if !exists || len(history) == 0 {
-		return []domain.PriceUpdate{}
-	}
+	history := r.prices[pair]
+	start := searchReceivedAt(history, since)
 
-	startIndex := 0
-	for i, update := range history {
-		if !update.ReceivedAt.Before(since) {
-			startIndex = i
-			break
-		}
-		if i == len(history)-1 {
-			startIndex = len(history)
+	result := make([]domain.PriceUpdate, len(history)-start)
+	copy(result, history[start:])
+
+	return result
+}
+
+// GetRange returns up to limit updates received in [from, to), in ascending order. A non-positive limit returns all of them.
+func (r *PricesBySliceRepo) GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	history := r.prices[pair]
+	start, end := searchReceivedAt(history, from), searchReceivedAt(history, to)
+	end = limitEnd(start, end, limit)
+
+	result := make([]domain.PriceUpdate, end-start)
+	copy(result, history[start:end])
+
+	return result
+}
+
+// Range iterates over the updates received in [from, to) in ascending order, without copying the history.
+// The repository is read-locked during the iteration, so the loop body must not block nor write to it.
+func (r *PricesBySliceRepo) Range(pair domain.Pair, from, to time.Time) iter.Seq[domain.PriceUpdate] {
+	return func(yield func(domain.PriceUpdate) bool) {
+		r.mutex.RLock()
+		defer r.mutex.RUnlock()
+
+		history := r.prices[pair]
+		start := searchReceivedAt(history, from)
+
+		for _, update := range history[start:limitEnd(start, searchReceivedAt(history, to), 0)] {
+			if !yield(update) {
+				return
+			}
 		}
 	}
+}
 
-	result := make([]domain.PriceUpdate, len(history)-startIndex)
-	copy(result, history[startIndex:])
+// Pairs returns the pairs with stored price updates.
+func (r *PricesBySliceRepo) Pairs() []domain.Pair {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
 
-	return result
+	pairs := make([]domain.Pair, 0, len(r.prices))
+	for pair, history := range r.prices {
+		if len(history) > 0 {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return pairs
 }
 
 func (r *PricesBySliceRepo) Clear() {
@@ -99,3 +137,21 @@ func (r *PricesBySliceRepo) Clear() {
 
 	r.prices = make(map[domain.Pair][]domain.PriceUpdate)
 }
+
+// searchReceivedAt returns the index of the first update received at or after t, the history being in reception order.
+func searchReceivedAt(history []domain.PriceUpdate, t time.Time) int {
+	return sort.Search(len(history), func(i int) bool {
+		return !history[i].ReceivedAt.Before(t)
+	})
+}
+
+// limitEnd caps the end of the [start, end) range to hold at most limit items, when limit is positive.
+func limitEnd(start, end, limit int) int {
+	if end < start {
+		return start
+	}
+	if limit > 0 && end-start > limit {
+		return start + limit
+	}
+	return end
+}
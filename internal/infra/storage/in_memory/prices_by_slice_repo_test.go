@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/storagetest"
 )
 
 func TestPricesBySliceRepoStore(t *testing.T) {
@@ -193,3 +194,9 @@ func TestPricesBySliceRepoClear(t *testing.T) {
 	history := repo.GetAll(pair)
 	assert.Len(t, history, 0, "Expected empty history after clear")
 }
+
+func TestPricesBySliceRepo_Contract(t *testing.T) {
+	storagetest.TestPricesRepository(t, func(_ *testing.T) storagetest.PricesRepository {
+		return NewPricesBySliceRepo(10)
+	})
+}
@@ -0,0 +1,132 @@
+package in_memory
+
+import (
+	"fmt"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain/domaintest"
+)
+
+type rangeRepository interface {
+	Store(priceUpdate domain.PriceUpdate)
+	GetSince(pair domain.Pair, since time.Time) []domain.PriceUpdate
+	GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate
+	Range(pair domain.Pair, from, to time.Time) iter.Seq[domain.PriceUpdate]
+}
+
+func TestRangeQueries(t *testing.T) {
+	var (
+		btcUsd  = domain.NewPair(domain.BTC, domain.USD)
+		start   = time.Now().Add(-time.Hour)
+		updates = domaintest.NewPriceUpdates(btcUsd, 15, start, time.Second)
+	)
+
+	repositories := map[string]rangeRepository{
+		"ring buffer": NewPricesByRingBuffer(10),
+		"slice":       NewPricesBySliceRepo(10),
+	}
+
+	for name, repo := range repositories {
+		// 15 updates in a capacity of 10 wrap the ring around, keeping updates 5 to 14
+		for _, update := range updates {
+			repo.Store(update)
+		}
+
+		t.Run(name+": should return updates since a timestamp", func(t *testing.T) {
+			since := repo.GetSince(btcUsd, updates[12].ReceivedAt)
+			require.Len(t, since, 3)
+			assert.True(t, since[0].Price.Equal(updates[12].Price))
+
+			assert.Len(t, repo.GetSince(btcUsd, start), 10)
+			assert.Empty(t, repo.GetSince(btcUsd, time.Now()))
+			assert.Empty(t, repo.GetSince(domain.NewPair(domain.ETH, domain.USD), start))
+		})
+
+		t.Run(name+": should return a limited range", func(t *testing.T) {
+			all := repo.GetRange(btcUsd, updates[7].ReceivedAt, updates[12].ReceivedAt, 0)
+			require.Len(t, all, 5)
+			assert.True(t, all[0].Price.Equal(updates[7].Price))
+			assert.True(t, all[4].Price.Equal(updates[11].Price))
+
+			limited := repo.GetRange(btcUsd, start, time.Now(), 3)
+			require.Len(t, limited, 3)
+			assert.True(t, limited[0].Price.Equal(updates[5].Price))
+
+			assert.Empty(t, repo.GetRange(btcUsd, updates[12].ReceivedAt, updates[7].ReceivedAt, 0))
+		})
+
+		t.Run(name+": should iterate over a range", func(t *testing.T) {
+			var prices []string
+			for update := range repo.Range(btcUsd, updates[8].ReceivedAt, updates[11].ReceivedAt) {
+				prices = append(prices, update.Price.String())
+			}
+			assert.Equal(t, []string{updates[8].Price.String(), updates[9].Price.String(), updates[10].Price.String()}, prices)
+
+			count := 0
+			for range repo.Range(btcUsd, start, time.Now()) {
+				count++
+				if count == 2 {
+					break
+				}
+			}
+			assert.Equal(t, 2, count, "Expected the iteration to stop on break")
+
+			for range repo.Range(btcUsd, updates[12].ReceivedAt, updates[7].ReceivedAt) {
+				t.Fatal("Expected no updates in an inverted range")
+			}
+		})
+	}
+}
+
+func BenchmarkRangeQueries(b *testing.B) {
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		start  = time.Now().Add(-24 * time.Hour)
+	)
+
+	for _, size := range []int{1_000, 100_000} {
+		updates := domaintest.NewPriceUpdates(btcUsd, size+size/2, start, time.Second)
+		// a reconnecting client asking for the last 100 updates
+		since := updates[len(updates)-100].ReceivedAt
+
+		repositories := map[string]rangeRepository{
+			"RingBuffer": NewPricesByRingBuffer(size),
+			"Slice":      NewPricesBySliceRepo(size),
+		}
+
+		for name, repo := range repositories {
+			for _, update := range updates {
+				repo.Store(update)
+			}
+
+			b.Run(fmt.Sprintf("%s/GetSince/%d", name, size), func(b *testing.B) {
+				b.ReportAllocs()
+				for b.Loop() {
+					_ = repo.GetSince(btcUsd, since)
+				}
+			})
+
+			b.Run(fmt.Sprintf("%s/GetRange/%d", name, size), func(b *testing.B) {
+				b.ReportAllocs()
+				for b.Loop() {
+					_ = repo.GetRange(btcUsd, start, time.Now(), 100)
+				}
+			})
+
+			b.Run(fmt.Sprintf("%s/Range/%d", name, size), func(b *testing.B) {
+				b.ReportAllocs()
+				for b.Loop() {
+					for update := range repo.Range(btcUsd, since, time.Now()) {
+						_ = update
+					}
+				}
+			})
+		}
+	}
+}
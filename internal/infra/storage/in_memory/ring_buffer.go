@@ -1,6 +1,11 @@
 package in_memory
 
-import "github.com/tonytcb/crypto-pricing-api/internal/domain"
+import (
+	"sort"
+	"time"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+)
 
 // ringBuffer is not thread-safe and depends on locks in the high-level repository
 type ringBuffer struct {
@@ -47,3 +52,30 @@ func (rb *ringBuffer) toSlice() []domain.PriceUpdate {
 
 	return result
 }
+
+// at returns the i-th oldest item, i must be lower than size.
+func (rb *ringBuffer) at(i int) domain.PriceUpdate {
+	return rb.items[(rb.head+i)%rb.capacity]
+}
+
+// search returns the index of the first item received at or after t, or size if there is none.
+// Items are expected to be pushed in reception order.
+func (rb *ringBuffer) search(t time.Time) int {
+	return sort.Search(rb.size, func(i int) bool {
+		return !rb.at(i).ReceivedAt.Before(t)
+	})
+}
+
+// copyRange copies the items in [start, end) into a new slice, using at most two copies as the range may wrap around.
+func (rb *ringBuffer) copyRange(start, end int) []domain.PriceUpdate {
+	result := make([]domain.PriceUpdate, end-start)
+	if len(result) == 0 {
+		return result
+	}
+
+	first := (rb.head + start) % rb.capacity
+	n := copy(result, rb.items[first:min(first+len(result), rb.capacity)])
+	copy(result[n:], rb.items[:len(result)-n])
+
+	return result
+}
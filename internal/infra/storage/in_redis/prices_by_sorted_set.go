@@ -0,0 +1,273 @@
+package in_redis
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/codec"
+)
+
+const (
+	defaultKeyPrefix  = "prices"
+	redisQueryTimeout = 3 * time.Second
+	memberSeparator   = ":"
+)
+
+// PricesBySortedSet is a prices repository shared by every instance connected to the same Redis. The updates
+// of each pair are kept in a sorted set scored by their reception time in milliseconds, with members encoded as
+// "<received at unix nano>:<price>", followed by ":<JSON extras>" for the backfilled, synthetic or quoted updates,
+// so storing the same update twice, e.g. from two instances, keeps a single copy.
+type PricesBySortedSet struct {
+	log            *slog.Logger
+	client         redis.UniversalClient
+	keyPrefix      string
+	maxHistorySize int
+	retention      time.Duration
+}
+
+// NewPricesBySortedSet keeps up to maxHistorySize updates per pair, and drops the updates older than retention.
+// A zero retention keeps the updates until they are pushed out by newer ones.
+func NewPricesBySortedSet(client redis.UniversalClient, keyPrefix string, maxHistorySize int, retention time.Duration) *PricesBySortedSet {
+	if keyPrefix == "" {
+		keyPrefix = defaultKeyPrefix
+	}
+
+	return &PricesBySortedSet{
+		log:            slog.Default(),
+		client:         client,
+		keyPrefix:      keyPrefix,
+		maxHistorySize: maxHistorySize,
+		retention:      retention,
+	}
+}
+
+func (r *PricesBySortedSet) Store(priceUpdate domain.PriceUpdate) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisQueryTimeout)
+	defer cancel()
+
+	key := r.key(priceUpdate.Pair)
+
+	member, err := encodeMember(priceUpdate)
+	if err != nil {
+		r.log.Error("Failed to encode price update", "pair", priceUpdate.Pair.String(), "error", err.Error())
+		return
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, key, redis.Z{Score: score(priceUpdate.ReceivedAt), Member: member})
+
+		if r.maxHistorySize > 0 {
+			pipe.ZRemRangeByRank(ctx, key, 0, int64(-r.maxHistorySize-1))
+		}
+
+		if r.retention > 0 {
+			threshold := score(time.Now().Add(-r.retention))
+			pipe.ZRemRangeByScore(ctx, key, "-inf", "("+formatScore(threshold))
+		}
+
+		return nil
+	})
+	if err != nil {
+		r.log.Error("Failed to store price update", "pair", priceUpdate.Pair.String(), "error", err.Error())
+	}
+}
+
+func (r *PricesBySortedSet) GetLatest(pair domain.Pair) (domain.PriceUpdate, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisQueryTimeout)
+	defer cancel()
+
+	members, err := r.client.ZRange(ctx, r.key(pair), -1, -1).Result()
+	if err != nil {
+		r.log.Error("Failed to query latest price", "pair", pair.String(), "error", err.Error())
+		return domain.PriceUpdate{}, false
+	}
+
+	updates := r.decodeMembers(pair, members)
+	if len(updates) == 0 {
+		return domain.PriceUpdate{}, false
+	}
+
+	return updates[0], true
+}
+
+func (r *PricesBySortedSet) GetAll(pair domain.Pair) []domain.PriceUpdate {
+	return r.GetSince(pair, time.Time{})
+}
+
+// GetSince returns the prices received at or after since, in ascending order.
+func (r *PricesBySortedSet) GetSince(pair domain.Pair, since time.Time) []domain.PriceUpdate {
+	ctx, cancel := context.WithTimeout(context.Background(), redisQueryTimeout)
+	defer cancel()
+
+	// scores only have millisecond precision, the exact filtering is done on the decoded updates
+	members, err := r.client.ZRangeByScore(ctx, r.key(pair), &redis.ZRangeBy{
+		Min: formatScore(score(since)),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		r.log.Error("Failed to query prices", "pair", pair.String(), "error", err.Error())
+		return nil
+	}
+
+	updates := r.decodeMembers(pair, members)
+
+	filtered := updates[:0]
+	for _, update := range updates {
+		if !update.ReceivedAt.Before(since) {
+			filtered = append(filtered, update)
+		}
+	}
+
+	return filtered
+}
+
+// GetRange returns up to limit prices received in [from, to), in ascending order. A non-positive limit returns all of them.
+func (r *PricesBySortedSet) GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate {
+	ctx, cancel := context.WithTimeout(context.Background(), redisQueryTimeout)
+	defer cancel()
+
+	// the score range is widened to the milliseconds holding the bounds, the exact filtering is done on the decoded updates
+	members, err := r.client.ZRangeByScore(ctx, r.key(pair), &redis.ZRangeBy{
+		Min: formatScore(score(from)),
+		Max: formatScore(score(to)),
+	}).Result()
+	if err != nil {
+		r.log.Error("Failed to query prices", "pair", pair.String(), "error", err.Error())
+		return nil
+	}
+
+	result := make([]domain.PriceUpdate, 0)
+	for _, update := range r.decodeMembers(pair, members) {
+		if update.ReceivedAt.Before(from) || !update.ReceivedAt.Before(to) {
+			continue
+		}
+		if limit > 0 && len(result) == limit {
+			break
+		}
+		result = append(result, update)
+	}
+
+	return result
+}
+
+// AvailableSince returns the latest of the retention threshold and, once the pair holds the max history size,
+// the reception time of its oldest update.
+func (r *PricesBySortedSet) AvailableSince(pair domain.Pair) (time.Time, bool) {
+	var (
+		since   time.Time
+		limited bool
+	)
+
+	if r.retention > 0 {
+		since, limited = time.Now().Add(-r.retention), true
+	}
+
+	if r.maxHistorySize <= 0 {
+		return since, limited
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisQueryTimeout)
+	defer cancel()
+
+	count, err := r.client.ZCard(ctx, r.key(pair)).Result()
+	if err != nil || count < int64(r.maxHistorySize) {
+		return since, limited
+	}
+
+	members, err := r.client.ZRange(ctx, r.key(pair), 0, 0).Result()
+	if err != nil {
+		return since, limited
+	}
+
+	if oldest := r.decodeMembers(pair, members); len(oldest) > 0 && oldest[0].ReceivedAt.After(since) {
+		return oldest[0].ReceivedAt, true
+	}
+
+	return since, limited
+}
+
+func (r *PricesBySortedSet) key(pair domain.Pair) string {
+	return r.keyPrefix + memberSeparator + pair.String()
+}
+
+func (r *PricesBySortedSet) decodeMembers(pair domain.Pair, members []string) []domain.PriceUpdate {
+	updates := make([]domain.PriceUpdate, 0, len(members))
+
+	for _, member := range members {
+		update, err := decodeMember(pair, member)
+		if err != nil {
+			r.log.Error("Failed to decode stored price", "pair", pair.String(), "member", member, "error", err.Error())
+			continue
+		}
+
+		updates = append(updates, update)
+	}
+
+	return updates
+}
+
+func score(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixMilli())
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+func encodeMember(update domain.PriceUpdate) (string, error) {
+	member := strconv.FormatInt(update.ReceivedAt.UnixNano(), 10) + memberSeparator + update.Price.String()
+
+	extras, err := codec.EncodeExtras(update)
+	if err != nil {
+		return "", err
+	}
+
+	if extras != nil {
+		member += memberSeparator + string(extras)
+	}
+
+	return member, nil
+}
+
+func decodeMember(pair domain.Pair, member string) (domain.PriceUpdate, error) {
+	receivedAt, rest, found := strings.Cut(member, memberSeparator)
+	if !found {
+		return domain.PriceUpdate{}, errors.New("missing separator")
+	}
+
+	// the price never holds the separator, unlike the JSON extras following it
+	price, extras, _ := strings.Cut(rest, memberSeparator)
+
+	unixNano, err := strconv.ParseInt(receivedAt, 10, 64)
+	if err != nil {
+		return domain.PriceUpdate{}, errors.Wrap(err, "invalid received at")
+	}
+
+	decimalPrice, err := decimal.NewFromString(price)
+	if err != nil {
+		return domain.PriceUpdate{}, errors.Wrap(err, "invalid price")
+	}
+
+	update := domain.PriceUpdate{
+		Pair:       pair,
+		Price:      decimalPrice,
+		ReceivedAt: time.Unix(0, unixNano).UTC(),
+	}
+
+	if err = codec.DecodeExtras([]byte(extras), &update); err != nil {
+		return domain.PriceUpdate{}, err
+	}
+
+	return update, nil
+}
@@ -0,0 +1,141 @@
+package in_redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain/domaintest"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/storagetest"
+)
+
+func newTestClient(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	return client, server
+}
+
+func TestPricesBySortedSet(t *testing.T) {
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		ethUsd = domain.NewPair(domain.ETH, domain.USD)
+		start  = time.Now().Add(-time.Minute)
+	)
+
+	t.Run("Should share the history between instances", func(t *testing.T) {
+		client, _ := newTestClient(t)
+
+		var (
+			first  = NewPricesBySortedSet(client, "", 10, 0)
+			second = NewPricesBySortedSet(client, "", 10, 0)
+			data   = domaintest.NewPriceUpdates(btcUsd, 3, start, time.Second)
+		)
+
+		for _, update := range data {
+			first.Store(update)
+			second.Store(update) // the same update stored by another instance
+		}
+		first.Store(domaintest.NewPriceUpdates(ethUsd, 1, start, time.Second)[0])
+
+		all := second.GetAll(btcUsd)
+		require.Len(t, all, 3, "Expected duplicated updates to be stored once")
+		assert.True(t, all[0].Price.Equal(data[0].Price))
+		assert.True(t, all[2].ReceivedAt.Equal(data[2].ReceivedAt))
+
+		latest, exists := second.GetLatest(btcUsd)
+		require.True(t, exists)
+		assert.True(t, latest.Price.Equal(data[2].Price))
+
+		_, exists = second.GetLatest(domain.NewPair("SOL", domain.USD))
+		assert.False(t, exists)
+	})
+
+	t.Run("Should return prices received since a timestamp with nanosecond precision", func(t *testing.T) {
+		client, _ := newTestClient(t)
+		repo := NewPricesBySortedSet(client, "test", 10, 0)
+
+		base := time.Now().Truncate(time.Millisecond)
+		for _, offset := range []time.Duration{100, 200, 300} {
+			repo.Store(domain.PriceUpdate{Pair: btcUsd, Price: decimal.NewFromInt(1), ReceivedAt: base.Add(offset)})
+		}
+
+		since := repo.GetSince(btcUsd, base.Add(201))
+		require.Len(t, since, 1)
+		assert.True(t, since[0].ReceivedAt.Equal(base.Add(300)))
+
+		since = repo.GetSince(btcUsd, base.Add(200))
+		require.Len(t, since, 2, "Expected the price received at since to be included")
+		assert.True(t, since[0].ReceivedAt.Equal(base.Add(200)))
+	})
+
+	t.Run("Should return a limited time range", func(t *testing.T) {
+		client, _ := newTestClient(t)
+		repo := NewPricesBySortedSet(client, "", 10, 0)
+
+		data := domaintest.NewPriceUpdates(btcUsd, 6, start, time.Second)
+		for _, update := range data {
+			repo.Store(update)
+		}
+
+		ranged := repo.GetRange(btcUsd, data[1].ReceivedAt, data[4].ReceivedAt, 0)
+		require.Len(t, ranged, 3)
+		assert.True(t, ranged[0].ReceivedAt.Equal(data[1].ReceivedAt))
+
+		assert.Len(t, repo.GetRange(btcUsd, data[1].ReceivedAt, data[4].ReceivedAt, 2), 2)
+	})
+
+	t.Run("Should keep only the max history size", func(t *testing.T) {
+		client, _ := newTestClient(t)
+		repo := NewPricesBySortedSet(client, "", 3, 0)
+
+		data := domaintest.NewPriceUpdates(btcUsd, 5, start, time.Second)
+		for _, update := range data {
+			repo.Store(update)
+		}
+
+		all := repo.GetAll(btcUsd)
+		require.Len(t, all, 3)
+		assert.True(t, all[0].Price.Equal(data[2].Price))
+	})
+
+	t.Run("Should drop updates older than the retention", func(t *testing.T) {
+		client, _ := newTestClient(t)
+		repo := NewPricesBySortedSet(client, "", 10, time.Hour)
+
+		for _, update := range domaintest.NewPriceUpdates(btcUsd, 3, time.Now().Add(-2*time.Hour), time.Second) {
+			repo.Store(update)
+		}
+		repo.Store(domaintest.NewPriceUpdates(btcUsd, 1, time.Now(), time.Second)[0])
+
+		assert.Len(t, repo.GetAll(btcUsd), 1)
+	})
+
+	t.Run("Should skip undecodable members", func(t *testing.T) {
+		client, server := newTestClient(t)
+		repo := NewPricesBySortedSet(client, "", 10, 0)
+
+		repo.Store(domaintest.NewPriceUpdates(btcUsd, 1, start, time.Second)[0])
+		_, err := server.ZAdd("prices:BTCUSD", 1, "garbage")
+		require.NoError(t, err)
+
+		assert.Len(t, repo.GetAll(btcUsd), 1)
+	})
+}
+
+func TestPricesBySortedSet_Contract(t *testing.T) {
+	storagetest.TestPricesRepository(t, func(t *testing.T) storagetest.PricesRepository {
+		client, _ := newTestClient(t)
+		return NewPricesBySortedSet(client, "", 10, 0)
+	})
+}
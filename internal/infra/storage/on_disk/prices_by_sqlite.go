@@ -0,0 +1,250 @@
+package on_disk
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	_ "modernc.org/sqlite" // registers the pure-Go "sqlite" driver
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/codec"
+)
+
+const (
+	sqliteQueryTimeout            = 5 * time.Second
+	defaultRetentionCheckInterval = time.Minute
+)
+
+type SQLiteOptions struct {
+	Path string
+	// Retention is how long prices are kept, zero keeps them forever
+	Retention time.Duration
+	// RetentionCheckInterval is how often the expired prices are deleted, one minute by default
+	RetentionCheckInterval time.Duration
+}
+
+// PricesBySQLite is a prices repository backed by an embedded SQLite database, meant to keep a long history.
+// Prices are indexed by pair and reception time, so time range reads don't scan the whole table.
+type PricesBySQLite struct {
+	log       *slog.Logger
+	db        *sql.DB
+	opts      SQLiteOptions
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewPricesBySQLite(opts SQLiteOptions) (*PricesBySQLite, error) {
+	if dir := filepath.Dir(opts.Path); dir != "" {
+		if err := os.MkdirAll(dir, dirPermission); err != nil {
+			return nil, errors.Wrap(err, "failed to create sqlite directory")
+		}
+	}
+
+	if opts.RetentionCheckInterval <= 0 {
+		opts.RetentionCheckInterval = defaultRetentionCheckInterval
+	}
+
+	// the pragmas are set per connection: WAL journaling lets readers run concurrently with the writer
+	dsn := "file:" + opts.Path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open sqlite database")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sqliteQueryTimeout)
+	defer cancel()
+
+	if _, err = migrate(ctx, db, sqliteMigrations); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	r := &PricesBySQLite{
+		log:  slog.Default(),
+		db:   db,
+		opts: opts,
+		done: make(chan struct{}),
+	}
+
+	if opts.Retention > 0 {
+		r.deleteExpired()
+		go r.enforceRetention()
+	}
+
+	return r, nil
+}
+
+func (r *PricesBySQLite) Store(priceUpdate domain.PriceUpdate) {
+	ctx, cancel := context.WithTimeout(context.Background(), sqliteQueryTimeout)
+	defer cancel()
+
+	const query = `INSERT INTO prices (base, quote, price, received_at, backfilled, extras) VALUES (?, ?, ?, ?, ?, ?)`
+
+	// the legs and the quote are kept as JSON, NULL for the plain updates
+	extras, err := codec.EncodeExtras(priceUpdate)
+	if err != nil {
+		r.log.Error("Failed to encode price update", "pair", priceUpdate.Pair.String(), "error", err.Error())
+		return
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		string(priceUpdate.Pair.From),
+		string(priceUpdate.Pair.To),
+		priceUpdate.Price.String(),
+		priceUpdate.ReceivedAt.UnixNano(),
+		priceUpdate.Backfilled,
+		sql.NullString{String: string(extras), Valid: extras != nil},
+	)
+	if err != nil {
+		r.log.Error("Failed to store price update", "pair", priceUpdate.Pair.String(), "error", err.Error())
+	}
+}
+
+func (r *PricesBySQLite) GetLatest(pair domain.Pair) (domain.PriceUpdate, bool) {
+	const query = `SELECT price, received_at, backfilled, extras FROM prices WHERE base = ? AND quote = ?
+		ORDER BY received_at DESC, id DESC LIMIT 1`
+
+	updates := r.query(pair, query, string(pair.From), string(pair.To))
+	if len(updates) == 0 {
+		return domain.PriceUpdate{}, false
+	}
+
+	return updates[0], true
+}
+
+// GetSince returns the prices received at or after since, in ascending order.
+func (r *PricesBySQLite) GetSince(pair domain.Pair, since time.Time) []domain.PriceUpdate {
+	const query = `SELECT price, received_at, backfilled, extras FROM prices WHERE base = ? AND quote = ? AND received_at >= ?
+		ORDER BY received_at, id`
+
+	return r.query(pair, query, string(pair.From), string(pair.To), since.UnixNano())
+}
+
+// GetRange returns up to limit prices received in [from, to), in ascending order. A non-positive limit returns all of them.
+func (r *PricesBySQLite) GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate {
+	const query = `SELECT price, received_at, backfilled, extras FROM prices WHERE base = ? AND quote = ? AND received_at >= ? AND received_at < ?
+		ORDER BY received_at, id LIMIT ?`
+
+	if limit <= 0 {
+		limit = -1 // no limit in SQLite
+	}
+
+	return r.query(pair, query, string(pair.From), string(pair.To), from.UnixNano(), to.UnixNano(), limit)
+}
+
+// AvailableSince returns the retention threshold, as older prices are deleted.
+func (r *PricesBySQLite) AvailableSince(_ domain.Pair) (time.Time, bool) {
+	if r.opts.Retention <= 0 {
+		return time.Time{}, false
+	}
+	return time.Now().Add(-r.opts.Retention), true
+}
+
+// Close stops the retention enforcement and closes the database. The repository must not be used afterward.
+func (r *PricesBySQLite) Close() error {
+	var err error
+
+	r.closeOnce.Do(func() {
+		close(r.done)
+		err = errors.Wrap(r.db.Close(), "failed to close sqlite database")
+	})
+
+	return err
+}
+
+func (r *PricesBySQLite) query(pair domain.Pair, query string, args ...any) []domain.PriceUpdate {
+	ctx, cancel := context.WithTimeout(context.Background(), sqliteQueryTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log.Error("Failed to query prices", "pair", pair.String(), "error", err.Error())
+		return nil
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var updates []domain.PriceUpdate
+
+	for rows.Next() {
+		var (
+			price      string
+			receivedAt int64
+			backfilled bool
+			extras     sql.NullString
+		)
+
+		if err = rows.Scan(&price, &receivedAt, &backfilled, &extras); err != nil {
+			r.log.Error("Failed to scan price", "pair", pair.String(), "error", err.Error())
+			return nil
+		}
+
+		decimalPrice, err := decimal.NewFromString(price)
+		if err != nil {
+			r.log.Error("Failed to parse stored price", "pair", pair.String(), "price", price, "error", err.Error())
+			continue
+		}
+
+		update := domain.PriceUpdate{
+			Pair:       pair,
+			Price:      decimalPrice,
+			ReceivedAt: time.Unix(0, receivedAt).UTC(),
+			Backfilled: backfilled,
+		}
+
+		if err = codec.DecodeExtras([]byte(extras.String), &update); err != nil {
+			r.log.Error("Failed to parse stored price extras", "pair", pair.String(), "error", err.Error())
+		}
+
+		updates = append(updates, update)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log.Error("Failed to read prices", "pair", pair.String(), "error", err.Error())
+		return nil
+	}
+
+	return updates
+}
+
+func (r *PricesBySQLite) enforceRetention() {
+	ticker := time.NewTicker(r.opts.RetentionCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.deleteExpired()
+		}
+	}
+}
+
+func (r *PricesBySQLite) deleteExpired() {
+	ctx, cancel := context.WithTimeout(context.Background(), sqliteQueryTimeout)
+	defer cancel()
+
+	threshold := time.Now().Add(-r.opts.Retention).UnixNano()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM prices WHERE received_at < ?`, threshold)
+	if err != nil {
+		r.log.Error("Failed to delete expired prices", "error", err.Error())
+		return
+	}
+
+	if deleted, _ := result.RowsAffected(); deleted > 0 {
+		r.log.Debug("Expired prices deleted", "count", deleted)
+	}
+}
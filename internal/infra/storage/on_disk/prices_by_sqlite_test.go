@@ -0,0 +1,172 @@
+package on_disk
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain/domaintest"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/storagetest"
+)
+
+func newTestSQLite(t *testing.T, opts SQLiteOptions) *PricesBySQLite {
+	if opts.Path == "" {
+		opts.Path = filepath.Join(t.TempDir(), "prices.db")
+	}
+
+	repo, err := NewPricesBySQLite(opts)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = repo.Close()
+	})
+
+	return repo
+}
+
+func TestPricesBySQLite_Queries(t *testing.T) {
+	var (
+		btcUsd  = domain.NewPair(domain.BTC, domain.USD)
+		ethUsd  = domain.NewPair(domain.ETH, domain.USD)
+		start   = time.Now().Add(-time.Hour).Truncate(time.Second)
+		btcData = domaintest.NewPriceUpdates(btcUsd, 10, start, time.Second)
+		repo    = newTestSQLite(t, SQLiteOptions{})
+	)
+
+	for _, update := range append(btcData, domaintest.NewPriceUpdates(ethUsd, 3, start, time.Second)...) {
+		repo.Store(update)
+	}
+
+	t.Run("Should return the latest price of a pair", func(t *testing.T) {
+		latest, exists := repo.GetLatest(btcUsd)
+		require.True(t, exists)
+		assert.True(t, latest.Price.Equal(btcData[9].Price))
+		assert.Equal(t, btcUsd, latest.Pair)
+
+		_, exists = repo.GetLatest(domain.NewPair("SOL", domain.USD))
+		assert.False(t, exists)
+	})
+
+	t.Run("Should return prices since a timestamp in ascending order", func(t *testing.T) {
+		since := repo.GetSince(btcUsd, btcData[7].ReceivedAt)
+		require.Len(t, since, 3)
+		assert.True(t, since[0].ReceivedAt.Equal(btcData[7].ReceivedAt), "Expected the update at since to be included")
+		assert.True(t, since[2].Price.Equal(btcData[9].Price))
+
+		assert.Len(t, repo.GetSince(ethUsd, start.Add(-time.Second)), 3)
+	})
+
+	t.Run("Should return a limited time range", func(t *testing.T) {
+		all := repo.GetRange(btcUsd, btcData[2].ReceivedAt, btcData[8].ReceivedAt, 0)
+		require.Len(t, all, 6)
+		assert.True(t, all[0].ReceivedAt.Equal(btcData[2].ReceivedAt))
+		assert.True(t, all[5].ReceivedAt.Equal(btcData[7].ReceivedAt))
+
+		limited := repo.GetRange(btcUsd, btcData[2].ReceivedAt, btcData[8].ReceivedAt, 2)
+		require.Len(t, limited, 2)
+		assert.True(t, limited[1].ReceivedAt.Equal(btcData[3].ReceivedAt))
+	})
+}
+
+func TestPricesBySQLite_PersistsAcrossRestarts(t *testing.T) {
+	var (
+		path   = filepath.Join(t.TempDir(), "data", "prices.db")
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+	)
+
+	repo, err := NewPricesBySQLite(SQLiteOptions{Path: path})
+	require.NoError(t, err)
+
+	for _, update := range domaintest.NewPriceUpdates(btcUsd, 3, time.Now().Add(-time.Minute), time.Second) {
+		repo.Store(update)
+	}
+	require.NoError(t, repo.Close())
+
+	restarted := newTestSQLite(t, SQLiteOptions{Path: path})
+	assert.Len(t, restarted.GetSince(btcUsd, time.Time{}), 3)
+}
+
+func TestPricesBySQLite_KeepsTheBackfilledFlag(t *testing.T) {
+	var (
+		btcUsd  = domain.NewPair(domain.BTC, domain.USD)
+		repo    = newTestSQLite(t, SQLiteOptions{})
+		updates = domaintest.NewPriceUpdates(btcUsd, 2, time.Now().Add(-time.Minute), time.Second)
+	)
+
+	updates[0].Backfilled = true
+	for _, update := range updates {
+		repo.Store(update)
+	}
+
+	stored := repo.GetSince(btcUsd, time.Time{})
+	require.Len(t, stored, 2)
+	assert.True(t, stored[0].Backfilled)
+	assert.False(t, stored[1].Backfilled)
+}
+
+func TestPricesBySQLite_Retention(t *testing.T) {
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		repo   = newTestSQLite(t, SQLiteOptions{Retention: time.Hour, RetentionCheckInterval: 10 * time.Millisecond})
+	)
+
+	for _, update := range domaintest.NewPriceUpdates(btcUsd, 3, time.Now().Add(-2*time.Hour), time.Second) {
+		repo.Store(update)
+	}
+	repo.Store(domaintest.NewPriceUpdates(btcUsd, 1, time.Now(), time.Second)[0])
+
+	assert.Eventually(t, func() bool {
+		return len(repo.GetSince(btcUsd, time.Time{})) == 1
+	}, time.Second, 10*time.Millisecond, "Expected prices older than the retention to be deleted")
+}
+
+func TestMigrate(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "migrations.db"))
+	require.NoError(t, err)
+	defer func() {
+		_ = db.Close()
+	}()
+
+	ctx := context.Background()
+	migrations := []string{
+		`CREATE TABLE a (id INTEGER)`,
+		`CREATE TABLE b (id INTEGER)`,
+	}
+
+	t.Run("Should apply pending migrations only once", func(t *testing.T) {
+		version, err := migrate(ctx, db, migrations[:1])
+		require.NoError(t, err)
+		assert.Equal(t, 1, version)
+
+		version, err = migrate(ctx, db, migrations)
+		require.NoError(t, err)
+		assert.Equal(t, 2, version)
+
+		version, err = migrate(ctx, db, migrations)
+		require.NoError(t, err)
+		assert.Equal(t, 2, version)
+	})
+
+	t.Run("Should fail when the schema is newer than supported", func(t *testing.T) {
+		_, err := migrate(ctx, db, migrations[:1])
+		assert.Error(t, err)
+	})
+
+	t.Run("Should not record a failed migration", func(t *testing.T) {
+		version, err := migrate(ctx, db, append(migrations, `CREATE TABLE broken (`))
+		assert.Error(t, err)
+		assert.Equal(t, 2, version)
+	})
+}
+
+func TestPricesBySQLite_Contract(t *testing.T) {
+	storagetest.TestPricesRepository(t, func(t *testing.T) storagetest.PricesRepository {
+		return newTestSQLite(t, SQLiteOptions{})
+	})
+}
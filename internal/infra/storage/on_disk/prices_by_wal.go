@@ -0,0 +1,538 @@
+package on_disk
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/in_memory"
+)
+
+const (
+	segmentExtension = ".wal"
+	tmpExtension     = ".tmp"
+	dirPermission    = 0o750
+	filePermission   = 0o600
+)
+
+type FsyncPolicy string
+
+const (
+	// FsyncAlways syncs the segment after every write: no update is lost on crash, at the cost of write latency.
+	FsyncAlways FsyncPolicy = "always"
+	// FsyncInterval syncs the segment periodically: updates written since the last sync may be lost on crash.
+	FsyncInterval FsyncPolicy = "interval"
+	// FsyncNever leaves syncing to the operating system.
+	FsyncNever FsyncPolicy = "never"
+)
+
+type WALOptions struct {
+	Dir           string
+	FsyncPolicy   FsyncPolicy
+	FsyncInterval time.Duration
+	// SegmentMaxBytes and SegmentMaxAge trigger the rotation of the active segment, whichever comes first
+	SegmentMaxBytes int64
+	SegmentMaxAge   time.Duration
+	// Retention is how long closed segments are kept after their newest record
+	Retention time.Duration
+	// MaxSegments is the number of closed segments that triggers a compaction into a single snapshot segment
+	MaxSegments int
+}
+
+type segment struct {
+	seq            uint64
+	path           string
+	size           int64
+	lastReceivedAt time.Time
+}
+
+// PricesByWAL is a disk-backed prices repository. Every update is appended to a segmented write-ahead log and
+// kept in an in-memory ring buffer, which serves all reads and is rebuilt from the log on startup.
+type PricesByWAL struct {
+	mu       sync.Mutex
+	log      *slog.Logger
+	opts     WALOptions
+	ring     *in_memory.PricesByRingBuffer
+	closed   []segment
+	active   segment
+	file     *os.File
+	writer   *bufio.Writer
+	openedAt time.Time
+	dirty    bool
+	done     chan struct{}
+	stopped  bool
+}
+
+func NewPricesByWAL(opts WALOptions, maxHistorySize int) (*PricesByWAL, error) {
+	if err := os.MkdirAll(opts.Dir, dirPermission); err != nil {
+		return nil, errors.Wrap(err, "failed to create wal directory")
+	}
+
+	r := &PricesByWAL{
+		log:  slog.Default(),
+		opts: opts,
+		ring: in_memory.NewPricesByRingBuffer(maxHistorySize),
+		done: make(chan struct{}),
+	}
+
+	if err := r.recover(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	err := r.openSegment(r.nextSeq())
+	r.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.FsyncPolicy == FsyncInterval && opts.FsyncInterval > 0 {
+		go r.syncPeriodically()
+	}
+
+	return r, nil
+}
+
+func (r *PricesByWAL) Store(priceUpdate domain.PriceUpdate) {
+	if err := r.append(priceUpdate); err != nil {
+		r.log.Error("Failed to append price update to the wal", "pair", priceUpdate.Pair.String(), "error", err.Error())
+	}
+
+	r.ring.Store(priceUpdate)
+}
+
+func (r *PricesByWAL) GetLatest(pair domain.Pair) (domain.PriceUpdate, bool) {
+	return r.ring.GetLatest(pair)
+}
+
+func (r *PricesByWAL) GetAll(pair domain.Pair) []domain.PriceUpdate {
+	return r.ring.GetAll(pair)
+}
+
+func (r *PricesByWAL) GetSince(pair domain.Pair, since time.Time) []domain.PriceUpdate {
+	return r.ring.GetSince(pair, since)
+}
+
+func (r *PricesByWAL) GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate {
+	return r.ring.GetRange(pair, from, to, limit)
+}
+
+func (r *PricesByWAL) AvailableSince(pair domain.Pair) (time.Time, bool) {
+	return r.ring.AvailableSince(pair)
+}
+
+// Resize changes the maximum number of items kept in memory per pair.
+func (r *PricesByWAL) Resize(maxHistorySize int) {
+	r.ring.Resize(maxHistorySize)
+}
+
+// Close flushes and syncs the active segment. The repository must not be used afterward.
+func (r *PricesByWAL) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		return nil
+	}
+
+	r.stopped = true
+	close(r.done)
+
+	return r.closeSegment()
+}
+
+func (r *PricesByWAL) append(update domain.PriceUpdate) error {
+	record, err := encodeRecord(update)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		return errors.New("wal is closed")
+	}
+
+	if r.shouldRotate(int64(len(record))) {
+		if err := r.rotate(); err != nil {
+			return errors.Wrap(err, "failed to rotate segment")
+		}
+	}
+
+	if _, err := r.writer.Write(record); err != nil {
+		return errors.Wrap(err, "failed to write record")
+	}
+
+	r.active.size += int64(len(record))
+	r.active.lastReceivedAt = update.ReceivedAt
+	r.dirty = true
+
+	if r.opts.FsyncPolicy == FsyncAlways {
+		return r.sync()
+	}
+
+	// records are flushed to the OS on every write, so that only fsync depends on the policy
+	return errors.Wrap(r.writer.Flush(), "failed to flush record")
+}
+
+func (r *PricesByWAL) shouldRotate(recordSize int64) bool {
+	if r.active.size == 0 {
+		return false
+	}
+
+	if r.opts.SegmentMaxBytes > 0 && r.active.size+recordSize > r.opts.SegmentMaxBytes {
+		return true
+	}
+
+	return r.opts.SegmentMaxAge > 0 && time.Since(r.openedAt) >= r.opts.SegmentMaxAge
+}
+
+// rotate closes the active segment, applies retention and compaction to the closed ones, and opens a new segment.
+func (r *PricesByWAL) rotate() error {
+	if err := r.closeSegment(); err != nil {
+		return err
+	}
+
+	r.closed = append(r.closed, r.active)
+
+	r.applyRetention()
+
+	if r.opts.MaxSegments > 0 && len(r.closed) > r.opts.MaxSegments {
+		if err := r.compact(); err != nil {
+			r.log.Error("Failed to compact wal segments", "error", err.Error())
+		}
+	}
+
+	return r.openSegment(r.nextSeq())
+}
+
+// applyRetention deletes the closed segments whose newest record is older than the retention period.
+func (r *PricesByWAL) applyRetention() {
+	if r.opts.Retention <= 0 {
+		return
+	}
+
+	var (
+		threshold = time.Now().Add(-r.opts.Retention)
+		kept      = r.closed[:0]
+	)
+
+	for _, s := range r.closed {
+		if !s.lastReceivedAt.IsZero() && s.lastReceivedAt.Before(threshold) {
+			if err := os.Remove(s.path); err != nil {
+				r.log.Error("Failed to remove expired wal segment", "path", s.path, "error", err.Error())
+				kept = append(kept, s)
+				continue
+			}
+			r.log.Info("Removed expired wal segment", "path", s.path)
+			continue
+		}
+		kept = append(kept, s)
+	}
+
+	r.closed = kept
+}
+
+// compact replaces every closed segment with a single segment holding the current in-memory history.
+// The snapshot is written to a temporary file and renamed, so a crash never leaves a partial segment behind.
+func (r *PricesByWAL) compact() error {
+	snapshot := segment{seq: r.nextSeq()}
+	snapshot.path = r.segmentPath(snapshot.seq)
+
+	tmpPath := snapshot.path + tmpExtension
+
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePermission)
+	if err != nil {
+		return errors.Wrap(err, "failed to create snapshot segment")
+	}
+
+	writer := bufio.NewWriter(file)
+
+	for _, pair := range r.ring.Pairs() {
+		for _, update := range r.ring.GetAll(pair) {
+			record, err := encodeRecord(update)
+			if err != nil {
+				continue
+			}
+
+			if _, err := writer.Write(record); err != nil {
+				_ = file.Close()
+				return errors.Wrap(err, "failed to write snapshot record")
+			}
+
+			snapshot.size += int64(len(record))
+			if update.ReceivedAt.After(snapshot.lastReceivedAt) {
+				snapshot.lastReceivedAt = update.ReceivedAt
+			}
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		_ = file.Close()
+		return errors.Wrap(err, "failed to flush snapshot segment")
+	}
+
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return errors.Wrap(err, "failed to sync snapshot segment")
+	}
+
+	if err := file.Close(); err != nil {
+		return errors.Wrap(err, "failed to close snapshot segment")
+	}
+
+	if err := os.Rename(tmpPath, snapshot.path); err != nil {
+		return errors.Wrap(err, "failed to rename snapshot segment")
+	}
+
+	// from now on the snapshot holds everything needed; duplicates left by a crash here are skipped on recovery
+	for _, s := range r.closed {
+		if err := os.Remove(s.path); err != nil {
+			r.log.Error("Failed to remove compacted wal segment", "path", s.path, "error", err.Error())
+		}
+	}
+
+	r.log.Info("Compacted wal segments", "segments", len(r.closed), "snapshot", snapshot.path, "bytes", snapshot.size)
+
+	r.closed = []segment{snapshot}
+
+	return nil
+}
+
+func (r *PricesByWAL) openSegment(seq uint64) error {
+	path := r.segmentPath(seq)
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, filePermission)
+	if err != nil {
+		return errors.Wrap(err, "failed to open wal segment")
+	}
+
+	r.active = segment{seq: seq, path: path}
+	r.file = file
+	r.writer = bufio.NewWriter(file)
+	r.openedAt = time.Now()
+
+	return nil
+}
+
+func (r *PricesByWAL) closeSegment() error {
+	if r.file == nil {
+		return nil
+	}
+
+	if err := r.sync(); err != nil {
+		return err
+	}
+
+	err := r.file.Close()
+	r.file = nil
+
+	// empty segments are useless, so they are not kept
+	if r.active.size == 0 {
+		_ = os.Remove(r.active.path)
+	}
+
+	return errors.Wrap(err, "failed to close wal segment")
+}
+
+func (r *PricesByWAL) sync() error {
+	if err := r.writer.Flush(); err != nil {
+		return errors.Wrap(err, "failed to flush wal segment")
+	}
+
+	if !r.dirty || r.opts.FsyncPolicy == FsyncNever {
+		return nil
+	}
+
+	if err := r.file.Sync(); err != nil {
+		return errors.Wrap(err, "failed to sync wal segment")
+	}
+
+	r.dirty = false
+
+	return nil
+}
+
+func (r *PricesByWAL) syncPeriodically() {
+	ticker := time.NewTicker(r.opts.FsyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			if !r.stopped {
+				if err := r.sync(); err != nil {
+					r.log.Error("Failed to sync wal segment", "error", err.Error())
+				}
+			}
+			r.mu.Unlock()
+		}
+	}
+}
+
+// recover replays every segment into the in-memory ring buffer. Records older than the latest one of their pair,
+// or identical to a record at that same time, are skipped, as they can only be duplicates left by an interrupted
+// compaction. Distinct records sharing the time of the latest one are all kept.
+func (r *PricesByWAL) recover() error {
+	segments, err := r.listSegments()
+	if err != nil {
+		return err
+	}
+
+	var (
+		latest   = make(map[domain.Pair]time.Time)
+		atLatest = make(map[domain.Pair]map[string]struct{})
+		replayed int
+	)
+
+	for i, s := range segments {
+		isLast := i == len(segments)-1
+
+		count, err := r.replaySegment(&s, isLast, func(update domain.PriceUpdate) {
+			if update.ReceivedAt.Before(latest[update.Pair]) {
+				return
+			}
+
+			if update.ReceivedAt.After(latest[update.Pair]) {
+				latest[update.Pair] = update.ReceivedAt
+				atLatest[update.Pair] = make(map[string]struct{})
+			}
+
+			record, _ := encodeRecord(update)
+			if _, duplicated := atLatest[update.Pair][string(record)]; duplicated {
+				return
+			}
+			atLatest[update.Pair][string(record)] = struct{}{}
+
+			r.ring.Store(update)
+		})
+		if err != nil {
+			return err
+		}
+
+		replayed += count
+
+		if s.size == 0 {
+			_ = os.Remove(s.path)
+			continue
+		}
+
+		r.closed = append(r.closed, s)
+	}
+
+	r.log.Info("Recovered price history from wal", "segments", len(r.closed), "records", replayed)
+
+	return nil
+}
+
+// replaySegment reads every valid record of a segment. A torn or corrupted record ends the segment: the file is
+// truncated at the last valid record, so that the corrupted bytes are not mistaken for new records later on.
+func (r *PricesByWAL) replaySegment(s *segment, isLast bool, onRecord func(domain.PriceUpdate)) (int, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to open wal segment, path: %s", s.path)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var (
+		reader = bufio.NewReader(file)
+		count  int
+	)
+
+	for {
+		update, size, err := readRecord(reader)
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+
+		if err != nil {
+			r.log.Warn("Skipping corrupted wal segment tail",
+				"path", s.path, "offset", s.size, "last_segment", isLast, "error", err.Error())
+
+			if err := os.Truncate(s.path, s.size); err != nil {
+				return count, errors.Wrapf(err, "failed to truncate corrupted wal segment, path: %s", s.path)
+			}
+
+			return count, nil
+		}
+
+		s.size += int64(size)
+		if update.ReceivedAt.After(s.lastReceivedAt) {
+			s.lastReceivedAt = update.ReceivedAt
+		}
+
+		onRecord(update)
+		count++
+	}
+}
+
+// listSegments returns the segments found in the wal directory, ordered by sequence.
+// Leftover temporary files of interrupted compactions are removed.
+func (r *PricesByWAL) listSegments() ([]segment, error) {
+	entries, err := os.ReadDir(r.opts.Dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list wal directory")
+	}
+
+	var segments []segment
+
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(r.opts.Dir, name)
+
+		if strings.HasSuffix(name, tmpExtension) {
+			_ = os.Remove(path)
+			continue
+		}
+
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExtension) {
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExtension), 10, 64)
+		if err != nil {
+			r.log.Warn("Ignoring unexpected file in wal directory", "path", path)
+			continue
+		}
+
+		segments = append(segments, segment{seq: seq, path: path})
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i].seq < segments[j].seq })
+
+	return segments, nil
+}
+
+func (r *PricesByWAL) nextSeq() uint64 {
+	next := r.active.seq + 1
+
+	for _, s := range r.closed {
+		if s.seq >= next {
+			next = s.seq + 1
+		}
+	}
+
+	return next
+}
+
+func (r *PricesByWAL) segmentPath(seq uint64) string {
+	return filepath.Join(r.opts.Dir, fmt.Sprintf("%020d%s", seq, segmentExtension))
+}
@@ -0,0 +1,267 @@
+package on_disk
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain/domaintest"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/storagetest"
+)
+
+func listSegmentFiles(t *testing.T, dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExtension))
+	require.NoError(t, err)
+	return files
+}
+
+func TestPricesByWAL_RecoverOnRestart(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		btcUsd  = domain.NewPair(domain.BTC, domain.USD)
+		ethUsd  = domain.NewPair(domain.ETH, domain.USD)
+		now     = time.Now()
+		opts    = WALOptions{Dir: dir, FsyncPolicy: FsyncAlways}
+		btcData = domaintest.NewPriceUpdates(btcUsd, 5, now.Add(-time.Minute), time.Second)
+		ethData = domaintest.NewPriceUpdates(ethUsd, 2, now.Add(-time.Minute), time.Second)
+	)
+
+	repo, err := NewPricesByWAL(opts, 3)
+	require.NoError(t, err)
+
+	for _, update := range append(btcData, ethData...) {
+		repo.Store(update)
+	}
+	require.NoError(t, repo.Close())
+
+	restarted, err := NewPricesByWAL(opts, 3)
+	require.NoError(t, err)
+	defer func() {
+		_ = restarted.Close()
+	}()
+
+	all := restarted.GetAll(btcUsd)
+	require.Len(t, all, 3, "Expected the ring buffer capacity to be respected on recovery")
+	assert.True(t, all[0].Price.Equal(btcData[2].Price))
+	assert.True(t, all[2].ReceivedAt.Equal(btcData[4].ReceivedAt))
+
+	assert.Len(t, restarted.GetSince(ethUsd, ethData[1].ReceivedAt), 1)
+
+	latest, exists := restarted.GetLatest(btcUsd)
+	assert.True(t, exists)
+	assert.True(t, latest.Price.Equal(btcData[4].Price))
+}
+
+func TestPricesByWAL_RecoversUpdatesSharingATimestamp(t *testing.T) {
+	var (
+		opts   = WALOptions{Dir: t.TempDir(), FsyncPolicy: FsyncAlways}
+		ethBtc = domain.NewPair(domain.ETH, domain.BTC)
+		at     = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	)
+
+	quoted := domain.PriceUpdate{
+		Pair:       ethBtc,
+		Price:      decimal.RequireFromString("0.05"),
+		ReceivedAt: at,
+		Backfilled: true,
+		Quote:      &domain.Quote{Bid: decimal.NewNullDecimal(decimal.RequireFromString("0.0499"))},
+	}
+	synthetic := domain.PriceUpdate{
+		Pair:       ethBtc,
+		Price:      decimal.RequireFromString("0.051"),
+		ReceivedAt: at,
+		Legs:       []domain.Pair{domain.NewPair(domain.ETH, domain.USD), domain.NewPair(domain.BTC, domain.USD)},
+	}
+
+	repo, err := NewPricesByWAL(opts, 10)
+	require.NoError(t, err)
+	repo.Store(quoted)
+	repo.Store(synthetic)
+	require.NoError(t, repo.Close())
+
+	restarted, err := NewPricesByWAL(opts, 10)
+	require.NoError(t, err)
+	defer func() {
+		_ = restarted.Close()
+	}()
+
+	all := restarted.GetAll(ethBtc)
+	require.Len(t, all, 2, "Expected both updates at the same time to be recovered")
+	assert.Equal(t, quoted, all[0], "Expected the backfilled flag and the quote to be recovered")
+	assert.Equal(t, synthetic, all[1], "Expected the legs to be recovered")
+}
+
+func TestPricesByWAL_SkipsCorruptedTail(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		opts   = WALOptions{Dir: dir, FsyncPolicy: FsyncNever}
+	)
+
+	repo, err := NewPricesByWAL(opts, 10)
+	require.NoError(t, err)
+
+	for _, update := range domaintest.NewPriceUpdates(btcUsd, 3, time.Now().Add(-time.Minute), time.Second) {
+		repo.Store(update)
+	}
+	require.NoError(t, repo.Close())
+
+	files := listSegmentFiles(t, dir)
+	require.Len(t, files, 1)
+
+	// simulate a torn write followed by garbage
+	file, err := os.OpenFile(files[0], os.O_APPEND|os.O_WRONLY, filePermission)
+	require.NoError(t, err)
+	_, err = file.Write([]byte{0, 0, 0, 40, 1, 2, 3})
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	sizeBefore := fileSize(t, files[0])
+
+	restarted, err := NewPricesByWAL(opts, 10)
+	require.NoError(t, err)
+	defer func() {
+		_ = restarted.Close()
+	}()
+
+	assert.Len(t, restarted.GetAll(btcUsd), 3, "Expected valid records to be recovered")
+	assert.Less(t, fileSize(t, files[0]), sizeBefore, "Expected the corrupted tail to be truncated")
+}
+
+func TestPricesByWAL_RotationAndCompaction(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+	)
+
+	record, err := encodeRecord(domaintest.NewPriceUpdates(btcUsd, 1, time.Now(), time.Second)[0])
+	require.NoError(t, err)
+
+	t.Run("Should rotate segments by size", func(t *testing.T) {
+		opts := WALOptions{Dir: filepath.Join(dir, "rotation"), SegmentMaxBytes: int64(2 * len(record))}
+
+		repo, err := NewPricesByWAL(opts, 100)
+		require.NoError(t, err)
+
+		for _, update := range domaintest.NewPriceUpdates(btcUsd, 6, time.Now().Add(-time.Minute), time.Second) {
+			repo.Store(update)
+		}
+		require.NoError(t, repo.Close())
+
+		assert.Len(t, listSegmentFiles(t, opts.Dir), 3)
+	})
+
+	t.Run("Should compact closed segments into a snapshot", func(t *testing.T) {
+		opts := WALOptions{Dir: filepath.Join(dir, "compaction"), SegmentMaxBytes: int64(len(record)), MaxSegments: 3}
+
+		repo, err := NewPricesByWAL(opts, 2)
+		require.NoError(t, err)
+
+		updates := domaintest.NewPriceUpdates(btcUsd, 10, time.Now().Add(-time.Minute), time.Second)
+		for _, update := range updates {
+			repo.Store(update)
+		}
+		require.NoError(t, repo.Close())
+
+		assert.LessOrEqual(t, len(listSegmentFiles(t, opts.Dir)), opts.MaxSegments+1)
+
+		restarted, err := NewPricesByWAL(opts, 2)
+		require.NoError(t, err)
+		defer func() {
+			_ = restarted.Close()
+		}()
+
+		all := restarted.GetAll(btcUsd)
+		require.Len(t, all, 2)
+		assert.True(t, all[1].Price.Equal(updates[9].Price))
+	})
+
+	t.Run("Should delete segments older than retention", func(t *testing.T) {
+		opts := WALOptions{Dir: filepath.Join(dir, "retention"), SegmentMaxBytes: int64(len(record)), Retention: time.Hour}
+
+		repo, err := NewPricesByWAL(opts, 10)
+		require.NoError(t, err)
+
+		for _, update := range domaintest.NewPriceUpdates(btcUsd, 3, time.Now().Add(-2*time.Hour), time.Second) {
+			repo.Store(update)
+		}
+		repo.Store(domaintest.NewPriceUpdates(btcUsd, 1, time.Now(), time.Second)[0])
+		repo.Store(domaintest.NewPriceUpdates(btcUsd, 1, time.Now().Add(time.Second), time.Second)[0])
+		require.NoError(t, repo.Close())
+
+		assert.Len(t, listSegmentFiles(t, opts.Dir), 2, "Expected only the recent segments to be kept")
+	})
+}
+
+func TestRecordEncoding(t *testing.T) {
+	update := domain.PriceUpdate{
+		Pair:       domain.NewPair("DOGE", "USDT"),
+		Price:      decimal.RequireFromString("0.123456789012345678"),
+		ReceivedAt: time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC),
+	}
+
+	record, err := encodeRecord(update)
+	require.NoError(t, err)
+
+	decoded, err := decodePayload(record[recordHeaderSize:])
+	require.NoError(t, err)
+
+	assert.Equal(t, update.Pair, decoded.Pair)
+	assert.True(t, update.Price.Equal(decoded.Price))
+	assert.True(t, update.ReceivedAt.Equal(decoded.ReceivedAt))
+
+	record[len(record)-1] ^= 0xff
+	_, err = decodePayload(record[recordHeaderSize : len(record)-2])
+	assert.Error(t, err)
+
+	t.Run("Encodes the extras", func(t *testing.T) {
+		update := update
+		update.Backfilled = true
+		update.Quote = &domain.Quote{High24h: decimal.NewNullDecimal(decimal.RequireFromString("0.13"))}
+
+		record, err := encodeRecord(update)
+		require.NoError(t, err)
+
+		decoded, err := decodePayload(record[recordHeaderSize:])
+		require.NoError(t, err)
+		assert.Equal(t, update, decoded)
+	})
+
+	t.Run("Decodes version 1 records", func(t *testing.T) {
+		payload := []byte{recordVersionV1, 4}
+		payload = append(payload, "DOGE"...)
+		payload = append(payload, 4)
+		payload = append(payload, "USDT"...)
+		payload = append(payload, 0, 4)
+		payload = append(payload, "0.12"...)
+		payload = binary.BigEndian.AppendUint64(payload, uint64(update.ReceivedAt.UnixNano()))
+
+		decoded, err := decodePayload(payload)
+		require.NoError(t, err)
+		assert.Equal(t, domain.PriceUpdate{Pair: update.Pair, Price: decimal.RequireFromString("0.12"), ReceivedAt: update.ReceivedAt}, decoded)
+	})
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	return info.Size()
+}
+
+func TestPricesByWAL_Contract(t *testing.T) {
+	storagetest.TestPricesRepository(t, func(t *testing.T) storagetest.PricesRepository {
+		repo, err := NewPricesByWAL(WALOptions{Dir: t.TempDir()}, 10)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = repo.Close()
+		})
+		return repo
+	})
+}
@@ -0,0 +1,74 @@
+package on_disk
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// sqliteMigrations are applied in order, each one in its own transaction. Released migrations must never be
+// changed: new schema changes are appended as new migrations.
+var sqliteMigrations = []string{
+	`CREATE TABLE prices (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		base        TEXT    NOT NULL,
+		quote       TEXT    NOT NULL,
+		price       TEXT    NOT NULL,
+		received_at INTEGER NOT NULL
+	);
+	CREATE INDEX idx_prices_pair_received_at ON prices (base, quote, received_at);`,
+	`ALTER TABLE prices ADD COLUMN backfilled INTEGER NOT NULL DEFAULT 0;`,
+	`ALTER TABLE prices ADD COLUMN extras TEXT;`,
+}
+
+// migrate brings the schema up to date, returning the resulting schema version.
+func migrate(ctx context.Context, db *sql.DB, migrations []string) (int, error) {
+	const createVersionsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`
+
+	if _, err := db.ExecContext(ctx, createVersionsTable); err != nil {
+		return 0, errors.Wrap(err, "failed to create schema migrations table")
+	}
+
+	var current int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return 0, errors.Wrap(err, "failed to read schema version")
+	}
+
+	if current > len(migrations) {
+		return current, errors.Errorf("database schema version %d is newer than the supported %d", current, len(migrations))
+	}
+
+	for version := current + 1; version <= len(migrations); version++ {
+		if err := applyMigration(ctx, db, version, migrations[version-1]); err != nil {
+			return version - 1, err
+		}
+	}
+
+	return len(migrations), nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, version int, statement string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to begin migration %d", version)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err = tx.ExecContext(ctx, statement); err != nil {
+		return errors.Wrapf(err, "failed to apply migration %d", version)
+	}
+
+	const insertVersion = `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`
+	if _, err = tx.ExecContext(ctx, insertVersion, version, time.Now().Unix()); err != nil {
+		return errors.Wrapf(err, "failed to record migration %d", version)
+	}
+
+	return errors.Wrapf(tx.Commit(), "failed to commit migration %d", version)
+}
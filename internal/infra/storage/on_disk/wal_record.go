@@ -0,0 +1,181 @@
+package on_disk
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/codec"
+)
+
+// Each record is written as [payload length: uint32][crc32 of payload: uint32][payload], where the payload is
+// [version: uint8][from length: uint8][from][to length: uint8][to][price length: uint16][price][received at: int64 unix nano]
+// followed, since version 2, by [extras length: uint16][extras], the JSON encoded codec.Extras, empty when none.
+const (
+	recordHeaderSize = 8
+	recordVersion    = 2
+	maxRecordSize    = 4096
+)
+
+// recordVersionV1 records have no extras, they are still read from the segments written before version 2.
+const recordVersionV1 = 1
+
+var (
+	errCorruptedRecord = errors.New("corrupted record")
+	errTornRecord      = errors.New("incomplete record")
+)
+
+func encodeRecord(update domain.PriceUpdate) ([]byte, error) {
+	var (
+		from  = string(update.Pair.From)
+		to    = string(update.Pair.To)
+		price = update.Price.String()
+	)
+
+	extras, err := codec.EncodeExtras(update)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(from) > 255 || len(to) > 255 || len(price) > 65535 || len(extras) > 65535 {
+		return nil, errors.New("price update fields are too long to be encoded")
+	}
+
+	payload := make([]byte, 0, 1+1+len(from)+1+len(to)+2+len(price)+8+2+len(extras))
+	payload = append(payload, recordVersion)
+	payload = append(payload, byte(len(from)))
+	payload = append(payload, from...)
+	payload = append(payload, byte(len(to)))
+	payload = append(payload, to...)
+	payload = binary.BigEndian.AppendUint16(payload, uint16(len(price)))
+	payload = append(payload, price...)
+	payload = binary.BigEndian.AppendUint64(payload, uint64(update.ReceivedAt.UnixNano()))
+	payload = binary.BigEndian.AppendUint16(payload, uint16(len(extras)))
+	payload = append(payload, extras...)
+
+	if len(payload) > maxRecordSize {
+		return nil, errors.New("price update is too large to be encoded")
+	}
+
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+
+	return append(record, payload...), nil
+}
+
+// readRecord reads the next record, returning io.EOF when the reader is exhausted exactly at a record boundary.
+func readRecord(r *bufio.Reader) (domain.PriceUpdate, int, error) {
+	header := make([]byte, recordHeaderSize)
+	if n, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) && n == 0 {
+			return domain.PriceUpdate{}, 0, io.EOF
+		}
+		return domain.PriceUpdate{}, 0, errTornRecord
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length == 0 || length > maxRecordSize {
+		return domain.PriceUpdate{}, 0, errCorruptedRecord
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return domain.PriceUpdate{}, 0, errTornRecord
+	}
+
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return domain.PriceUpdate{}, 0, errCorruptedRecord
+	}
+
+	update, err := decodePayload(payload)
+	if err != nil {
+		return domain.PriceUpdate{}, 0, err
+	}
+
+	return update, recordHeaderSize + int(length), nil
+}
+
+func decodePayload(payload []byte) (domain.PriceUpdate, error) {
+	d := &payloadDecoder{buf: payload}
+
+	version := d.uint8()
+	if version != recordVersion && version != recordVersionV1 {
+		return domain.PriceUpdate{}, errors.Wrapf(errCorruptedRecord, "unsupported record version %d", version)
+	}
+
+	from := d.bytes(int(d.uint8()))
+	to := d.bytes(int(d.uint8()))
+	price := d.bytes(int(d.uint16()))
+	receivedAt := d.uint64()
+
+	var extras []byte
+	if version >= 2 {
+		extras = d.bytes(int(d.uint16()))
+	}
+
+	if d.err != nil || len(d.buf) != 0 {
+		return domain.PriceUpdate{}, errCorruptedRecord
+	}
+
+	decimalPrice, err := decimal.NewFromString(string(price))
+	if err != nil {
+		return domain.PriceUpdate{}, errors.Wrap(errCorruptedRecord, "invalid price")
+	}
+
+	update := domain.PriceUpdate{
+		Pair:       domain.NewPair(domain.Currency(from), domain.Currency(to)),
+		Price:      decimalPrice,
+		ReceivedAt: time.Unix(0, int64(receivedAt)).UTC(),
+	}
+
+	if err = codec.DecodeExtras(extras, &update); err != nil {
+		return domain.PriceUpdate{}, errors.Wrap(errCorruptedRecord, "invalid extras")
+	}
+
+	return update, nil
+}
+
+type payloadDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *payloadDecoder) bytes(n int) []byte {
+	if d.err != nil || len(d.buf) < n {
+		d.err = errCorruptedRecord
+		return nil
+	}
+
+	v := d.buf[:n]
+	d.buf = d.buf[n:]
+
+	return v
+}
+
+func (d *payloadDecoder) uint8() uint8 {
+	if v := d.bytes(1); v != nil {
+		return v[0]
+	}
+	return 0
+}
+
+func (d *payloadDecoder) uint16() uint16 {
+	if v := d.bytes(2); v != nil {
+		return binary.BigEndian.Uint16(v)
+	}
+	return 0
+}
+
+func (d *payloadDecoder) uint64() uint64 {
+	if v := d.bytes(8); v != nil {
+		return binary.BigEndian.Uint64(v)
+	}
+	return 0
+}
@@ -0,0 +1,146 @@
+// Package storagetest provides the contract shared by every prices repository implementation.
+package storagetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain/domaintest"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/codec"
+)
+
+// PricesRepository is the behaviour checked by TestPricesRepository.
+type PricesRepository interface {
+	Store(priceUpdate domain.PriceUpdate)
+	GetLatest(pair domain.Pair) (domain.PriceUpdate, bool)
+	GetSince(pair domain.Pair, since time.Time) []domain.PriceUpdate
+	GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate
+}
+
+// TestPricesRepository runs the repository contract on an empty repository returned by newRepo for each case.
+// The repositories are expected to hold at least 10 updates per pair received within the last minute.
+func TestPricesRepository(t *testing.T, newRepo func(t *testing.T) PricesRepository) {
+	var (
+		btcUsd  = domain.NewPair(domain.BTC, domain.USD)
+		ethUsd  = domain.NewPair(domain.ETH, domain.USD)
+		start   = time.Now().Add(-time.Minute).Truncate(time.Millisecond).Add(123456 * time.Nanosecond)
+		btcData = domaintest.NewPriceUpdates(btcUsd, 5, start, time.Second)
+		ethData = domaintest.NewPriceUpdates(ethUsd, 3, start, time.Second)
+	)
+
+	storeAll := func(repo PricesRepository) {
+		for i := range btcData {
+			repo.Store(btcData[i])
+			if i < len(ethData) {
+				repo.Store(ethData[i])
+			}
+		}
+	}
+
+	t.Run("Should return the latest update of each pair", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, exists := repo.GetLatest(btcUsd)
+		assert.False(t, exists, "Expected no latest update in an empty repository")
+
+		storeAll(repo)
+
+		latest, exists := repo.GetLatest(btcUsd)
+		require.True(t, exists)
+		AssertEqualUpdates(t, btcData[4:], []domain.PriceUpdate{latest})
+
+		latest, exists = repo.GetLatest(ethUsd)
+		require.True(t, exists)
+		AssertEqualUpdates(t, ethData[2:], []domain.PriceUpdate{latest})
+
+		_, exists = repo.GetLatest(domain.NewPair("SOL", domain.USD))
+		assert.False(t, exists)
+	})
+
+	t.Run("Should return the updates since a timestamp, including it, in ascending order", func(t *testing.T) {
+		repo := newRepo(t)
+		storeAll(repo)
+
+		AssertEqualUpdates(t, btcData, repo.GetSince(btcUsd, time.Time{}))
+		AssertEqualUpdates(t, btcData[2:], repo.GetSince(btcUsd, btcData[2].ReceivedAt))
+		AssertEqualUpdates(t, btcData[3:], repo.GetSince(btcUsd, btcData[2].ReceivedAt.Add(time.Nanosecond)))
+		AssertEqualUpdates(t, ethData[1:], repo.GetSince(ethUsd, ethData[1].ReceivedAt))
+
+		assert.Empty(t, repo.GetSince(btcUsd, btcData[4].ReceivedAt.Add(time.Nanosecond)))
+		assert.Empty(t, repo.GetSince(domain.NewPair("SOL", domain.USD), time.Time{}))
+	})
+
+	t.Run("Should return up to limit updates in a half-open range", func(t *testing.T) {
+		repo := newRepo(t)
+		storeAll(repo)
+
+		AssertEqualUpdates(t, btcData[1:4], repo.GetRange(btcUsd, btcData[1].ReceivedAt, btcData[4].ReceivedAt, 0))
+		AssertEqualUpdates(t, btcData[1:3], repo.GetRange(btcUsd, btcData[1].ReceivedAt, btcData[4].ReceivedAt, 2))
+		AssertEqualUpdates(t, btcData[1:2], repo.GetRange(btcUsd, btcData[1].ReceivedAt, btcData[2].ReceivedAt.Add(-time.Nanosecond), 0))
+
+		assert.Empty(t, repo.GetRange(btcUsd, btcData[4].ReceivedAt, btcData[1].ReceivedAt, 0))
+	})
+
+	t.Run("Should keep the optional fields of the updates", func(t *testing.T) {
+		repo := newRepo(t)
+
+		backfilled, synthetic, quoted := btcData[0], btcData[1], btcData[2]
+		backfilled.Backfilled = true
+		synthetic.Legs = []domain.Pair{domain.NewPair(domain.BTC, domain.EUR), domain.NewPair(domain.EUR, domain.USD)}
+		quoted.Quote = &domain.Quote{
+			Bid:          decimal.NewNullDecimal(quoted.Price.Sub(decimal.RequireFromString("0.25"))),
+			Ask:          decimal.NewNullDecimal(quoted.Price.Add(decimal.RequireFromString("0.25"))),
+			ChangePct24h: decimal.NewNullDecimal(decimal.RequireFromString("-1.5")),
+		}
+
+		for _, update := range []domain.PriceUpdate{backfilled, synthetic, quoted} {
+			repo.Store(update)
+		}
+
+		AssertEqualUpdates(t, []domain.PriceUpdate{backfilled, synthetic, quoted}, repo.GetSince(btcUsd, time.Time{}))
+
+		latest, exists := repo.GetLatest(btcUsd)
+		require.True(t, exists)
+		AssertEqualUpdates(t, []domain.PriceUpdate{quoted}, []domain.PriceUpdate{latest})
+	})
+
+	t.Run("Should keep the updates sharing a timestamp", func(t *testing.T) {
+		repo := newRepo(t)
+
+		shared := btcData[0]
+		shared.Price = shared.Price.Add(decimal.NewFromInt(1))
+
+		repo.Store(btcData[0])
+		repo.Store(shared)
+		repo.Store(btcData[1])
+
+		since := repo.GetSince(btcUsd, btcData[0].ReceivedAt)
+		require.Len(t, since, 3)
+		AssertEqualUpdates(t, []domain.PriceUpdate{btcData[0], shared}, since[:2])
+	})
+}
+
+// AssertEqualUpdates asserts both lists hold the same updates in the same order, whatever the location of their
+// times and the representation of their decimals.
+func AssertEqualUpdates(t *testing.T, expected, actual []domain.PriceUpdate) {
+	t.Helper()
+
+	require.Len(t, actual, len(expected))
+
+	for i := range expected {
+		assert.Equal(t, expected[i].Pair, actual[i].Pair, "Unexpected pair at %d", i)
+		assert.True(t, expected[i].Price.Equal(actual[i].Price), "Expected price %s at %d, got %s", expected[i].Price, i, actual[i].Price)
+		assert.True(t, expected[i].ReceivedAt.Equal(actual[i].ReceivedAt), "Expected update received at %s at %d, got %s", expected[i].ReceivedAt, i, actual[i].ReceivedAt)
+
+		expectedExtras, err := codec.EncodeExtras(expected[i])
+		require.NoError(t, err)
+		actualExtras, err := codec.EncodeExtras(actual[i])
+		require.NoError(t, err)
+		assert.Equal(t, string(expectedExtras), string(actualExtras), "Unexpected optional fields at %d", i)
+	}
+}
@@ -0,0 +1,116 @@
+package tiered
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/sse"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/in_memory"
+)
+
+type rangeRepository interface {
+	GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate
+}
+
+type retentionReporter interface {
+	AvailableSince(pair domain.Pair) (time.Time, bool)
+}
+
+// PricesByTiers keeps the recent updates in a hot ring buffer, and every update in a cold persistent repository.
+// Reads are served from memory when it holds the requested range, and from both tiers otherwise.
+type PricesByTiers struct {
+	hot  *in_memory.PricesByRingBuffer
+	cold sse.PricesRepository
+}
+
+func NewPricesByTiers(hot *in_memory.PricesByRingBuffer, cold sse.PricesRepository) *PricesByTiers {
+	return &PricesByTiers{
+		hot:  hot,
+		cold: cold,
+	}
+}
+
+// Store writes the update through both tiers.
+func (r *PricesByTiers) Store(priceUpdate domain.PriceUpdate) {
+	r.hot.Store(priceUpdate)
+	r.cold.Store(priceUpdate)
+}
+
+func (r *PricesByTiers) GetLatest(pair domain.Pair) (domain.PriceUpdate, bool) {
+	return r.hot.GetLatest(pair)
+}
+
+// GetSince returns the updates received since the given time, in ascending order.
+func (r *PricesByTiers) GetSince(pair domain.Pair, since time.Time) []domain.PriceUpdate {
+	// the hot tier may have dropped updates sharing the time of its oldest one, so it only serves later ranges
+	if oldest, exists := r.hot.GetOldest(pair); exists && oldest.ReceivedAt.Before(since) {
+		return r.hot.GetSince(pair, since)
+	}
+
+	// the cold tier is read first: being written on every update, it holds everything older than the hot tier,
+	// even if the hot tier drops updates in between
+	var (
+		cold = r.cold.GetSince(pair, since)
+		hot  = r.hot.GetSince(pair, since)
+	)
+
+	if len(hot) == 0 {
+		return cold
+	}
+
+	// the updates received at the boundary are taken from the cold tier, which holds all of them
+	boundary := hot[0].ReceivedAt
+
+	merged := make([]domain.PriceUpdate, 0, len(cold)+len(hot))
+	for _, update := range cold {
+		if update.ReceivedAt.After(boundary) {
+			break
+		}
+		merged = append(merged, update)
+	}
+
+	for i, update := range hot {
+		if update.ReceivedAt.After(boundary) {
+			return append(merged, hot[i:]...)
+		}
+	}
+
+	return merged
+}
+
+// GetRange returns up to limit updates received in [from, to), in ascending order. Ranges not held in memory are
+// read from the cold tier, which holds every update, or from memory only if it doesn't support range queries.
+func (r *PricesByTiers) GetRange(pair domain.Pair, from, to time.Time, limit int) []domain.PriceUpdate {
+	if oldest, exists := r.hot.GetOldest(pair); exists && oldest.ReceivedAt.Before(from) {
+		return r.hot.GetRange(pair, from, to, limit)
+	}
+
+	if cold, ok := r.cold.(rangeRepository); ok {
+		return cold.GetRange(pair, from, to, limit)
+	}
+
+	return r.hot.GetRange(pair, from, to, limit)
+}
+
+// AvailableSince returns the time from which the cold tier holds the whole history, when it is limited.
+func (r *PricesByTiers) AvailableSince(pair domain.Pair) (time.Time, bool) {
+	if cold, ok := r.cold.(retentionReporter); ok {
+		return cold.AvailableSince(pair)
+	}
+	return time.Time{}, false
+}
+
+// Resize changes the maximum number of items kept in memory per pair.
+func (r *PricesByTiers) Resize(maxHistorySize int) {
+	r.hot.Resize(maxHistorySize)
+}
+
+func (r *PricesByTiers) Close() error {
+	if closer, ok := r.cold.(io.Closer); ok {
+		return errors.Wrap(closer.Close(), "failed to close cold prices repository")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package tiered
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonytcb/crypto-pricing-api/internal/domain"
+	"github.com/tonytcb/crypto-pricing-api/internal/domain/domaintest"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/in_memory"
+	"github.com/tonytcb/crypto-pricing-api/internal/infra/storage/storagetest"
+)
+
+// coldStore is a persistent repository stand-in with a limited retention.
+type coldStore struct {
+	*in_memory.PricesBySliceRepo
+	availableSince time.Time
+}
+
+func (c coldStore) AvailableSince(_ domain.Pair) (time.Time, bool) {
+	return c.availableSince, !c.availableSince.IsZero()
+}
+
+func TestPricesByTiers_GetSince(t *testing.T) {
+	var (
+		btcUsd = domain.NewPair(domain.BTC, domain.USD)
+		start  = time.Now().Add(-time.Hour)
+		data   = domaintest.NewPriceUpdates(btcUsd, 10, start, time.Second)
+		cold   = coldStore{PricesBySliceRepo: in_memory.NewPricesBySliceRepo(100)}
+		repo   = NewPricesByTiers(in_memory.NewPricesByRingBuffer(4), cold)
+	)
+
+	for _, update := range data {
+		repo.Store(update)
+	}
+
+	t.Run("Should serve recent ranges from memory", func(t *testing.T) {
+		since := repo.GetSince(btcUsd, data[7].ReceivedAt)
+		require.Len(t, since, 3)
+		assert.True(t, since[0].Price.Equal(data[7].Price))
+	})
+
+	t.Run("Should fall back to the cold tier for older ranges, without duplicates", func(t *testing.T) {
+		since := repo.GetSince(btcUsd, data[1].ReceivedAt.Add(-time.Millisecond))
+		require.Len(t, since, 9)
+
+		for i, update := range since {
+			assert.True(t, update.Price.Equal(data[i+1].Price), "unexpected update at %d", i)
+		}
+	})
+
+	t.Run("Should serve ranges from the tier holding them", func(t *testing.T) {
+		recent := repo.GetRange(btcUsd, data[7].ReceivedAt, data[9].ReceivedAt, 0)
+		require.Len(t, recent, 2)
+
+		old := repo.GetRange(btcUsd, data[1].ReceivedAt, data[9].ReceivedAt, 3)
+		require.Len(t, old, 3)
+		assert.True(t, old[0].Price.Equal(data[1].Price))
+	})
+
+	t.Run("Should serve from the cold tier when the hot tier is empty", func(t *testing.T) {
+		ethUsd := domain.NewPair(domain.ETH, domain.USD)
+		cold.Store(domaintest.NewPriceUpdates(ethUsd, 1, start, time.Second)[0])
+
+		assert.Len(t, repo.GetSince(ethUsd, time.Time{}), 1)
+	})
+
+	t.Run("Should report the retention of the cold tier", func(t *testing.T) {
+		_, limited := repo.AvailableSince(btcUsd)
+		assert.False(t, limited)
+
+		threshold := time.Now().Add(-24 * time.Hour)
+		limitedRepo := NewPricesByTiers(in_memory.NewPricesByRingBuffer(4), coldStore{
+			PricesBySliceRepo: in_memory.NewPricesBySliceRepo(100),
+			availableSince:    threshold,
+		})
+
+		availableSince, limited := limitedRepo.AvailableSince(btcUsd)
+		assert.True(t, limited)
+		assert.Equal(t, threshold, availableSince)
+	})
+}
+
+func TestPricesByTiers_Contract(t *testing.T) {
+	storagetest.TestPricesRepository(t, func(_ *testing.T) storagetest.PricesRepository {
+		// a hot tier smaller than the stored updates checks the fallback to the cold one
+		return NewPricesByTiers(in_memory.NewPricesByRingBuffer(2), coldStore{PricesBySliceRepo: in_memory.NewPricesBySliceRepo(10)})
+	})
+}
@@ -5,17 +5,23 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"sync"
+	"time"
 )
 
+// flushTimeout bounds how long WaitFlushed waits for a flush, so that a missing one fails instead of hanging.
+const flushTimeout = time.Second
+
 // ThreadSafeRecorder is a thread-safe ResponseRecorder
 type ThreadSafeRecorder struct {
-	mu sync.Mutex
-	w  *httptest.ResponseRecorder
+	mu      sync.Mutex
+	w       *httptest.ResponseRecorder
+	flushed chan struct{}
 }
 
 func NewThreadSafeRecorder() *ThreadSafeRecorder {
 	return &ThreadSafeRecorder{
-		w: httptest.NewRecorder(),
+		w:       httptest.NewRecorder(),
+		flushed: make(chan struct{}, 64),
 	}
 }
 
@@ -47,6 +53,21 @@ func (t *ThreadSafeRecorder) Flush() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.w.Flush()
+
+	select {
+	case t.flushed <- struct{}{}:
+	default:
+	}
+}
+
+// WaitFlushed waits for the next flush not waited for yet, returning false if none happens in time.
+func (t *ThreadSafeRecorder) WaitFlushed() bool {
+	select {
+	case <-t.flushed:
+		return true
+	case <-time.After(flushTimeout):
+		return false
+	}
 }
 
 func (t *ThreadSafeRecorder) Result() *http.Response {